@@ -0,0 +1,62 @@
+package main
+
+import (
+	"avito-pr-service/config"
+	"avito-pr-service/internal/repository/postgres"
+	"avito-pr-service/internal/usecase"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// app собирает те же usecase-ы, что и сервер, но без HTTP и фоновых
+// воркеров: события из outbox отправит работающий сервер
+type app struct {
+	store   *postgres.Store
+	team    usecase.TeamUsecase
+	user    usecase.UserUsecase
+	pr      usecase.PRUsecase
+	archive usecase.ArchiveUsecase
+	log     *slog.Logger
+}
+
+func newApp(ctx context.Context, cfg config.Config) (*app, error) {
+	store, err := postgres.NewStore(ctx, cfg.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	// stdout занят результатом команды, логи пишем в stderr
+	log := cliLogger()
+
+	teamRepository := store.Team()
+	userRepository := store.User()
+	prRepository := store.PR()
+
+	prUC := usecase.NewPRUsecase(prRepository, userRepository, teamRepository, log)
+
+	return &app{
+		store:   store,
+		team:    usecase.NewTeamUsecase(teamRepository, userRepository, prRepository, store.Deactivation(), prUC, log),
+		user:    usecase.NewUserUsecase(userRepository, log),
+		pr:      prUC,
+		archive: usecase.NewArchiveUsecase(store.Archive(), log),
+		log:     log,
+	}, nil
+}
+
+func (a *app) Close() {
+	a.store.Close()
+}
+
+func cliLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"avito-pr-service/config"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// export пишет полный архив в формате JSON Lines; сводка уходит в stderr,
+// чтобы не смешиваться с архивом в stdout
+func runExport(ctx context.Context, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	output := fs.String("o", "-", "output file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected arguments: %v", fs.Args())
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if *output == "-" {
+		summary, err := a.archive.Export(ctx, os.Stdout)
+		if err != nil {
+			return err
+		}
+		return printJSON(os.Stderr, summary)
+	}
+
+	// пишем во временный файл рядом, чтобы оборванный экспорт
+	// не оставил недописанный архив под итоговым именем
+	tmp := *output + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmp, err)
+	}
+	summary, err := a.archive.Export(ctx, f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("write %s: %w", tmp, cerr)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, *output); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	return printJSON(os.Stderr, summary)
+}
+
+func runRestore(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) != 1 {
+		return usagef("expected exactly one archive file")
+	}
+
+	var in io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("open %s: %w", args[0], err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	summary, err := a.archive.Restore(ctx, in)
+	if err != nil {
+		return err
+	}
+	return printJSON(os.Stdout, summary)
+}
@@ -2,42 +2,151 @@ package main
 
 import (
 	"avito-pr-service/config"
-	"avito-pr-service/internal/server"
+	"avito-pr-service/internal/models"
 	"context"
-	"log"
-	"net/http"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
-	"time"
 )
 
+// коды выхода повторяют классы HTTP-ошибок API, чтобы скрипты могли
+// отличить опечатку в аргументах от отсутствующей сущности или конфликта
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitConflict = 4
+)
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, cfg config.Config, args []string) error
+}
+
+var commands = map[string]command{
+	"serve":   {usage: "serve", run: runServe},
+	"migrate": {usage: "migrate up|down|status", run: runMigrate},
+	"team":    {usage: "team import [-dry-run] [-format json|yaml|csv] <file|->", run: runTeam},
+	"user":    {usage: "user set-active <user_id> <true|false>", run: runUser},
+	"pr":      {usage: "pr reassign <pr_id> <old_reviewer_id>", run: runPR},
+	"stats":   {usage: "stats", run: runStats},
+	"export":  {usage: "export [-o file]", run: runExport},
+	"restore": {usage: "restore <file|->", run: runRestore},
+}
+
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
 func main() {
-	cfg := config.New()
+	os.Exit(run(os.Args[1:]))
+}
 
-	srv, err := server.New(*cfg)
+func run(args []string) int {
+	flags, args, err := config.ParseFlags(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stdout, usage())
+		return exitOK
+	}
 	if err != nil {
-		log.Fatal("failed to create server: ", err)
+		fmt.Fprintf(os.Stderr, "%s\n\n%s", err, usage())
+		return exitUsage
 	}
 
-	go func() {
-		if err := srv.Start(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("server error: ", err)
-		}
-	}()
+	// без аргументов запускаем сервер, как и раньше (CMD в Dockerfile)
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
 
-	log.Printf("Server running on :%s", cfg.Port)
+	switch args[0] {
+	case "help":
+		fmt.Fprint(os.Stdout, usage())
+		return exitOK
+	}
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage())
+		return exitUsage
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	cfg, err := config.Load(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = cmd.run(ctx, *cfg, args[1:])
+	if err == nil {
+		return exitOK
+	}
+
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprintf(os.Stderr, "%s\nusage: pr-service %s\n", err, cmd.usage)
+	} else {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+	}
+	return exitCode(err)
+}
+
+func exitCode(err error) int {
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		return exitUsage
+	}
+
+	var importErr *models.ImportValidationError
+	if errors.As(err, &importErr) {
+		return exitUsage
+	}
+
+	var appErr models.AppError
+	if !errors.As(err, &appErr) {
+		return exitError
+	}
+	switch appErr.Code {
+	case models.ErrorNotFound:
+		return exitNotFound
+	case models.ErrorTeamExists, models.ErrorPRExists, models.ErrorPRMerged, models.ErrorNoCandidate, models.ErrorNotAssigned,
+		models.ErrorDatabaseNotEmpty, models.ErrorNotDeactivated, models.ErrorDeactivating, models.ErrorReviewSubmitted:
+		return exitConflict
+	case models.ErrorEmptyTeam, models.ErrorDuplicateUserID, models.ErrorUserInAnotherTeam,
+		models.ErrorInvalidStatus, models.ErrorInvalidReviewer, models.ErrorAlreadyAssigned, models.ErrorInvalidArchive:
+		return exitUsage
+	default:
+		return exitError
+	}
+}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("shutdown error: ", err)
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	log.Println("Server stopped gracefully")
+	var b strings.Builder
+	b.WriteString("usage: pr-service [flags] <command>\n\ncommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s\n", commands[name].usage)
+	}
+	b.WriteString("\nflags:\n")
+	b.WriteString(config.FlagsUsage)
+	return b.String()
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"avito-pr-service/internal/models"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"usage", usagef("bad args"), exitUsage},
+		{"not found", models.AppError{Code: models.ErrorNotFound}, exitNotFound},
+		{"wrapped not found", fmt.Errorf("import: %w", models.AppError{Code: models.ErrorNotFound}), exitNotFound},
+		{"team exists", models.AppError{Code: models.ErrorTeamExists}, exitConflict},
+		{"pr merged", models.AppError{Code: models.ErrorPRMerged}, exitConflict},
+		{"no candidate", models.AppError{Code: models.ErrorNoCandidate}, exitConflict},
+		{"database not empty", models.ErrDatabaseNotEmpty, exitConflict},
+		{"invalid input", models.AppError{Code: models.ErrorDuplicateUserID}, exitUsage},
+		{"invalid archive", models.AppError{Code: models.ErrorInvalidArchive}, exitUsage},
+		{"unknown app error", models.AppError{Code: models.ErrorForbidden}, exitError},
+		{"invalid import file", &models.ImportValidationError{Errors: []models.ImportRowError{{Location: "line 2"}}}, exitUsage},
+		{"plain error", errors.New("connection refused"), exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	// ни один из случаев не должен доходить до подключения к БД
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"help", []string{"help"}, exitOK},
+		{"help flag", []string{"-h"}, exitOK},
+		{"unknown global flag", []string{"-verbose", "stats"}, exitUsage},
+		{"invalid config", []string{"-log-level", "loud", "stats"}, exitError},
+		{"unknown command", []string{"frobnicate"}, exitUsage},
+		{"serve with args", []string{"serve", "now"}, exitUsage},
+		{"migrate without action", []string{"migrate"}, exitUsage},
+		{"team without file", []string{"team", "import"}, exitUsage},
+		{"team unknown format", []string{"team", "import", "-format", "xml", "teams.xml"}, exitUsage},
+		{"team unknown action", []string{"team", "delete", "x"}, exitUsage},
+		{"user bad bool", []string{"user", "set-active", "u1", "maybe"}, exitUsage},
+		{"pr missing reviewer", []string{"pr", "reassign", "pr-1"}, exitUsage},
+		{"stats with args", []string{"stats", "all"}, exitUsage},
+		{"export unknown flag", []string{"export", "-x"}, exitUsage},
+		{"restore without file", []string{"restore"}, exitUsage},
+		{"restore missing file", []string{"restore", "/nonexistent/backup.jsonl"}, exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, run(tt.args))
+		})
+	}
+}
+
+func TestReadImport(t *testing.T) {
+	dir := t.TempDir()
+
+	csvPath := dir + "/teams.csv"
+	writeFile(t, csvPath, "team_name,user_id,username\nbackend,u1,Alice\n")
+	req, err := readImport(csvPath, "")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportFormatCSV, req.Format)
+	assert.Contains(t, string(req.Data), "backend,u1,Alice")
+
+	req, err = readImport(csvPath, models.ImportFormatYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportFormatYAML, req.Format)
+
+	_, err = readImport(dir+"/teams.txt", "")
+	assert.Equal(t, exitUsage, exitCode(err))
+
+	_, err = readImport(csvPath, "xml")
+	assert.Equal(t, exitUsage, exitCode(err))
+
+	_, err = readImport(dir+"/missing.json", "")
+	assert.Equal(t, exitError, exitCode(err))
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"avito-pr-service/config"
+	"avito-pr-service/internal/repository/postgres"
+	"avito-pr-service/migrations"
+	"context"
+	"fmt"
+	"os"
+)
+
+func runMigrate(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) != 1 {
+		return usagef("migrate requires exactly one action")
+	}
+
+	m, err := postgres.NewMigrator(cfg.DB.DSN, cliLogger())
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		from, to, err := m.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "migrated from %d to %d\n", from, to)
+		return nil
+	case "down":
+		if err := m.Down(); err != nil {
+			return err
+		}
+		return printSchemaVersion(m)
+	case "status":
+		return printSchemaVersion(m)
+	default:
+		return usagef("unknown migrate action %q", args[0])
+	}
+}
+
+func printSchemaVersion(m *postgres.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	latest, err := migrations.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "version: %d\nlatest: %d\ndirty: %t\n", version, latest, dirty)
+	return nil
+}
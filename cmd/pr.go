@@ -0,0 +1,33 @@
+package main
+
+import (
+	"avito-pr-service/config"
+	"avito-pr-service/internal/models"
+	"context"
+	"os"
+)
+
+func runPR(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) != 3 || args[0] != "reassign" {
+		return usagef("expected: pr reassign <pr_id> <old_reviewer_id>")
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	pr, replacedBy, err := a.pr.ReassignReviewer(ctx, models.ReassignRequest{
+		PRID:          args[1],
+		OldReviewerID: args[2],
+		Reason:        models.ReassignReasonManual,
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(os.Stdout, map[string]any{
+		"pr":          pr,
+		"replaced_by": replacedBy,
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"avito-pr-service/config"
+	"avito-pr-service/internal/server"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+func runServe(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) > 0 {
+		return usagef("serve takes no arguments")
+	}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Start()
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server error: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownDrainDelay+cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"avito-pr-service/config"
+	"context"
+	"os"
+)
+
+func runStats(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) > 0 {
+		return usagef("stats takes no arguments")
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	stats, err := a.pr.GetUserStats(ctx)
+	if err != nil {
+		return err
+	}
+	return printJSON(os.Stdout, map[string]any{"stats": stats})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"avito-pr-service/config"
+	"avito-pr-service/internal/importer"
+	"avito-pr-service/internal/models"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func runTeam(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "import" {
+		return usagef("expected: team import [-dry-run] [-format json|yaml|csv] <file>")
+	}
+
+	fs := flag.NewFlagSet("team import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dryRun := fs.Bool("dry-run", false, "show changes without applying them")
+	format := fs.String("format", "", "file format, detected from extension by default")
+	if err := fs.Parse(args[1:]); err != nil {
+		return usagef("%v", err)
+	}
+	if fs.NArg() != 1 {
+		return usagef("expected exactly one file")
+	}
+	path := fs.Arg(0)
+
+	req, err := readImport(path, *format)
+	if err != nil {
+		return err
+	}
+	req.DryRun = *dryRun
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	result, err := a.team.ImportTeams(ctx, req)
+	var verr *models.ImportValidationError
+	if errors.As(err, &verr) {
+		if perr := printJSON(os.Stdout, map[string]any{"errors": verr.Errors}); perr != nil {
+			return perr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(os.Stdout, result)
+}
+
+// "-" означает stdin, формат тогда по умолчанию JSON
+func readImport(path, format string) (models.ImportRequest, error) {
+	if format == "" {
+		if path == "-" {
+			format = models.ImportFormatJSON
+		} else {
+			var err error
+			if format, err = importer.FormatFromPath(path); err != nil {
+				return models.ImportRequest{}, usagef("%v", err)
+			}
+		}
+	}
+	switch format {
+	case models.ImportFormatJSON, models.ImportFormatYAML, models.ImportFormatCSV:
+	default:
+		return models.ImportRequest{}, usagef("unknown format %q", format)
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return models.ImportRequest{}, fmt.Errorf("read %s: %w", path, err)
+	}
+	return models.ImportRequest{Format: format, Data: data}, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"avito-pr-service/config"
+	"avito-pr-service/internal/models"
+	"context"
+	"os"
+	"strconv"
+)
+
+func runUser(ctx context.Context, cfg config.Config, args []string) error {
+	if len(args) != 3 || args[0] != "set-active" {
+		return usagef("expected: user set-active <user_id> <true|false>")
+	}
+	isActive, err := strconv.ParseBool(args[2])
+	if err != nil {
+		return usagef("invalid is_active value %q", args[2])
+	}
+
+	a, err := newApp(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	req := models.SetUserActiveRequest{UserID: args[1], IsActive: isActive}
+	if err := a.user.SetActive(ctx, req); err != nil {
+		return err
+	}
+
+	user, err := a.user.GetUser(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	return printJSON(os.Stdout, user)
+}
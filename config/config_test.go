@@ -0,0 +1,198 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	assert.NoError(t, Default().Validate())
+}
+
+func TestLoad_Layers(t *testing.T) {
+	path := writeConfig(t, `
+port: "9000"
+docs_url: ""
+db:
+  dsn: postgres://app:secret@db:5432/pr_service
+  max_conns: 20
+  max_conn_idle_time: 1m
+log:
+  level: debug
+cors:
+  allowed_origins: [https://a.example.com]
+stale_prs:
+  teams:
+    backend: {threshold: 24h, lead: u1}
+shutdown_timeout: 15s
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PORT", "9100")
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("STALE_PRS_THRESHOLD", "48h")
+	t.Setenv("JOBS_WORKERS", "8")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	flags, rest, err := ParseFlags([]string{"-port", "9200", "migrate", "up"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"migrate", "up"}, rest)
+
+	cfg, err := Load(flags)
+	require.NoError(t, err)
+
+	// флаг важнее окружения, окружение важнее файла, файл важнее значений по умолчанию
+	assert.Equal(t, "9200", cfg.Port)
+	assert.Equal(t, "json", cfg.Log.Format)
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.AllowedOrigins)
+	assert.Equal(t, "postgres://app:secret@db:5432/pr_service", cfg.DB.DSN)
+	assert.Equal(t, int32(20), cfg.DB.MaxConns)
+	assert.Equal(t, int32(2), cfg.DB.MinConns)
+	assert.Equal(t, time.Minute, cfg.DB.MaxConnIdleTime)
+	assert.Equal(t, time.Hour, cfg.DB.MaxConnLifetime)
+	assert.Equal(t, 15*time.Second, cfg.ShutdownTimeout)
+	assert.Empty(t, cfg.DocsURL)
+	assert.Equal(t, 48*time.Hour, cfg.StalePRs.Threshold)
+	assert.Equal(t, 8, cfg.Jobs.Workers)
+	assert.Equal(t, map[string]StaleTeamConfig{"backend": {Threshold: 24 * time.Hour, Lead: "u1"}}, cfg.StalePRs.Teams)
+}
+
+func TestLoad_ConfigFlagOverridesEnv(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "/nonexistent/config.yaml")
+	path := writeConfig(t, "port: \"9300\"\n")
+
+	flags, _, err := ParseFlags([]string{"-config", path})
+	require.NoError(t, err)
+
+	cfg, err := Load(flags)
+	require.NoError(t, err)
+	assert.Equal(t, "9300", cfg.Port)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Run("unknown key", func(t *testing.T) {
+		flags, _, err := ParseFlags([]string{"-config", writeConfig(t, "db:\n  max_con: 5\n")})
+		require.NoError(t, err)
+		_, err = Load(flags)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field max_con not found")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		flags, _, err := ParseFlags([]string{"-config", "/nonexistent/config.yaml"})
+		require.NoError(t, err)
+		_, err = Load(flags)
+		assert.ErrorContains(t, err, "read config file")
+	})
+
+	t.Run("invalid env values", func(t *testing.T) {
+		t.Setenv("DB_MAX_CONNS", "many")
+		t.Setenv("SHUTDOWN_TIMEOUT", "5")
+		_, err := Load(Flags{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `DB_MAX_CONNS: invalid integer "many"`)
+		assert.Contains(t, err.Error(), `SHUTDOWN_TIMEOUT: invalid duration "5"`)
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		_, _, err := ParseFlags([]string{"-verbose"})
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	cfg := Default()
+	cfg.Port = "http"
+	cfg.DocsURL = "localhost"
+	cfg.DB.DSN = " "
+	cfg.DB.MaxConns = 2
+	cfg.DB.MinConns = 5
+	cfg.Log.Level = "verbose"
+	cfg.Log.Format = "xml"
+	cfg.CORS.AllowedOrigins = nil
+	cfg.AuthMode = AuthModeJWT
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Jobs.Workers = 0
+	cfg.StalePRs.Interval = 0
+	cfg.Rebalance.MinGap = 1
+	cfg.Outbox.Retention = 0
+	cfg.Stream.Heartbeat = 0
+	cfg.ShutdownTimeout = 0
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		`port: must be a number between 1 and 65535, got "http"`,
+		`docs_url: must be an absolute URL`,
+		`db.dsn: must not be empty`,
+		`db.min_conns: must be between 0 and db.max_conns, got 5`,
+		`log.level: must be debug, info, warn or error, got "verbose"`,
+		`log.format: must be "text" or "json", got "xml"`,
+		`cors.allowed_origins: must not be empty`,
+		`jwt: auth_mode "jwt" requires jwks_file or jwks_url`,
+		`jwt.issuer: must not be empty in auth_mode "jwt"`,
+		`jwt.audience: must not be empty in auth_mode "jwt"`,
+		`tracing.exporter: must be "none" or "otlp", got "jaeger"`,
+		`jobs.workers: must be positive, got 0`,
+		`stale_prs.interval: must be at least 1s`,
+		`rebalance.min_gap: must be at least 2, got 1`,
+		`outbox.retention: must be positive, got 0s`,
+		`stream.heartbeat: must be at least 1s, got 0s`,
+		`shutdown_timeout: must be positive`,
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.DB.DSN = "postgres://app:s3cr3t@db:5432/pr_service?sslmode=disable"
+	cfg.GitHubWebhookSecret = "gh-secret"
+
+	r := cfg.Redacted()
+	assert.Equal(t, "postgres://app:xxxxx@db:5432/pr_service?sslmode=disable", r.DB.DSN)
+	assert.Equal(t, redacted, r.GitHubWebhookSecret)
+	assert.Empty(t, r.GitLabWebhookToken)
+
+	// исходная конфигурация не меняется
+	assert.Equal(t, "gh-secret", cfg.GitHubWebhookSecret)
+	assert.Contains(t, cfg.DB.DSN, "s3cr3t")
+
+	cfg.DB.DSN = "postgres://app@db/pr_service?password=s3cr3t&sslmode=disable"
+	assert.Equal(t, "postgres://app@db/pr_service?password=xxxxx&sslmode=disable", cfg.Redacted().DB.DSN)
+}
+
+func TestRedacted_KeyValueDSN(t *testing.T) {
+	cfg := Default()
+	cfg.DB.DSN = "host=db user=app password=s3cr3t dbname=pr_service"
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "host=db user=app password=xxxxx dbname=pr_service", cfg.Redacted().DB.DSN)
+
+	cfg.DB.DSN = `host=db password='with \' quote' sslmode=disable`
+	assert.Equal(t, "host=db password=xxxxx sslmode=disable", cfg.Redacted().DB.DSN)
+}
+
+func TestLoad_ExampleMatchesDefaults(t *testing.T) {
+	// пустые переменные считаются незаданными
+	for _, key := range []string{"CONFIG_FILE", "DB_DSN", "PORT", "LOG_LEVEL", "AUTH_MODE"} {
+		t.Setenv(key, "")
+	}
+
+	flags, _, err := ParseFlags([]string{"-config", "../config.example.yaml"})
+	require.NoError(t, err)
+
+	cfg, err := Load(flags)
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flags — явно заданные флаги командной строки; незаданные флаги
+// не перекрывают файл и окружение
+type Flags struct {
+	ConfigFile string
+	values     map[string]string
+}
+
+// ParseFlags разбирает глобальные флаги до имени подкоманды и возвращает
+// оставшиеся аргументы
+func ParseFlags(args []string) (Flags, []string, error) {
+	fs := flag.NewFlagSet("pr-service", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFile := fs.String("config", "", "")
+	fs.String("port", "", "")
+	fs.String("dsn", "", "")
+	fs.String("log-level", "", "")
+	fs.String("log-format", "", "")
+	if err := fs.Parse(args); err != nil {
+		return Flags{}, nil, err
+	}
+
+	flags := Flags{ConfigFile: *configFile, values: make(map[string]string)}
+	fs.Visit(func(f *flag.Flag) {
+		flags.values[f.Name] = f.Value.String()
+	})
+	return flags, fs.Args(), nil
+}
+
+// FlagsUsage описывает глобальные флаги для справки
+const FlagsUsage = `  -config file      YAML config file (env CONFIG_FILE)
+  -port port        HTTP port (env PORT)
+  -dsn url          Postgres URL (env DB_DSN)
+  -log-level level  debug, info, warn or error (env LOG_LEVEL)
+  -log-format fmt   text or json (env LOG_FORMAT)
+`
+
+// Load применяет слои по порядку: значения по умолчанию, YAML-файл
+// (флаг -config или CONFIG_FILE), переменные окружения, флаги — и проверяет результат
+func Load(flags Flags) (*Config, error) {
+	cfg := Default()
+
+	path := flags.ConfigFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	cfg.applyFlags(flags)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	var env envLoader
+	env.string(&c.Port, "PORT")
+	env.string(&c.DocsURL, "DOCS_URL")
+
+	env.string(&c.DB.DSN, "DB_DSN")
+	env.int32(&c.DB.MaxConns, "DB_MAX_CONNS")
+	env.int32(&c.DB.MinConns, "DB_MIN_CONNS")
+	env.duration(&c.DB.MaxConnLifetime, "DB_MAX_CONN_LIFETIME")
+	env.duration(&c.DB.MaxConnIdleTime, "DB_MAX_CONN_IDLE_TIME")
+
+	env.string(&c.Log.Level, "LOG_LEVEL")
+	env.string(&c.Log.Format, "LOG_FORMAT")
+	env.list(&c.CORS.AllowedOrigins, "CORS_ALLOWED_ORIGINS")
+
+	env.string(&c.AuthMode, "AUTH_MODE")
+	env.string(&c.JWT.JWKSFile, "JWT_JWKS_FILE")
+	env.string(&c.JWT.JWKSURL, "JWT_JWKS_URL")
+	env.string(&c.JWT.Issuer, "JWT_ISSUER")
+	env.string(&c.JWT.Audience, "JWT_AUDIENCE")
+	env.string(&c.JWT.UserIDClaim, "JWT_USER_ID_CLAIM")
+	env.string(&c.JWT.RolesClaim, "JWT_ROLES_CLAIM")
+
+	env.string(&c.GitHubWebhookSecret, "GITHUB_WEBHOOK_SECRET")
+	env.string(&c.GitLabWebhookToken, "GITLAB_WEBHOOK_TOKEN")
+
+	env.string(&c.Tracing.Exporter, "OTEL_TRACES_EXPORTER")
+	env.string(&c.Tracing.ServiceName, "OTEL_SERVICE_NAME")
+
+	env.int(&c.Jobs.Workers, "JOBS_WORKERS")
+	env.duration(&c.Jobs.PollInterval, "JOBS_POLL_INTERVAL")
+	env.duration(&c.Jobs.Retention, "JOBS_RETENTION")
+
+	env.bool(&c.StalePRs.Enabled, "STALE_PRS_ENABLED")
+	env.duration(&c.StalePRs.Interval, "STALE_PRS_INTERVAL")
+	env.duration(&c.StalePRs.Threshold, "STALE_PRS_THRESHOLD")
+
+	env.string(&c.Rebalance.Schedule, "REBALANCE_SCHEDULE")
+	env.int(&c.Rebalance.MaxMoves, "REBALANCE_MAX_MOVES")
+	env.int(&c.Rebalance.MinGap, "REBALANCE_MIN_GAP")
+
+	env.duration(&c.Outbox.Retention, "OUTBOX_RETENTION")
+
+	env.duration(&c.Stream.PollInterval, "STREAM_POLL_INTERVAL")
+	env.duration(&c.Stream.Heartbeat, "STREAM_HEARTBEAT")
+
+	env.bool(&c.MigrateOnStart, "MIGRATE_ON_START")
+	env.duration(&c.ShutdownDrainDelay, "SHUTDOWN_DRAIN_DELAY")
+	env.duration(&c.ShutdownTimeout, "SHUTDOWN_TIMEOUT")
+
+	return errors.Join(env.errs...)
+}
+
+func (c *Config) applyFlags(flags Flags) {
+	for name, value := range flags.values {
+		switch name {
+		case "port":
+			c.Port = value
+		case "dsn":
+			c.DB.DSN = value
+		case "log-level":
+			c.Log.Level = value
+		case "log-format":
+			c.Log.Format = value
+		}
+	}
+}
+
+// пустая переменная считается незаданной, как и раньше
+type envLoader struct {
+	errs []error
+}
+
+func (l *envLoader) lookup(key string) (string, bool) {
+	value := os.Getenv(key)
+	return value, value != ""
+}
+
+func (l *envLoader) string(dst *string, key string) {
+	if value, ok := l.lookup(key); ok {
+		*dst = value
+	}
+}
+
+func (l *envLoader) list(dst *[]string, key string) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+	items := strings.Split(value, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	*dst = items
+}
+
+func (l *envLoader) int(dst *int, key string) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: invalid integer %q", key, value))
+		return
+	}
+	*dst = n
+}
+
+func (l *envLoader) int32(dst *int32, key string) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: invalid integer %q", key, value))
+		return
+	}
+	*dst = int32(n)
+}
+
+func (l *envLoader) bool(dst *bool, key string) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: invalid boolean %q", key, value))
+		return
+	}
+	*dst = b
+}
+
+func (l *envLoader) duration(dst *time.Duration, key string) {
+	value, ok := l.lookup(key)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s: invalid duration %q", key, value))
+		return
+	}
+	*dst = d
+}
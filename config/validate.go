@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// пароль в key=value DSN, в том числе в кавычках
+var dsnPassword = regexp.MustCompile(`(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// Validate возвращает все ошибки сразу, а не первую найденную
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		add("port: must be a number between 1 and 65535, got %q", c.Port)
+	}
+	if c.DocsURL != "" {
+		if u, err := url.Parse(c.DocsURL); err != nil || u.Scheme == "" || u.Host == "" {
+			add("docs_url: must be an absolute URL, got %q", c.DocsURL)
+		}
+	}
+
+	if strings.TrimSpace(c.DB.DSN) == "" {
+		add("db.dsn: must not be empty")
+	}
+	if c.DB.MaxConns < 1 {
+		add("db.max_conns: must be positive, got %d", c.DB.MaxConns)
+	}
+	if c.DB.MinConns < 0 || c.DB.MinConns > c.DB.MaxConns {
+		add("db.min_conns: must be between 0 and db.max_conns, got %d", c.DB.MinConns)
+	}
+	if c.DB.MaxConnLifetime <= 0 {
+		add("db.max_conn_lifetime: must be positive, got %s", c.DB.MaxConnLifetime)
+	}
+	if c.DB.MaxConnIdleTime <= 0 {
+		add("db.max_conn_idle_time: must be positive, got %s", c.DB.MaxConnIdleTime)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		add("log.level: must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	if c.Log.Format != LogFormatText && c.Log.Format != LogFormatJSON {
+		add("log.format: must be %q or %q, got %q", LogFormatText, LogFormatJSON, c.Log.Format)
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		add("cors.allowed_origins: must not be empty")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "" {
+			add("cors.allowed_origins: contains an empty origin")
+		}
+	}
+
+	switch c.AuthMode {
+	case AuthModeNone:
+	case AuthModeJWT:
+		if c.JWT.JWKSFile == "" && c.JWT.JWKSURL == "" {
+			add("jwt: auth_mode %q requires jwks_file or jwks_url", AuthModeJWT)
+		}
+		if c.JWT.Issuer == "" {
+			add("jwt.issuer: must not be empty in auth_mode %q", AuthModeJWT)
+		}
+		if c.JWT.Audience == "" {
+			add("jwt.audience: must not be empty in auth_mode %q", AuthModeJWT)
+		}
+	default:
+		add("auth_mode: must be %q or %q, got %q", AuthModeNone, AuthModeJWT, c.AuthMode)
+	}
+
+	if c.Tracing.Exporter != TracesExporterNone && c.Tracing.Exporter != TracesExporterOTLP {
+		add("tracing.exporter: must be %q or %q, got %q", TracesExporterNone, TracesExporterOTLP, c.Tracing.Exporter)
+	}
+
+	if c.Jobs.Workers < 1 {
+		add("jobs.workers: must be positive, got %d", c.Jobs.Workers)
+	}
+	if c.Jobs.PollInterval <= 0 {
+		add("jobs.poll_interval: must be positive, got %s", c.Jobs.PollInterval)
+	}
+	if c.Jobs.Retention <= 0 {
+		add("jobs.retention: must be positive, got %s", c.Jobs.Retention)
+	}
+
+	if c.StalePRs.Interval < time.Second {
+		add("stale_prs.interval: must be at least 1s, got %s", c.StalePRs.Interval)
+	}
+	if c.StalePRs.Threshold <= 0 {
+		add("stale_prs.threshold: must be positive, got %s", c.StalePRs.Threshold)
+	}
+	for name, team := range c.StalePRs.Teams {
+		if team.Threshold < 0 {
+			add("stale_prs.teams.%s.threshold: must not be negative, got %s", name, team.Threshold)
+		}
+	}
+
+	if c.Rebalance.MaxMoves < 1 {
+		add("rebalance.max_moves: must be positive, got %d", c.Rebalance.MaxMoves)
+	}
+	if c.Rebalance.MinGap < 2 {
+		add("rebalance.min_gap: must be at least 2, got %d", c.Rebalance.MinGap)
+	}
+
+	if c.Outbox.Retention <= 0 {
+		add("outbox.retention: must be positive, got %s", c.Outbox.Retention)
+	}
+
+	if c.Stream.PollInterval <= 0 {
+		add("stream.poll_interval: must be positive, got %s", c.Stream.PollInterval)
+	}
+	if c.Stream.Heartbeat < time.Second {
+		add("stream.heartbeat: must be at least 1s, got %s", c.Stream.Heartbeat)
+	}
+
+	if c.ShutdownDrainDelay < 0 {
+		add("shutdown_drain_delay: must not be negative, got %s", c.ShutdownDrainDelay)
+	}
+	if c.ShutdownTimeout <= 0 {
+		add("shutdown_timeout: must be positive, got %s", c.ShutdownTimeout)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+}
+
+// Redacted возвращает копию без секретов для отладочной ручки /config
+func (c Config) Redacted() Config {
+	if u, err := url.Parse(c.DB.DSN); err == nil && u.Scheme != "" {
+		if u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), "xxxxx")
+			}
+		}
+		// libpq принимает пароль и параметром запроса
+		if q := u.Query(); q.Has("password") {
+			q.Set("password", "xxxxx")
+			u.RawQuery = q.Encode()
+		}
+		c.DB.DSN = u.String()
+	} else {
+		c.DB.DSN = dsnPassword.ReplaceAllString(c.DB.DSN, "${1}xxxxx")
+	}
+	if c.GitHubWebhookSecret != "" {
+		c.GitHubWebhookSecret = redacted
+	}
+	if c.GitLabWebhookToken != "" {
+		c.GitLabWebhookToken = redacted
+	}
+	c.CORS.AllowedOrigins = append([]string(nil), c.CORS.AllowedOrigins...)
+	return c
+}
+
+// SlogLevel переводит уровень из конфигурации; вызывается после Validate
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.Level))
+	return level
+}
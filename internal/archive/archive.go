@@ -0,0 +1,344 @@
+package archive
+
+import (
+	"avito-pr-service/internal/models"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// максимум сообщений о нарушениях целостности в одной ошибке
+const maxIntegrityErrors = 20
+
+// каждая строка архива — {"type": "...", "data": {...}}, первая строка — заголовок
+type record struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Write пишет архив в порядке зависимостей: команды, пользователи, PR,
+// ревьюверы, затем остальное
+func Write(w io.Writer, a models.Archive) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	write := func(typ string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", typ, err)
+		}
+		return enc.Encode(record{Type: typ, Data: data})
+	}
+
+	if err := write(models.ArchiveRecordHeader, a.Header); err != nil {
+		return err
+	}
+	for _, v := range a.Teams {
+		if err := write(models.ArchiveRecordTeam, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range a.Users {
+		if err := write(models.ArchiveRecordUser, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range a.PullRequests {
+		if err := write(models.ArchiveRecordPullRequest, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range a.Reviewers {
+		if err := write(models.ArchiveRecordReviewer, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range a.Reassignments {
+		if err := write(models.ArchiveRecordReassignment, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range a.VCSIdentities {
+		if err := write(models.ArchiveRecordVCSIdentity, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range a.Webhooks {
+		if err := write(models.ArchiveRecordWebhook, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range a.Snapshots {
+		if err := write(models.ArchiveRecordSnapshot, v); err != nil {
+			return err
+		}
+	}
+	for _, v := range a.StaleSteps {
+		if err := write(models.ArchiveRecordStaleStep, v); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Read разбирает архив и проверяет заголовок; целостность ссылок
+// проверяет Validate
+func Read(r io.Reader) (models.Archive, error) {
+	var a models.Archive
+	br := bufio.NewReader(r)
+
+	line := 0
+	hasHeader := false
+	for {
+		raw, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return a, fmt.Errorf("read archive: %w", err)
+		}
+		eof := errors.Is(err, io.EOF)
+
+		line++
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 {
+			var rec record
+			if err := json.Unmarshal(raw, &rec); err != nil {
+				return a, fmt.Errorf("line %d: invalid JSON: %w", line, err)
+			}
+
+			if !hasHeader {
+				if rec.Type != models.ArchiveRecordHeader {
+					return a, fmt.Errorf("line %d: archive must start with a header record", line)
+				}
+				if err := json.Unmarshal(rec.Data, &a.Header); err != nil {
+					return a, fmt.Errorf("line %d: invalid header: %w", line, err)
+				}
+				if err := checkHeader(a.Header); err != nil {
+					return a, err
+				}
+				hasHeader = true
+			} else if err := decodeRecord(&a, rec); err != nil {
+				return a, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		if eof {
+			break
+		}
+	}
+
+	if !hasHeader {
+		return a, errors.New("archive is empty")
+	}
+	return a, nil
+}
+
+func checkHeader(h models.ArchiveHeader) error {
+	if h.Format != models.ArchiveFormat {
+		return fmt.Errorf("unknown archive format %q", h.Format)
+	}
+	if h.Version < 1 {
+		return fmt.Errorf("invalid archive version %d", h.Version)
+	}
+	if h.Version > models.ArchiveVersion {
+		return fmt.Errorf("archive version %d is newer than supported version %d, upgrade the service", h.Version, models.ArchiveVersion)
+	}
+	return nil
+}
+
+func decodeRecord(a *models.Archive, rec record) error {
+	var err error
+	switch rec.Type {
+	case models.ArchiveRecordTeam:
+		var v models.ArchiveTeam
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.Teams = append(a.Teams, v)
+		}
+	case models.ArchiveRecordUser:
+		var v models.User
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.Users = append(a.Users, v)
+		}
+	case models.ArchiveRecordPullRequest:
+		var v models.ArchivePullRequest
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.PullRequests = append(a.PullRequests, v)
+		}
+	case models.ArchiveRecordReviewer:
+		var v models.ArchiveReviewer
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.Reviewers = append(a.Reviewers, v)
+		}
+	case models.ArchiveRecordReassignment:
+		var v models.ArchiveReassignment
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.Reassignments = append(a.Reassignments, v)
+		}
+	case models.ArchiveRecordVCSIdentity:
+		var v models.VCSIdentity
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.VCSIdentities = append(a.VCSIdentities, v)
+		}
+	case models.ArchiveRecordWebhook:
+		var v models.ArchiveWebhook
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.Webhooks = append(a.Webhooks, v)
+		}
+	case models.ArchiveRecordSnapshot:
+		var v models.ArchiveMemberSnapshot
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.Snapshots = append(a.Snapshots, v)
+		}
+	case models.ArchiveRecordStaleStep:
+		var v models.ArchiveStaleStep
+		if err = json.Unmarshal(rec.Data, &v); err == nil {
+			a.StaleSteps = append(a.StaleSteps, v)
+		}
+	case models.ArchiveRecordHeader:
+		return errors.New("duplicate header record")
+	default:
+		return fmt.Errorf("unknown record type %q", rec.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s record: %w", rec.Type, err)
+	}
+	return nil
+}
+
+// Validate проверяет уникальность ключей и ссылочную целостность,
+// чтобы восстановление не упало на середине из-за внешних ключей
+func Validate(a models.Archive) error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	teams := make(map[string]bool, len(a.Teams))
+	for _, t := range a.Teams {
+		switch {
+		case t.Name == "":
+			add("team with empty name")
+		case teams[t.Name]:
+			add("duplicate team %q", t.Name)
+		}
+		teams[t.Name] = true
+	}
+
+	users := make(map[string]bool, len(a.Users))
+	for _, u := range a.Users {
+		switch {
+		case u.UserID == "":
+			add("user with empty user_id")
+		case users[u.UserID]:
+			add("duplicate user %q", u.UserID)
+		case !teams[u.TeamName]:
+			add("user %q references unknown team %q", u.UserID, u.TeamName)
+		}
+		users[u.UserID] = true
+	}
+
+	prs := make(map[string]bool, len(a.PullRequests))
+	for _, pr := range a.PullRequests {
+		switch {
+		case pr.ID == "":
+			add("pull request with empty id")
+		case prs[pr.ID]:
+			add("duplicate pull request %q", pr.ID)
+		case !users[pr.AuthorID]:
+			add("pull request %q references unknown author %q", pr.ID, pr.AuthorID)
+		case pr.Status != models.StatusOpen && pr.Status != models.StatusMerged:
+			add("pull request %q has invalid status %q", pr.ID, pr.Status)
+		}
+		prs[pr.ID] = true
+	}
+
+	reviewers := make(map[[2]string]bool, len(a.Reviewers))
+	for _, r := range a.Reviewers {
+		key := [2]string{r.PRID, r.UserID}
+		switch {
+		case reviewers[key]:
+			add("duplicate reviewer %q on pull request %q", r.UserID, r.PRID)
+		case !prs[r.PRID]:
+			add("reviewer %q references unknown pull request %q", r.UserID, r.PRID)
+		case !users[r.UserID]:
+			add("pull request %q references unknown reviewer %q", r.PRID, r.UserID)
+		case r.Verdict != "" && r.Verdict != models.VerdictApproved && r.Verdict != models.VerdictChangesRequested:
+			add("reviewer %q on pull request %q has invalid verdict %q", r.UserID, r.PRID, r.Verdict)
+		}
+		reviewers[key] = true
+	}
+
+	for _, r := range a.Reassignments {
+		switch {
+		case !prs[r.PRID]:
+			add("reassignment references unknown pull request %q", r.PRID)
+		case !users[r.OldReviewerID]:
+			add("reassignment on pull request %q references unknown reviewer %q", r.PRID, r.OldReviewerID)
+		case !users[r.NewReviewerID]:
+			add("reassignment on pull request %q references unknown reviewer %q", r.PRID, r.NewReviewerID)
+		case r.Verdict != "" && r.Verdict != models.VerdictApproved && r.Verdict != models.VerdictChangesRequested:
+			add("reassignment on pull request %q has invalid verdict %q", r.PRID, r.Verdict)
+		}
+	}
+
+	identities := make(map[[2]string]bool, len(a.VCSIdentities))
+	for _, id := range a.VCSIdentities {
+		key := [2]string{id.Provider, id.Login}
+		switch {
+		case identities[key]:
+			add("duplicate %s identity %q", id.Provider, id.Login)
+		case !users[id.UserID]:
+			add("%s identity %q references unknown user %q", id.Provider, id.Login, id.UserID)
+		}
+		identities[key] = true
+	}
+
+	webhooks := make(map[int64]bool, len(a.Webhooks))
+	for _, wh := range a.Webhooks {
+		switch {
+		case wh.ID <= 0:
+			add("webhook subscription with invalid id %d", wh.ID)
+		case webhooks[wh.ID]:
+			add("duplicate webhook subscription %d", wh.ID)
+		}
+		webhooks[wh.ID] = true
+	}
+
+	snapshots := make(map[[2]string]bool, len(a.Snapshots))
+	for _, s := range a.Snapshots {
+		key := [2]string{s.TeamName, s.UserID}
+		switch {
+		case snapshots[key]:
+			add("duplicate snapshot of user %q in team %q", s.UserID, s.TeamName)
+		case !teams[s.TeamName]:
+			add("snapshot references unknown team %q", s.TeamName)
+		case !users[s.UserID]:
+			add("snapshot of team %q references unknown user %q", s.TeamName, s.UserID)
+		}
+		snapshots[key] = true
+	}
+
+	// ревьюверы и лид в шагах — журнал без внешних ключей, их не проверяем
+	for _, s := range a.StaleSteps {
+		switch {
+		case !prs[s.PRID]:
+			add("stale step references unknown pull request %q", s.PRID)
+		case s.Step != models.StaleStepRemind && s.Step != models.StaleStepReassign && s.Step != models.StaleStepEscalate:
+			add("stale step on pull request %q has invalid step %q", s.PRID, s.Step)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	if len(problems) > maxIntegrityErrors {
+		extra := len(problems) - maxIntegrityErrors
+		problems = append(problems[:maxIntegrityErrors], fmt.Sprintf("and %d more", extra))
+	}
+	errs := make([]error, len(problems))
+	for i, p := range problems {
+		errs[i] = errors.New(p)
+	}
+	return fmt.Errorf("archive integrity check failed: %w", errors.Join(errs...))
+}
@@ -0,0 +1,168 @@
+package archive
+
+import (
+	"avito-pr-service/internal/models"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testArchive() models.Archive {
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	merged := created.Add(time.Hour)
+	return models.Archive{
+		Header: models.ArchiveHeader{Format: models.ArchiveFormat, Version: models.ArchiveVersion, CreatedAt: created},
+		Teams:  []models.ArchiveTeam{{Name: "backend"}},
+		Users: []models.User{
+			{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+			{UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: false},
+		},
+		PullRequests: []models.ArchivePullRequest{
+			{ID: "pr-1", Name: "Add search", AuthorID: "u1", Status: models.StatusMerged, CreatedAt: created, MergedAt: &merged},
+			{ID: "pr-2", Name: "Fix login", AuthorID: "u2", Status: models.StatusOpen, CreatedAt: created},
+		},
+		Reviewers: []models.ArchiveReviewer{
+			{PRID: "pr-1", UserID: "u2", Verdict: models.VerdictApproved, VerdictAt: &merged},
+			{PRID: "pr-2", UserID: "u1"},
+		},
+		Reassignments: []models.ArchiveReassignment{
+			{PRID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u2", ReassignedAt: merged, Verdict: models.VerdictChangesRequested, VerdictAt: &created},
+		},
+		VCSIdentities: []models.VCSIdentity{{Provider: models.VCSProviderGitHub, Login: "alice", UserID: "u1"}},
+		Webhooks: []models.ArchiveWebhook{
+			{ID: 3, URL: "https://bot.example.com/hook", Secret: "0123456789abcdef", EventTypes: []string{"pr.merged"}, IsActive: true, CreatedAt: created},
+		},
+		Snapshots: []models.ArchiveMemberSnapshot{{TeamName: "backend", UserID: "u2", WasActive: true, CreatedAt: merged}},
+		StaleSteps: []models.ArchiveStaleStep{
+			{PRID: "pr-2", Step: models.StaleStepRemind, ReviewerID: "u1", InactiveSince: created, CreatedAt: merged},
+			{PRID: "pr-2", Step: models.StaleStepEscalate, LeadID: "u2", InactiveSince: created, CreatedAt: merged},
+		},
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	want := testArchive()
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, want))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 14)
+	assert.True(t, strings.HasPrefix(lines[0], `{"type":"header","data":{"format":"avito-pr-service","version":3`))
+	assert.Contains(t, lines[10], `"secret":"0123456789abcdef"`)
+	assert.True(t, strings.HasPrefix(lines[11], `{"type":"team_member_snapshot",`))
+	assert.NotContains(t, lines[12], `"lead_id"`)
+
+	got, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.NoError(t, Validate(got))
+}
+
+func TestRead_IgnoresUnknownFieldsAndBlankLines(t *testing.T) {
+	data := `{"type":"header","data":{"format":"avito-pr-service","version":1,"created_at":"2025-03-01T10:00:00Z"}}
+
+{"type":"team","data":{"team_name":"backend","color":"green"}}`
+
+	got, err := Read(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, []models.ArchiveTeam{{Name: "backend"}}, got.Teams)
+}
+
+func TestRead_Version1(t *testing.T) {
+	data := `{"type":"header","data":{"format":"avito-pr-service","version":1,"created_at":"2025-03-01T10:00:00Z"}}
+{"type":"team","data":{"team_name":"backend"}}`
+
+	got, err := Read(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Header.Version)
+	assert.Empty(t, got.Reassignments)
+	assert.Empty(t, got.Snapshots)
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "archive is empty"},
+		{"no header", `{"type":"team","data":{"team_name":"a"}}`, "line 1: archive must start with a header record"},
+		{"foreign format", `{"type":"header","data":{"format":"other","version":1}}`, `unknown archive format "other"`},
+		{"newer version", `{"type":"header","data":{"format":"avito-pr-service","version":4}}`, "archive version 4 is newer than supported version 3"},
+		{"zero version", `{"type":"header","data":{"format":"avito-pr-service"}}`, "invalid archive version 0"},
+		{
+			"unknown record",
+			"{\"type\":\"header\",\"data\":{\"format\":\"avito-pr-service\",\"version\":1}}\n{\"type\":\"comment\",\"data\":{}}",
+			`line 2: unknown record type "comment"`,
+		},
+		{
+			"broken line",
+			"{\"type\":\"header\",\"data\":{\"format\":\"avito-pr-service\",\"version\":1}}\n{\"type\":\"team\",",
+			"line 2: invalid JSON",
+		},
+		{
+			"duplicate header",
+			"{\"type\":\"header\",\"data\":{\"format\":\"avito-pr-service\",\"version\":1}}\n{\"type\":\"header\",\"data\":{\"format\":\"avito-pr-service\",\"version\":1}}",
+			"line 2: duplicate header record",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestValidate_ReferentialIntegrity(t *testing.T) {
+	a := testArchive()
+	a.Users = append(a.Users, models.User{UserID: "u3", Username: "Carol", TeamName: "frontend"}, a.Users[0])
+	a.PullRequests = append(a.PullRequests, models.ArchivePullRequest{ID: "pr-3", AuthorID: "ghost", Status: models.StatusOpen})
+	a.Reviewers = append(a.Reviewers, models.ArchiveReviewer{PRID: "pr-9", UserID: "u1"}, models.ArchiveReviewer{PRID: "pr-1", UserID: "ghost"},
+		models.ArchiveReviewer{PRID: "pr-2", UserID: "u2", Verdict: "LGTM"})
+	a.Reassignments = append(a.Reassignments, models.ArchiveReassignment{PRID: "pr-2", OldReviewerID: "u2", NewReviewerID: "ghost"},
+		models.ArchiveReassignment{PRID: "pr-2", OldReviewerID: "u2", NewReviewerID: "u1", Verdict: "LGTM"})
+	a.VCSIdentities = append(a.VCSIdentities, models.VCSIdentity{Provider: models.VCSProviderGitLab, Login: "bob", UserID: "ghost"})
+	a.Webhooks = append(a.Webhooks, a.Webhooks[0])
+	a.Snapshots = append(a.Snapshots, a.Snapshots[0], models.ArchiveMemberSnapshot{TeamName: "frontend", UserID: "u1"})
+	a.StaleSteps = append(a.StaleSteps, models.ArchiveStaleStep{PRID: "pr-9", Step: models.StaleStepRemind},
+		models.ArchiveStaleStep{PRID: "pr-1", Step: "nag"})
+
+	err := Validate(a)
+	require.Error(t, err)
+	for _, want := range []string{
+		`user "u3" references unknown team "frontend"`,
+		`duplicate user "u1"`,
+		`pull request "pr-3" references unknown author "ghost"`,
+		`reviewer "u1" references unknown pull request "pr-9"`,
+		`pull request "pr-1" references unknown reviewer "ghost"`,
+		`reviewer "u2" on pull request "pr-2" has invalid verdict "LGTM"`,
+		`reassignment on pull request "pr-2" references unknown reviewer "ghost"`,
+		`reassignment on pull request "pr-2" has invalid verdict "LGTM"`,
+		`gitlab identity "bob" references unknown user "ghost"`,
+		"duplicate webhook subscription 3",
+		`duplicate snapshot of user "u2" in team "backend"`,
+		`snapshot references unknown team "frontend"`,
+		`stale step references unknown pull request "pr-9"`,
+		`stale step on pull request "pr-1" has invalid step "nag"`,
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestValidate_LimitsReportedProblems(t *testing.T) {
+	a := testArchive()
+	for i := 0; i < 30; i++ {
+		a.Users = append(a.Users, models.User{UserID: "orphan", TeamName: "missing"})
+	}
+
+	err := Validate(a)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "and 10 more")
+}
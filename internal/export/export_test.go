@@ -0,0 +1,117 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		query, accept string
+		want          string
+		wantErr       bool
+	}{
+		{"", "", FormatJSON, false},
+		{"", "application/json", FormatJSON, false},
+		{"", "text/csv", FormatCSV, false},
+		{"", "text/csv;q=0, application/json", FormatJSON, false},
+		{"", "application/json;q=0.5, " + ContentTypeXLSX, FormatXLSX, false},
+		{"?format=csv", ContentTypeXLSX, FormatCSV, false},
+		{"?format=pdf", "", "", true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/stats/users"+tt.query, nil)
+		r.Header.Set("Accept", tt.accept)
+		got, err := Format(r)
+		if tt.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "query %q accept %q", tt.query, tt.accept)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, "users")
+	require.NoError(t, err)
+
+	ratio := 1.5
+	merged := time.Date(2025, 3, 1, 10, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+	require.NoError(t, w.WriteRow("user_id", "name", "count", "ratio", "active", "merged_at", "prs"))
+	require.NoError(t, w.WriteRow("u1", `Alice "A", Jr.`, 3, &ratio, true, &merged, []string{"pr-1", "pr-2"}))
+	require.NoError(t, w.WriteRow("u2", "multi\nline", 0, (*float64)(nil), false, (*time.Time)(nil), []string{}))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, "user_id,name,count,ratio,active,merged_at,prs\r\n"+
+		"u1,\"Alice \"\"A\"\", Jr.\",3,1.5000,true,2025-03-01T07:00:00Z,pr-1;pr-2\r\n"+
+		"u2,\"multi\r\nline\",0,,false,,\r\n", buf.String())
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, "user/stats")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow("user_id", "count", "active"))
+	require.NoError(t, w.WriteRow("<u1 & co>", 3, true))
+	require.NoError(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		parts[f.Name] = data
+	}
+	require.Contains(t, parts, "[Content_Types].xml")
+	require.Contains(t, parts, "_rels/.rels")
+	require.Contains(t, parts, "xl/_rels/workbook.xml.rels")
+	assert.Contains(t, string(parts["xl/workbook.xml"]), `name="user_stats"`)
+
+	var sheet xlsxSheet
+	require.NoError(t, xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet))
+	require.Len(t, sheet.Rows, 2)
+	row := sheet.Rows[1]
+	assert.Equal(t, 2, row.R)
+	require.Len(t, row.Cells, 3)
+	assert.Equal(t, "A2", row.Cells[0].Ref)
+	assert.Equal(t, "inlineStr", row.Cells[0].Type)
+	assert.Equal(t, "<u1 & co>", row.Cells[0].Inline)
+	assert.Equal(t, "3", row.Cells[1].Value)
+	assert.Equal(t, "b", row.Cells[2].Type)
+	assert.Equal(t, "1", row.Cells[2].Value)
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"avito-pr-service/config"
+	"avito-pr-service/internal/server/response"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+	"net/http"
+)
+
+type ConfigHandler struct {
+	snapshot map[string]any
+}
+
+// NewConfigHandler запоминает конфигурацию без секретов; ключи и формат
+// длительностей совпадают с YAML-файлом, чтобы ответ можно было сравнить с ним
+func NewConfigHandler(cfg config.Config) (*ConfigHandler, error) {
+	data, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	var snapshot map[string]any
+	if err := yaml.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return &ConfigHandler{snapshot: snapshot}, nil
+}
+
+func (h *ConfigHandler) Register(r chi.Router) {
+	r.Get("/config", h.GetConfig)
+}
+
+func (h *ConfigHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, map[string]any{"config": h.snapshot}, http.StatusOK)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"avito-pr-service/config"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfigHandler_GetConfig_Redacted(t *testing.T) {
+	cfg := config.Default()
+	cfg.DB.DSN = "postgres://app:s3cr3t@db:5432/pr_service"
+	cfg.GitLabWebhookToken = "gl-token"
+
+	h, err := NewConfigHandler(*cfg)
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	h.Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "s3cr3t")
+	assert.NotContains(t, w.Body.String(), "gl-token")
+
+	var resp struct {
+		Config struct {
+			Port               string `json:"port"`
+			GitLabWebhookToken string `json:"gitlab_webhook_token"`
+			ShutdownTimeout    string `json:"shutdown_timeout"`
+			DB                 struct {
+				DSN      string `json:"dsn"`
+				MaxConns int    `json:"max_conns"`
+			} `json:"db"`
+		} `json:"config"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "8080", resp.Config.Port)
+	assert.Equal(t, "[REDACTED]", resp.Config.GitLabWebhookToken)
+	assert.Equal(t, "5s", resp.Config.ShutdownTimeout)
+	assert.Equal(t, "postgres://app:xxxxx@db:5432/pr_service", resp.Config.DB.DSN)
+	assert.Equal(t, 10, resp.Config.DB.MaxConns)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"avito-pr-service/internal/export"
+	"avito-pr-service/internal/server/response"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// tableWriter создаёт файл только на первой строке: пока ничего не
+// отправлено, ошибку ещё можно вернуть обычным JSON-ответом
+type tableWriter struct {
+	w       http.ResponseWriter
+	format  string
+	name    string
+	columns []string
+	out     export.Writer
+}
+
+func (t *tableWriter) start() error {
+	if t.out != nil {
+		return nil
+	}
+	filename := fmt.Sprintf("%s-%s.%s", t.name, time.Now().UTC().Format("20060102"), t.format)
+	t.w.Header().Set("Content-Type", export.ContentType(t.format))
+	t.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	out, err := export.NewWriter(t.format, t.w, t.name)
+	if err != nil {
+		return err
+	}
+	t.out = out
+	columns := make([]any, len(t.columns))
+	for i, c := range t.columns {
+		columns[i] = c
+	}
+	return out.WriteRow(columns...)
+}
+
+func (t *tableWriter) row(values ...any) error {
+	if err := t.start(); err != nil {
+		return err
+	}
+	return t.out.WriteRow(values...)
+}
+
+// writeTable отдаёт таблицу в CSV или XLSX; fill вызывает row для каждой
+// строки по мере чтения из базы, так что выгрузка не копится в памяти
+func writeTable(w http.ResponseWriter, r *http.Request, log *slog.Logger, format, name string, columns []string, fill func(row func(values ...any) error) error) {
+	t := &tableWriter{w: w, format: format, name: name, columns: columns}
+	err := fill(t.row)
+	if err == nil {
+		err = t.start()
+	}
+	if t.out == nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if closeErr := t.out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// статус 200 уже отправлен: обрываем соединение, чтобы клиент
+		// не принял обрезанный файл за целый
+		log.ErrorContext(r.Context(), "export aborted", "format", format, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/usecase"
+	"avito-pr-service/internal/webhook"
+	"crypto/hmac"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	gitHubEventHeader     = "X-GitHub-Event"
+	gitHubSignatureHeader = "X-Hub-Signature-256"
+)
+
+type gitHubPullRequestPayload struct {
+	Action      string `json:"action"`
+	Number      int64  `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// gitHubReviewPayload — событие pull_request_review; номер PR здесь
+// только внутри pull_request
+type gitHubReviewPayload struct {
+	Action string `json:"action"`
+	Review struct {
+		State string `json:"state"`
+		User  struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"review"`
+	PullRequest struct {
+		Number int64  `json:"number"`
+		Title  string `json:"title"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+type GitHubHandler struct {
+	uc     usecase.IntegrationUsecase
+	secret []byte
+	log    *slog.Logger
+}
+
+func NewGitHubHandler(uc usecase.IntegrationUsecase, secret string, log *slog.Logger) *GitHubHandler {
+	return &GitHubHandler{
+		uc:     uc,
+		secret: []byte(secret),
+		log:    log.With("handler", "github"),
+	}
+}
+
+func (h *GitHubHandler) Register(r chi.Router) {
+	r.Post("/integrations/github", h.Receive)
+}
+
+func (h *GitHubHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxVCSPayloadSize))
+	if err != nil {
+		response.BadRequest(w, r, "failed to read body")
+		return
+	}
+
+	expected := webhook.Sign(string(h.secret), body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(gitHubSignatureHeader))) {
+		h.log.WarnContext(r.Context(), "invalid GitHub signature", "remote_addr", r.RemoteAddr)
+		response.Error(w, r, models.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	var event models.VCSPullRequestEvent
+	switch r.Header.Get(gitHubEventHeader) {
+	case "ping":
+		response.JSON(w, map[string]string{"result": "pong"}, http.StatusOK)
+		return
+	case "pull_request":
+		var payload gitHubPullRequestPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			response.BadRequest(w, r, "invalid JSON")
+			return
+		}
+		event = models.VCSPullRequestEvent{
+			Provider:    models.VCSProviderGitHub,
+			Action:      gitHubAction(payload),
+			Repository:  payload.Repository.FullName,
+			Number:      payload.Number,
+			Title:       payload.PullRequest.Title,
+			AuthorLogin: payload.PullRequest.User.Login,
+		}
+	case "pull_request_review":
+		var payload gitHubReviewPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			response.BadRequest(w, r, "invalid JSON")
+			return
+		}
+		event = gitHubReviewEvent(payload)
+	default:
+		response.JSON(w, models.VCSEventResult{Result: models.VCSResultIgnored}, http.StatusAccepted)
+		return
+	}
+
+	result, err := h.uc.HandlePullRequestEvent(r.Context(), event)
+	respondVCSResult(w, r, result, err)
+}
+
+func gitHubAction(p gitHubPullRequestPayload) string {
+	switch p.Action {
+	case "opened", "reopened":
+		// черновики ревьюим только после ready_for_review
+		if p.PullRequest.Draft {
+			return models.VCSActionIgnore
+		}
+		return models.VCSActionOpen
+	case "ready_for_review":
+		return models.VCSActionOpen
+	case "closed":
+		if p.PullRequest.Merged {
+			return models.VCSActionMerge
+		}
+		return models.VCSActionClose
+	default:
+		return models.VCSActionIgnore
+	}
+}
+
+// вердикт — только approve и request changes; комментарии и отзыв ревью
+// (dismissed) состояние не меняют
+func gitHubReviewEvent(p gitHubReviewPayload) models.VCSPullRequestEvent {
+	event := models.VCSPullRequestEvent{
+		Provider:      models.VCSProviderGitHub,
+		Action:        models.VCSActionIgnore,
+		Repository:    p.Repository.FullName,
+		Number:        p.PullRequest.Number,
+		Title:         p.PullRequest.Title,
+		AuthorLogin:   p.PullRequest.User.Login,
+		ReviewerLogin: p.Review.User.Login,
+	}
+	if p.Action != "submitted" {
+		return event
+	}
+	switch p.Review.State {
+	case "approved":
+		event.Action, event.Verdict = models.VCSActionReview, models.VerdictApproved
+	case "changes_requested":
+		event.Action, event.Verdict = models.VCSActionReview, models.VerdictChangesRequested
+	}
+	return event
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/webhook"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGitHubSecret = "gh-webhook-secret"
+
+type mockIntegrationUsecase struct{ mock.Mock }
+
+func (m *mockIntegrationUsecase) SetIdentity(ctx context.Context, identity models.VCSIdentity) error {
+	return m.Called(ctx, identity).Error(0)
+}
+
+func (m *mockIntegrationUsecase) HandlePullRequestEvent(ctx context.Context, event models.VCSPullRequestEvent) (models.VCSEventResult, error) {
+	args := m.Called(ctx, event)
+	return args.Get(0).(models.VCSEventResult), args.Error(1)
+}
+
+func loadFixture(t *testing.T, path ...string) []byte {
+	data, err := os.ReadFile(filepath.Join(append([]string{"testdata"}, path...)...))
+	require.NoError(t, err)
+	return data
+}
+
+func newGitHubRequest(event string, body []byte, secret string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/integrations/github", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", webhook.Sign(secret, body))
+	return req
+}
+
+func newGitHubRouter(uc *mockIntegrationUsecase) *chi.Mux {
+	r := chi.NewRouter()
+	NewGitHubHandler(uc, testGitHubSecret, testLogger()).Register(r)
+	return r
+}
+
+func TestGitHubHandler_InvalidSignature(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitHubRouter(uc)
+
+	body := loadFixture(t, "github", "pull_request_opened.json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitHubRequest("pull_request", body, "wrong-secret"))
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	uc.AssertNotCalled(t, "HandlePullRequestEvent", mock.Anything, mock.Anything)
+}
+
+func TestGitHubHandler_Ping(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitHubRouter(uc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitHubRequest("ping", loadFixture(t, "github", "ping.json"), testGitHubSecret))
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGitHubHandler_Opened(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitHubRouter(uc)
+
+	pr := models.PullRequest{ID: "github:acme/pr-service#42", Status: models.StatusOpen, AssignedReviewers: []string{"u2", "u3"}}
+	uc.On("HandlePullRequestEvent", mock.Anything, models.VCSPullRequestEvent{
+		Provider:    models.VCSProviderGitHub,
+		Action:      models.VCSActionOpen,
+		Repository:  "acme/pr-service",
+		Number:      42,
+		Title:       "Add search endpoint",
+		AuthorLogin: "octo-alice",
+	}).Return(models.VCSEventResult{Result: models.VCSResultCreated, PR: &pr}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitHubRequest("pull_request", loadFixture(t, "github", "pull_request_opened.json"), testGitHubSecret))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp models.VCSEventResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []string{"u2", "u3"}, resp.PR.AssignedReviewers)
+	uc.AssertExpectations(t)
+}
+
+func TestGitHubHandler_ActionMapping(t *testing.T) {
+	tests := []struct {
+		fixture string
+		action  string
+	}{
+		{"pull_request_opened_draft.json", models.VCSActionIgnore},
+		{"pull_request_ready_for_review.json", models.VCSActionOpen},
+		{"pull_request_reopened.json", models.VCSActionOpen},
+		{"pull_request_closed_merged.json", models.VCSActionMerge},
+		{"pull_request_closed.json", models.VCSActionClose},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			uc := new(mockIntegrationUsecase)
+			r := newGitHubRouter(uc)
+
+			uc.On("HandlePullRequestEvent", mock.Anything, mock.MatchedBy(func(e models.VCSPullRequestEvent) bool {
+				return e.Action == tt.action && e.PRID() == "github:acme/pr-service#42"
+			})).Return(models.VCSEventResult{Result: models.VCSResultIgnored}, nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newGitHubRequest("pull_request", loadFixture(t, "github", tt.fixture), testGitHubSecret))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGitHubHandler_ReviewSubmitted(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitHubRouter(uc)
+
+	uc.On("HandlePullRequestEvent", mock.Anything, models.VCSPullRequestEvent{
+		Provider:      models.VCSProviderGitHub,
+		Action:        models.VCSActionReview,
+		Repository:    "acme/pr-service",
+		Number:        42,
+		Title:         "Add search endpoint",
+		AuthorLogin:   "octo-alice",
+		ReviewerLogin: "octo-bob",
+		Verdict:       models.VerdictApproved,
+	}).Return(models.VCSEventResult{Result: models.VCSResultReviewed}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitHubRequest("pull_request_review", loadFixture(t, "github", "pull_request_review_approved.json"), testGitHubSecret))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestGitHubHandler_ReviewCommentIgnored(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitHubRouter(uc)
+
+	uc.On("HandlePullRequestEvent", mock.Anything, mock.MatchedBy(func(e models.VCSPullRequestEvent) bool {
+		return e.Action == models.VCSActionIgnore && e.Verdict == ""
+	})).Return(models.VCSEventResult{Result: models.VCSResultIgnored}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitHubRequest("pull_request_review", loadFixture(t, "github", "pull_request_review_commented.json"), testGitHubSecret))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestGitHubHandler_UnmappedAuthor(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitHubRouter(uc)
+
+	uc.On("HandlePullRequestEvent", mock.Anything, mock.Anything).Return(models.VCSEventResult{}, models.ErrIdentityNotFound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitHubRequest("pull_request", loadFixture(t, "github", "pull_request_opened.json"), testGitHubSecret))
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGitHubHandler_OtherEventIgnored(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitHubRouter(uc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitHubRequest("push", []byte(`{}`), testGitHubSecret))
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	uc.AssertNotCalled(t, "HandlePullRequestEvent", mock.Anything, mock.Anything)
+}
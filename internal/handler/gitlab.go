@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/usecase"
+	"crypto/subtle"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	gitLabEventHeader  = "X-Gitlab-Event"
+	gitLabTokenHeader  = "X-Gitlab-Token"
+	gitLabMergeRequest = "Merge Request Hook"
+	gitLabObjectKindMR = "merge_request"
+)
+
+type gitLabMergeRequestPayload struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID      int64  `json:"iid"`
+		Title    string `json:"title"`
+		Action   string `json:"action"`
+		Draft    bool   `json:"draft"`
+		AuthorID int64  `json:"author_id"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft *struct {
+			Previous bool `json:"previous"`
+			Current  bool `json:"current"`
+		} `json:"draft"`
+	} `json:"changes"`
+}
+
+type GitLabHandler struct {
+	uc    usecase.IntegrationUsecase
+	token []byte
+	log   *slog.Logger
+}
+
+func NewGitLabHandler(uc usecase.IntegrationUsecase, token string, log *slog.Logger) *GitLabHandler {
+	return &GitLabHandler{
+		uc:    uc,
+		token: []byte(token),
+		log:   log.With("handler", "gitlab"),
+	}
+}
+
+func (h *GitLabHandler) Register(r chi.Router) {
+	r.Post("/integrations/gitlab", h.Receive)
+}
+
+func (h *GitLabHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(gitLabTokenHeader)), h.token) != 1 {
+		h.log.WarnContext(r.Context(), "invalid GitLab token", "remote_addr", r.RemoteAddr)
+		response.Error(w, r, models.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	if r.Header.Get(gitLabEventHeader) != gitLabMergeRequest {
+		response.JSON(w, models.VCSEventResult{Result: models.VCSResultIgnored}, http.StatusAccepted)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxVCSPayloadSize))
+	if err != nil {
+		response.BadRequest(w, r, "failed to read body")
+		return
+	}
+
+	var payload gitLabMergeRequestPayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.ObjectKind != gitLabObjectKindMR {
+		response.BadRequest(w, r, "invalid merge request payload")
+		return
+	}
+
+	event := models.VCSPullRequestEvent{
+		Provider:   models.VCSProviderGitLab,
+		Action:     gitLabAction(payload),
+		Repository: payload.Project.PathWithNamespace,
+		Number:     payload.ObjectAttributes.IID,
+		Title:      payload.ObjectAttributes.Title,
+	}
+	// в MR Hook есть логин только того, кто совершил действие: автором он
+	// считается, лишь если его id совпадает с author_id MR
+	if payload.User.ID == payload.ObjectAttributes.AuthorID {
+		event.AuthorLogin = payload.User.Username
+	}
+	if event.Action == models.VCSActionReview {
+		// approved присылает одобривший, а не автор
+		event.AuthorLogin = ""
+		event.ReviewerLogin = payload.User.Username
+		event.Verdict = models.VerdictApproved
+	}
+
+	result, err := h.uc.HandlePullRequestEvent(r.Context(), event)
+	respondVCSResult(w, r, result, err)
+}
+
+// PR заводится на open, reopen и снятие draft. Если действие совершил не
+// автор, логина автора нет, и open только находит уже заведённый PR
+func gitLabAction(p gitLabMergeRequestPayload) string {
+	attrs := p.ObjectAttributes
+	switch attrs.Action {
+	case "open", "reopen":
+		if attrs.Draft {
+			return models.VCSActionIgnore
+		}
+		return models.VCSActionOpen
+	case "update":
+		if d := p.Changes.Draft; d != nil && d.Previous && !d.Current {
+			return models.VCSActionOpen
+		}
+		return models.VCSActionIgnore
+	case "merge":
+		return models.VCSActionMerge
+	case "close":
+		return models.VCSActionClose
+	case "approved":
+		return models.VCSActionReview
+	default:
+		return models.VCSActionIgnore
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testGitLabToken = "gl-webhook-token"
+
+func newGitLabRequest(event string, body []byte, token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/integrations/gitlab", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gitlab-Event", event)
+	req.Header.Set("X-Gitlab-Token", token)
+	return req
+}
+
+func newGitLabRouter(uc *mockIntegrationUsecase) *chi.Mux {
+	r := chi.NewRouter()
+	NewGitLabHandler(uc, testGitLabToken, testLogger()).Register(r)
+	return r
+}
+
+func TestGitLabHandler_InvalidToken(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitLabRouter(uc)
+
+	body := loadFixture(t, "gitlab", "merge_request_open.json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitLabRequest("Merge Request Hook", body, "wrong-token"))
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	uc.AssertNotCalled(t, "HandlePullRequestEvent", mock.Anything, mock.Anything)
+}
+
+func TestGitLabHandler_Open(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitLabRouter(uc)
+
+	pr := models.PullRequest{ID: "gitlab:acme/backend/pr-service#7", Status: models.StatusOpen, AssignedReviewers: []string{"u2", "u3"}}
+	uc.On("HandlePullRequestEvent", mock.Anything, models.VCSPullRequestEvent{
+		Provider:    models.VCSProviderGitLab,
+		Action:      models.VCSActionOpen,
+		Repository:  "acme/backend/pr-service",
+		Number:      7,
+		Title:       "Add search endpoint",
+		AuthorLogin: "gl-alice",
+	}).Return(models.VCSEventResult{Result: models.VCSResultCreated, PR: &pr}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitLabRequest("Merge Request Hook", loadFixture(t, "gitlab", "merge_request_open.json"), testGitLabToken))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp models.VCSEventResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []string{"u2", "u3"}, resp.PR.AssignedReviewers)
+	uc.AssertExpectations(t)
+}
+
+func TestGitLabHandler_ActionMapping(t *testing.T) {
+	tests := []struct {
+		fixture string
+		action  string
+	}{
+		{"merge_request_open_draft.json", models.VCSActionIgnore},
+		{"merge_request_update_ready.json", models.VCSActionOpen},
+		{"merge_request_update.json", models.VCSActionIgnore},
+		{"merge_request_reopen.json", models.VCSActionOpen},
+		{"merge_request_merge.json", models.VCSActionMerge},
+		{"merge_request_close.json", models.VCSActionClose},
+		{"merge_request_approved.json", models.VCSActionReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			uc := new(mockIntegrationUsecase)
+			r := newGitLabRouter(uc)
+
+			uc.On("HandlePullRequestEvent", mock.Anything, mock.MatchedBy(func(e models.VCSPullRequestEvent) bool {
+				return e.Action == tt.action && e.PRID() == "gitlab:acme/backend/pr-service#7"
+			})).Return(models.VCSEventResult{Result: models.VCSResultIgnored}, nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newGitLabRequest("Merge Request Hook", loadFixture(t, "gitlab", tt.fixture), testGitLabToken))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGitLabHandler_ActionByOtherUserHasNoAuthor(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitLabRouter(uc)
+
+	// reopen нажал не автор MR: его логин автором не считается
+	uc.On("HandlePullRequestEvent", mock.Anything, models.VCSPullRequestEvent{
+		Provider:   models.VCSProviderGitLab,
+		Action:     models.VCSActionOpen,
+		Repository: "acme/backend/pr-service",
+		Number:     7,
+		Title:      "Add search endpoint",
+	}).Return(models.VCSEventResult{Result: models.VCSResultIgnored}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitLabRequest("Merge Request Hook", loadFixture(t, "gitlab", "merge_request_reopen_by_other.json"), testGitLabToken))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestGitLabHandler_UnmappedAuthor(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitLabRouter(uc)
+
+	uc.On("HandlePullRequestEvent", mock.Anything, mock.Anything).Return(models.VCSEventResult{}, models.ErrIdentityNotFound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitLabRequest("Merge Request Hook", loadFixture(t, "gitlab", "merge_request_open.json"), testGitLabToken))
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGitLabHandler_OtherEventIgnored(t *testing.T) {
+	uc := new(mockIntegrationUsecase)
+	r := newGitLabRouter(uc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newGitLabRequest("Push Hook", []byte(`{"object_kind":"push"}`), testGitLabToken))
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	uc.AssertNotCalled(t, "HandlePullRequestEvent", mock.Anything, mock.Anything)
+}
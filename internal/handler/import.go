@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"avito-pr-service/internal/importer"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/usecase"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+const maxImportSize = 10 << 20
+
+type ImportHandler struct {
+	uc  usecase.TeamUsecase
+	log *slog.Logger
+}
+
+func NewImportHandler(uc usecase.TeamUsecase, log *slog.Logger) *ImportHandler {
+	return &ImportHandler{
+		uc:  uc,
+		log: log.With("handler", "import"),
+	}
+}
+
+func (h *ImportHandler) Register(r chi.Router) {
+	r.Post("/team/import", h.ImportTeams)
+}
+
+func (h *ImportHandler) ImportTeams(w http.ResponseWriter, r *http.Request) {
+	// ?format= важнее Content-Type: curl --data-binary по умолчанию шлёт form-urlencoded
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		var err error
+		format, err = importer.FormatFromContentType(r.Header.Get("Content-Type"))
+		if err != nil {
+			response.BadRequest(w, r, err.Error())
+			return
+		}
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			response.BadRequest(w, r, "dry_run must be a boolean")
+			return
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxImportSize+1))
+	if err != nil {
+		response.BadRequest(w, r, "failed to read body")
+		return
+	}
+	if len(data) > maxImportSize {
+		response.Fail(w, r, http.StatusRequestEntityTooLarge, models.ErrorValidation, "import file is too large", nil)
+		return
+	}
+
+	h.log.InfoContext(r.Context(), "ImportTeams request", "format", format, "dry_run", dryRun, "size", len(data))
+
+	result, err := h.uc.ImportTeams(r.Context(), models.ImportRequest{Format: format, Data: data, DryRun: dryRun})
+	var verr *models.ImportValidationError
+	if errors.As(err, &verr) {
+		response.Fail(w, r, http.StatusBadRequest, models.ErrorInvalidImport, verr.Error(), verr.Errors)
+		return
+	}
+	if err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]any{"import": result}, http.StatusOK)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newImportRouter(uc *mockTeamUsecase) chi.Router {
+	r := chi.NewRouter()
+	NewImportHandler(uc, testLogger()).Register(r)
+	return r
+}
+
+func TestImportHandler_ImportTeams_DryRunCSV(t *testing.T) {
+	uc := &mockTeamUsecase{teams: make(map[string]models.Team)}
+	body := "team_name,user_id,username\nbackend,u1,Alice\n"
+	uc.On("ImportTeams", mock.Anything, models.ImportRequest{
+		Format: models.ImportFormatCSV,
+		Data:   []byte(body),
+		DryRun: true,
+	}).Return(models.ImportResult{
+		DryRun: true,
+		Diff: models.ImportDiff{
+			TeamsCreated: []string{"backend"},
+			UsersCreated: []models.ImportUserChange{{UserID: "u1", TeamName: "backend"}},
+			UsersUpdated: []models.ImportUserChange{},
+		},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/team/import?dry_run=true", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	w := httptest.NewRecorder()
+	newImportRouter(uc).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Import models.ImportResult `json:"import"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Import.DryRun)
+	assert.Equal(t, []string{"backend"}, resp.Import.Diff.TeamsCreated)
+	uc.AssertExpectations(t)
+}
+
+func TestImportHandler_ImportTeams_FormatQueryOverridesContentType(t *testing.T) {
+	uc := &mockTeamUsecase{teams: make(map[string]models.Team)}
+	uc.On("ImportTeams", mock.Anything, mock.MatchedBy(func(req models.ImportRequest) bool {
+		return req.Format == models.ImportFormatYAML && !req.DryRun
+	})).Return(models.ImportResult{}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/team/import?format=yaml", bytes.NewBufferString("teams: []"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	newImportRouter(uc).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestImportHandler_ImportTeams_RowErrors(t *testing.T) {
+	uc := &mockTeamUsecase{teams: make(map[string]models.Team)}
+	uc.On("ImportTeams", mock.Anything, mock.Anything).Return(models.ImportResult{}, &models.ImportValidationError{
+		Errors: []models.ImportRowError{
+			{Location: "line 3", Field: "username", Message: "is required"},
+			{Location: "line 4", Field: "is_active", Message: `invalid boolean "maybe"`},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/team/import", bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	newImportRouter(uc).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var resp struct {
+		Error  map[string]string       `json:"error"`
+		Errors []models.ImportRowError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "INVALID_IMPORT", resp.Error["code"])
+	require.Len(t, resp.Errors, 2)
+	assert.Equal(t, "line 4", resp.Errors[1].Location)
+}
+
+func TestImportHandler_ImportTeams_BadRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+	}{
+		{"unsupported content type", "/team/import", "application/xml"},
+		{"missing content type", "/team/import", ""},
+		{"invalid dry_run", "/team/import?dry_run=maybe", "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &mockTeamUsecase{teams: make(map[string]models.Team)}
+			req := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewBufferString("{}"))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			newImportRouter(uc).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			uc.AssertNotCalled(t, "ImportTeams", mock.Anything, mock.Anything)
+		})
+	}
+}
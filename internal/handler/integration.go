@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/usecase"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+)
+
+const maxVCSPayloadSize = 10 << 20
+
+type IntegrationHandler struct {
+	uc  usecase.IntegrationUsecase
+	log *slog.Logger
+}
+
+func NewIntegrationHandler(uc usecase.IntegrationUsecase, log *slog.Logger) *IntegrationHandler {
+	return &IntegrationHandler{
+		uc:  uc,
+		log: log.With("handler", "integration"),
+	}
+}
+
+func (h *IntegrationHandler) Register(r chi.Router) {
+	r.Post("/integrations/identities/set", h.SetIdentity)
+}
+
+func (h *IntegrationHandler) SetIdentity(w http.ResponseWriter, r *http.Request) {
+	var req models.VCSIdentity
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, "invalid JSON")
+		return
+	}
+	if err := models.Validate(&req); err != nil {
+		response.ValidationError(w, r, err)
+		return
+	}
+
+	if err := h.uc.SetIdentity(r.Context(), req); err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"identity": req}, http.StatusOK)
+}
+
+// respondVCSResult отвечает VCS так, чтобы в истории доставок было видно,
+// что произошло с PR; доменные отказы (нет маппинга, PR уже смёржен) не 5xx,
+// чтобы VCS не ретраила их бесконечно
+func respondVCSResult(w http.ResponseWriter, r *http.Request, result models.VCSEventResult, err error) {
+	if err != nil {
+		var appErr models.AppError
+		if errors.As(err, &appErr) {
+			response.Error(w, r, err, http.StatusUnprocessableEntity)
+			return
+		}
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Result == models.VCSResultCreated {
+		status = http.StatusCreated
+	}
+	response.JSON(w, result, status)
+}
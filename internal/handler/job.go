@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/usecase"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type JobHandler struct {
+	uc  usecase.JobUsecase
+	log *slog.Logger
+}
+
+func NewJobHandler(uc usecase.JobUsecase, log *slog.Logger) *JobHandler {
+	return &JobHandler{
+		uc:  uc,
+		log: log.With("handler", "job"),
+	}
+}
+
+func (h *JobHandler) Register(r chi.Router) {
+	r.Get("/jobs/list", h.ListJobs)
+	r.Get("/jobs/get", h.GetJob)
+	r.Post("/jobs/retry", h.RetryJob)
+}
+
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.JobFilter{Status: q.Get("status"), Kind: q.Get("kind")}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			response.BadRequest(w, r, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = n
+	}
+
+	jobs, err := h.uc.ListJobs(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"jobs": jobs}, http.StatusOK)
+}
+
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, r, "id must be a positive integer")
+		return
+	}
+
+	job, err := h.uc.GetJob(r.Context(), id)
+	if err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"job": job}, http.StatusOK)
+}
+
+func (h *JobHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	var req models.RetryJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, "invalid JSON")
+		return
+	}
+	if err := models.Validate(&req); err != nil {
+		response.ValidationError(w, r, err)
+		return
+	}
+
+	if err := h.uc.RetryJob(r.Context(), req.ID); err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockJobUsecase struct{ mock.Mock }
+
+func (m *mockJobUsecase) ListJobs(ctx context.Context, filter models.JobFilter) ([]models.Job, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Job), args.Error(1)
+}
+
+func (m *mockJobUsecase) GetJob(ctx context.Context, id int64) (models.Job, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Job), args.Error(1)
+}
+
+func (m *mockJobUsecase) RetryJob(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func TestJobHandler_ListJobs(t *testing.T) {
+	uc := new(mockJobUsecase)
+	h := NewJobHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("ListJobs", mock.Anything, models.JobFilter{Status: models.JobFailed, Kind: "stale_prs.check", Limit: 10}).
+		Return([]models.Job{{ID: 3, Kind: "stale_prs.check", Status: models.JobFailed, LastError: "db is down"}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs/list?status=FAILED&kind=stale_prs.check&limit=10", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Jobs []models.Job `json:"jobs"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Jobs, 1)
+	require.Equal(t, "db is down", resp.Jobs[0].LastError)
+}
+
+func TestJobHandler_ListJobs_InvalidLimit(t *testing.T) {
+	uc := new(mockJobUsecase)
+	h := NewJobHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs/list?limit=-1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	uc.AssertNotCalled(t, "ListJobs", mock.Anything, mock.Anything)
+}
+
+func TestJobHandler_GetJob_NotFound(t *testing.T) {
+	uc := new(mockJobUsecase)
+	h := NewJobHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("GetJob", mock.Anything, int64(9)).Return(models.Job{}, models.ErrJobNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs/get?id=9", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestJobHandler_RetryJob(t *testing.T) {
+	uc := new(mockJobUsecase)
+	h := NewJobHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("RetryJob", mock.Anything, int64(5)).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/jobs/retry", bytes.NewBufferString(`{"id":5}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	uc.AssertExpectations(t)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"avito-pr-service/internal/export"
 	"avito-pr-service/internal/models"
 	"avito-pr-service/internal/server/response"
 	"avito-pr-service/internal/usecase"
@@ -10,6 +11,12 @@ import (
 	"net/http"
 )
 
+// порядок колонок выгрузок — часть контракта, новые колонки добавляются в конец
+var (
+	prCSVColumns        = []string{"pull_request_id", "pull_request_name", "author_id", "status", "assigned_reviewers", "created_at", "merged_at"}
+	userStatsCSVColumns = []string{"user_id", "username", "team_name", "assignment_count", "assigned_prs"}
+)
+
 type PRHandler struct {
 	uc  usecase.PRUsecase
 	log *slog.Logger
@@ -32,17 +39,17 @@ func (h *PRHandler) Register(r chi.Router) {
 func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 	var req models.CreatePRRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "invalid JSON")
+		response.BadRequest(w, r, "invalid JSON")
 		return
 	}
 	if err := models.Validate(&req); err != nil {
-		response.ValidationError(w, err)
+		response.ValidationError(w, r, err)
 		return
 	}
 
 	pr, err := h.uc.CreatePR(r.Context(), req)
 	if err != nil {
-		response.Error(w, err, http.StatusConflict)
+		response.Error(w, r, err, http.StatusConflict)
 		return
 	}
 
@@ -52,17 +59,17 @@ func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 	var req models.MergePRRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "invalid JSON")
+		response.BadRequest(w, r, "invalid JSON")
 		return
 	}
 	if err := models.Validate(&req); err != nil {
-		response.ValidationError(w, err)
+		response.ValidationError(w, r, err)
 		return
 	}
 
 	pr, err := h.uc.MergePR(r.Context(), req.PRID)
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 	response.JSON(w, map[string]any{"pr": pr}, http.StatusOK)
@@ -71,11 +78,11 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 	var req models.ReassignRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "invalid JSON")
+		response.BadRequest(w, r, "invalid JSON")
 		return
 	}
 	if err := models.Validate(&req); err != nil {
-		response.ValidationError(w, err)
+		response.ValidationError(w, r, err)
 		return
 	}
 
@@ -84,7 +91,7 @@ func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 		OldReviewerID: req.OldReviewerID,
 	})
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 	response.JSON(w, map[string]any{
@@ -96,21 +103,49 @@ func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 func (h *PRHandler) GetPRsByReviewer(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
 	if userID == "" {
-		response.BadRequest(w, "user_id is required")
+		response.BadRequest(w, r, "user_id is required")
+		return
+	}
+	format, err := export.Format(r)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	if format != export.FormatJSON {
+		writeTable(w, r, h.log, format, "reviews", prCSVColumns, func(row func(values ...any) error) error {
+			return h.uc.EachPRByReviewer(r.Context(), userID, func(pr models.PullRequest) error {
+				return row(pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.AssignedReviewers, pr.CreatedAt, pr.MergedAt)
+			})
+		})
 		return
 	}
+
 	prs, err := h.uc.GetPRsByReviewer(r.Context(), userID)
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 	response.JSON(w, map[string]any{"pull_requests": prs}, http.StatusOK)
 }
 
 func (h *PRHandler) GetUserStats(w http.ResponseWriter, r *http.Request) {
+	format, err := export.Format(r)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	if format != export.FormatJSON {
+		writeTable(w, r, h.log, format, "user-stats", userStatsCSVColumns, func(row func(values ...any) error) error {
+			return h.uc.EachUserStats(r.Context(), func(s models.UserStats) error {
+				return row(s.UserID, s.Username, s.TeamName, s.AssignmentCount, s.AssignedPRs)
+			})
+		})
+		return
+	}
+
 	stats, err := h.uc.GetUserStats(r.Context())
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 	response.JSON(w, map[string]any{"stats": stats}, http.StatusOK)
@@ -27,6 +27,10 @@ func (m *mockPRUsecase) MergePR(ctx context.Context, prID string) (models.PullRe
 	args := m.Called(ctx, prID)
 	return args.Get(0).(models.PullRequest), args.Error(1)
 }
+func (m *mockPRUsecase) RecordVerdict(ctx context.Context, prID, reviewerID, verdict string) (models.PullRequest, error) {
+	args := m.Called(ctx, prID, reviewerID, verdict)
+	return args.Get(0).(models.PullRequest), args.Error(1)
+}
 func (m *mockPRUsecase) ReassignReviewer(ctx context.Context, req models.ReassignRequest) (models.PullRequest, string, error) {
 	args := m.Called(ctx, req)
 	return args.Get(0).(models.PullRequest), args.String(1), args.Error(2)
@@ -46,6 +50,26 @@ func (m *mockPRUsecase) GetUserStats(ctx context.Context) ([]models.UserStats, e
 	return args.Get(0).([]models.UserStats), args.Error(1)
 }
 
+func (m *mockPRUsecase) EachPRByReviewer(ctx context.Context, userID string, fn func(models.PullRequest) error) error {
+	args := m.Called(ctx, userID)
+	for _, pr := range args.Get(0).([]models.PullRequest) {
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockPRUsecase) EachUserStats(ctx context.Context, fn func(models.UserStats) error) error {
+	args := m.Called(ctx)
+	for _, s := range args.Get(0).([]models.UserStats) {
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 func TestPRHandler_CreatePR_Success(t *testing.T) {
 	uc := new(mockPRUsecase)
 	h := NewPRHandler(uc, testLogger())
@@ -180,3 +204,26 @@ func TestPRHandler_GetPRsByReviewer_Success(t *testing.T) {
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 	require.Len(t, resp.PullRequests, 1)
 }
+
+func TestPRHandler_GetPRsByReviewer_CSV(t *testing.T) {
+	uc := new(mockPRUsecase)
+	h := NewPRHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	prs := []models.PullRequest{{
+		ID: "pr-1001", Name: "Add search, v2", AuthorID: "u1", Status: "OPEN",
+		AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &created,
+	}}
+	uc.On("EachPRByReviewer", mock.Anything, "u2").Return(prs, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/getReview?user_id=u2&format=csv", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "pull_request_id,pull_request_name,author_id,status,assigned_reviewers,created_at,merged_at\r\n"+
+		"pr-1001,\"Add search, v2\",u1,OPEN,u2;u3,2025-03-01T12:00:00Z,\r\n", w.Body.String())
+	uc.AssertNotCalled(t, "GetPRsByReviewer", mock.Anything, mock.Anything)
+}
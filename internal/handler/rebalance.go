@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/usecase"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+)
+
+type RebalanceHandler struct {
+	uc  usecase.RebalanceUsecase
+	log *slog.Logger
+}
+
+func NewRebalanceHandler(uc usecase.RebalanceUsecase, log *slog.Logger) *RebalanceHandler {
+	return &RebalanceHandler{
+		uc:  uc,
+		log: log.With("handler", "rebalance"),
+	}
+}
+
+func (h *RebalanceHandler) Register(r chi.Router) {
+	r.Post("/team/rebalance", h.RebalanceTeam)
+}
+
+func (h *RebalanceHandler) RebalanceTeam(w http.ResponseWriter, r *http.Request) {
+	var req models.RebalanceTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, "invalid JSON")
+		return
+	}
+	if err := models.Validate(&req); err != nil {
+		response.ValidationError(w, r, err)
+		return
+	}
+
+	resp, err := h.uc.RebalanceTeam(r.Context(), req)
+	if err != nil {
+		h.log.ErrorContext(r.Context(), "rebalance failed", "team", req.TeamName, "error", err)
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"rebalance": resp}, http.StatusOK)
+}
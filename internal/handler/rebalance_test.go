@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockRebalanceUsecase struct{ mock.Mock }
+
+func (m *mockRebalanceUsecase) RebalanceTeam(ctx context.Context, req models.RebalanceTeamRequest) (models.RebalanceTeamResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.RebalanceTeamResponse), args.Error(1)
+}
+
+func (m *mockRebalanceUsecase) RunScheduled(ctx context.Context, job models.Job) error {
+	return m.Called(ctx, job).Error(0)
+}
+
+func TestRebalanceHandler_RebalanceTeam(t *testing.T) {
+	uc := new(mockRebalanceUsecase)
+	r := chi.NewRouter()
+	NewRebalanceHandler(uc, testLogger()).Register(r)
+
+	uc.On("RebalanceTeam", mock.Anything, models.RebalanceTeamRequest{TeamName: "backend", DryRun: true, MaxMoves: 3}).
+		Return(models.RebalanceTeamResponse{
+			TeamName: "backend",
+			DryRun:   true,
+			Moves:    []models.ReviewerMove{{PRID: "pr-1", FromID: "u1", ToID: "u2"}},
+			Load:     []models.ReviewerLoad{{UserID: "u1", Before: 3, After: 2}, {UserID: "u2", Before: 0, After: 1}},
+		}, nil)
+	uc.On("RebalanceTeam", mock.Anything, models.RebalanceTeamRequest{TeamName: "unknown"}).
+		Return(models.RebalanceTeamResponse{}, models.ErrTeamNotFound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/team/rebalance", bytes.NewBufferString(`{"team_name":"backend","dry_run":true,"max_moves":3}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Rebalance models.RebalanceTeamResponse `json:"rebalance"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.True(t, resp.Rebalance.DryRun)
+	require.Len(t, resp.Rebalance.Moves, 1)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/team/rebalance", bytes.NewBufferString(`{"team_name":"unknown"}`)))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/team/rebalance", bytes.NewBufferString(`{"team_name":"backend","min_gap":1}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	uc.AssertNumberOfCalls(t, "RebalanceTeam", 2)
+}
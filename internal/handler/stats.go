@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"avito-pr-service/internal/export"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/usecase"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type StatsHandler struct {
+	uc  usecase.StatsUsecase
+	log *slog.Logger
+}
+
+func NewStatsHandler(uc usecase.StatsUsecase, log *slog.Logger) *StatsHandler {
+	return &StatsHandler{
+		uc:  uc,
+		log: log.With("handler", "stats"),
+	}
+}
+
+func (h *StatsHandler) Register(r chi.Router) {
+	r.Get("/stats/userLoad", h.GetUserLoad)
+	r.Get("/stats/teamLoad", h.GetTeamLoad)
+	r.Get("/stats/cycleTime", h.GetCycleTime)
+	r.Get("/stats/fairness", h.GetFairness)
+}
+
+// порядок колонок выгрузок — часть контракта, новые колонки добавляются в конец
+var (
+	userLoadCSVColumns = []string{
+		"user_id", "username", "team_name", "is_active",
+		"open_reviews", "merged_reviews", "authored_prs", "reassigned_in", "reassigned_out",
+	}
+	teamLoadCSVColumns = []string{
+		"team_name", "members",
+		"open_reviews", "merged_reviews", "authored_prs", "reassigned_in", "reassigned_out",
+	}
+	cycleTimeCSVColumns = []string{
+		"key", "week_start", "count", "median_seconds", "p90_seconds", "p99_seconds",
+		"first_review_count", "first_review_median_seconds", "first_review_p90_seconds", "first_review_p99_seconds",
+	}
+	fairnessCSVColumns = []string{
+		"team_name", "gini", "max_min_ratio",
+		"user_id", "username", "is_active", "assignments", "expected", "share", "expected_share", "load_ratio", "flag",
+	}
+)
+
+func (h *StatsHandler) GetUserLoad(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	format, err := export.Format(r)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	if format != export.FormatJSON {
+		writeTable(w, r, h.log, format, "user-load", userLoadCSVColumns, func(row func(values ...any) error) error {
+			return h.uc.EachUserLoad(r.Context(), filter, func(s models.UserReviewStats) error {
+				return row(s.UserID, s.Username, s.TeamName, s.IsActive,
+					s.OpenReviews, s.MergedReviews, s.AuthoredPRs, s.ReassignedIn, s.ReassignedOut)
+			})
+		})
+		return
+	}
+
+	stats, err := h.uc.GetUserLoad(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err, http.StatusBadRequest)
+		return
+	}
+	response.JSON(w, map[string]any{"filter": filter, "users": stats}, http.StatusOK)
+}
+
+// команд немного, поэтому выгрузка идёт из уже свёрнутого среза
+func (h *StatsHandler) GetTeamLoad(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	format, err := export.Format(r)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	stats, err := h.uc.GetTeamLoad(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if format != export.FormatJSON {
+		writeTable(w, r, h.log, format, "team-load", teamLoadCSVColumns, func(row func(values ...any) error) error {
+			for _, s := range stats {
+				err := row(s.TeamName, s.Members,
+					s.OpenReviews, s.MergedReviews, s.AuthoredPRs, s.ReassignedIn, s.ReassignedOut)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+	response.JSON(w, map[string]any{"filter": filter, "teams": stats}, http.StatusOK)
+}
+
+// time_to_first_review считается от создания PR до первого вердикта
+// ревьюера и только по PR, где вердикт есть
+func (h *StatsHandler) GetCycleTime(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.CycleTimeFilter{
+		TeamName: q.Get("team_name"),
+		GroupBy:  q.Get("group_by"),
+	}
+	if filter.GroupBy == "" {
+		filter.GroupBy = models.CycleTimeByTeam
+	}
+	var err error
+	if filter.From, filter.To, err = parseStatsWindow(q); err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	format, err := export.Format(r)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	groups, err := h.uc.GetCycleTime(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if format != export.FormatJSON {
+		// итог группы идёт строкой с пустой week_start перед её неделями
+		writeTable(w, r, h.log, format, "cycle-time-"+filter.GroupBy, cycleTimeCSVColumns, func(row func(values ...any) error) error {
+			for _, g := range groups {
+				t, f := g.TimeToMerge, g.TimeToFirstReview
+				if err := row(g.Key, nil, t.Count, t.MedianSeconds, t.P90Seconds, t.P99Seconds,
+					f.Count, f.MedianSeconds, f.P90Seconds, f.P99Seconds); err != nil {
+					return err
+				}
+				for _, wk := range g.Weeks {
+					t, f := wk.TimeToMerge, wk.TimeToFirstReview
+					if err := row(g.Key, wk.WeekStart, t.Count, t.MedianSeconds, t.P90Seconds, t.P99Seconds,
+						f.Count, f.MedianSeconds, f.P90Seconds, f.P99Seconds); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		return
+	}
+	response.JSON(w, map[string]any{"filter": filter, "groups": groups}, http.StatusOK)
+}
+
+func (h *StatsHandler) GetFairness(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStatsFilter(r.URL.Query())
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	format, err := export.Format(r)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	filter.Status = ""
+
+	teams, err := h.uc.GetFairness(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if format != export.FormatJSON {
+		writeTable(w, r, h.log, format, "fairness", fairnessCSVColumns, func(row func(values ...any) error) error {
+			for _, t := range teams {
+				for _, m := range t.Members {
+					err := row(t.TeamName, t.Gini, t.MaxMinRatio,
+						m.UserID, m.Username, m.IsActive, m.Assignments, m.Expected, m.Share, m.ExpectedShare, m.LoadRatio, m.Flag)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		return
+	}
+	response.JSON(w, map[string]any{"filter": filter, "teams": teams}, http.StatusOK)
+}
+
+func parseStatsFilter(q url.Values) (models.StatsFilter, error) {
+	filter := models.StatsFilter{
+		TeamName: q.Get("team_name"),
+		Status:   q.Get("status"),
+	}
+	var err error
+	filter.From, filter.To, err = parseStatsWindow(q)
+	return filter, err
+}
+
+func parseStatsWindow(q url.Values) (from, to *time.Time, err error) {
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := parseStatsTime(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s must be a date (2006-01-02) or RFC 3339 timestamp", p.name)
+		}
+		*p.dst = &t
+	}
+	return from, to, nil
+}
+
+// дата без времени — полночь UTC, так что to=2025-03-15 не включает 15-е
+func parseStatsTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockStatsUsecase struct {
+	cycleFilter models.CycleTimeFilter
+	groups      []models.CycleTimeGroup
+	fairness    []models.TeamFairness
+	users       []models.UserReviewStats
+	err         error
+	// ошибка на середине выгрузки, после первой строки
+	eachErr error
+}
+
+func (m *mockStatsUsecase) GetFairness(ctx context.Context, filter models.StatsFilter) ([]models.TeamFairness, error) {
+	return m.fairness, m.err
+}
+
+func (m *mockStatsUsecase) GetUserLoad(ctx context.Context, filter models.StatsFilter) ([]models.UserReviewStats, error) {
+	return nil, m.err
+}
+
+func (m *mockStatsUsecase) EachUserLoad(ctx context.Context, filter models.StatsFilter, fn func(models.UserReviewStats) error) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, u := range m.users {
+		if err := fn(u); err != nil {
+			return err
+		}
+		if m.eachErr != nil {
+			return m.eachErr
+		}
+	}
+	return nil
+}
+
+func (m *mockStatsUsecase) GetTeamLoad(ctx context.Context, filter models.StatsFilter) ([]models.TeamReviewStats, error) {
+	return nil, m.err
+}
+
+func (m *mockStatsUsecase) GetCycleTime(ctx context.Context, filter models.CycleTimeFilter) ([]models.CycleTimeGroup, error) {
+	m.cycleFilter = filter
+	return m.groups, m.err
+}
+
+func TestParseStatsFilter(t *testing.T) {
+	filter, err := parseStatsFilter(url.Values{
+		"from":      {"2025-03-01"},
+		"to":        {"2025-03-15T12:00:00+03:00"},
+		"team_name": {"backend"},
+		"status":    {"OPEN"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, filter.From)
+	require.NotNil(t, filter.To)
+	assert.True(t, filter.From.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, filter.To.Equal(time.Date(2025, 3, 15, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "backend", filter.TeamName)
+	assert.Equal(t, "OPEN", filter.Status)
+
+	filter, err = parseStatsFilter(url.Values{})
+	require.NoError(t, err)
+	assert.Nil(t, filter.From)
+	assert.Nil(t, filter.To)
+
+	_, err = parseStatsFilter(url.Values{"to": {"last week"}})
+	require.EqualError(t, err, "to must be a date (2006-01-02) or RFC 3339 timestamp")
+}
+
+func TestStatsHandler_GetCycleTime_DefaultsToTeam(t *testing.T) {
+	uc := &mockStatsUsecase{groups: []models.CycleTimeGroup{
+		{Key: "backend", TimeToMerge: models.CycleTime{Count: 1, MedianSeconds: 3600}, Weeks: []models.CycleTimeWeek{}},
+	}}
+	r := chi.NewRouter()
+	NewStatsHandler(uc, testLogger()).Register(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/cycleTime?from=2025-03-01", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.CycleTimeByTeam, uc.cycleFilter.GroupBy)
+	require.NotNil(t, uc.cycleFilter.From)
+
+	var resp struct {
+		Filter models.CycleTimeFilter  `json:"filter"`
+		Groups []models.CycleTimeGroup `json:"groups"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "team", resp.Filter.GroupBy)
+	require.Len(t, resp.Groups, 1)
+	assert.Equal(t, 3600.0, resp.Groups[0].TimeToMerge.MedianSeconds)
+}
+
+func TestStatsHandler_GetCycleTime_InvalidGroupBy(t *testing.T) {
+	uc := &mockStatsUsecase{err: models.AppError{Code: models.ErrorValidation, Message: "group_by must be team, author or reviewer"}}
+	r := chi.NewRouter()
+	NewStatsHandler(uc, testLogger()).Register(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/cycleTime?group_by=repo", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+}
+
+func TestStatsHandler_GetFairness_CSV(t *testing.T) {
+	ratio, maxMin := 1.6, 3.2
+	low := 0.5
+	uc := &mockStatsUsecase{fairness: []models.TeamFairness{{
+		TeamName: "back,end", Assignments: 21, Gini: 0.25, MaxMinRatio: &maxMin,
+		Members: []models.MemberFairness{
+			{UserID: "u1", Username: `Alice "A"`, IsActive: true, Assignments: 16, Expected: 10, Share: 0.7619, ExpectedShare: 0.5, LoadRatio: &ratio, Flag: models.FairnessOverloaded},
+			{UserID: "u2", Username: "Bob", IsActive: true, Assignments: 5, Expected: 10, Share: 0.2381, ExpectedShare: 0.5, LoadRatio: &low, Flag: models.FairnessUnderloaded},
+			{UserID: "u3", Username: "Carol"},
+		},
+	}}}
+	r := chi.NewRouter()
+	NewStatsHandler(uc, testLogger()).Register(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/fairness?format=csv", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, strings.Join([]string{
+		"team_name,gini,max_min_ratio,user_id,username,is_active,assignments,expected,share,expected_share,load_ratio,flag",
+		`"back,end",0.2500,3.2000,u1,"Alice ""A""",true,16,10.0000,0.7619,0.5000,1.6000,overloaded`,
+		`"back,end",0.2500,3.2000,u2,Bob,true,5,10.0000,0.2381,0.5000,0.5000,underloaded`,
+		`"back,end",0.2500,3.2000,u3,Carol,false,0,0.0000,0.0000,0.0000,,`,
+	}, "\r\n")+"\r\n", w.Body.String())
+	assert.Regexp(t, `^attachment; filename="fairness-\d{8}\.csv"$`, w.Header().Get("Content-Disposition"))
+}
+
+func TestStatsHandler_GetUserLoad_Export(t *testing.T) {
+	uc := &mockStatsUsecase{users: []models.UserReviewStats{
+		{UserID: "u1", Username: "alice", TeamName: "backend", IsActive: true, ReviewLoad: models.ReviewLoad{OpenReviews: 2, ReassignedIn: 1}},
+		{UserID: "u2", Username: "bob", TeamName: "backend"},
+	}}
+	r := chi.NewRouter()
+	NewStatsHandler(uc, testLogger()).Register(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/userLoad", nil)
+	req.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, strings.Join([]string{
+		"user_id,username,team_name,is_active,open_reviews,merged_reviews,authored_prs,reassigned_in,reassigned_out",
+		"u1,alice,backend,true,2,0,0,1,0",
+		"u2,bob,backend,false,0,0,0,0,0",
+	}, "\r\n")+"\r\n", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/stats/userLoad?format=xlsx", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "PK"))
+}
+
+func TestStatsHandler_GetUserLoad_ExportErrors(t *testing.T) {
+	r := chi.NewRouter()
+	uc := &mockStatsUsecase{}
+	NewStatsHandler(uc, testLogger()).Register(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/userLoad?format=pdf", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// до первой строки ошибка уходит обычным JSON
+	uc.err = models.ErrNotFound
+	req = httptest.NewRequest(http.MethodGet, "/stats/userLoad?format=csv&team_name=ghost", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	// после первой строки соединение обрывается
+	uc.err = nil
+	uc.users = []models.UserReviewStats{{UserID: "u1"}, {UserID: "u2"}}
+	uc.eachErr = errors.New("connection reset")
+	req = httptest.NewRequest(http.MethodGet, "/stats/userLoad?format=csv", nil)
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	})
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/stream"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StreamHandler struct {
+	broker    *stream.Broker
+	heartbeat time.Duration
+	log       *slog.Logger
+}
+
+func NewStreamHandler(broker *stream.Broker, heartbeat time.Duration, log *slog.Logger) *StreamHandler {
+	return &StreamHandler{
+		broker:    broker,
+		heartbeat: heartbeat,
+		log:       log.With("handler", "stream"),
+	}
+}
+
+func (h *StreamHandler) Register(r chi.Router) {
+	r.Get("/events/stream", h.Stream)
+}
+
+// Stream отдаёт события в формате Server-Sent Events. С Last-Event-ID
+// сначала дочитываются пропущенные события из журнала, затем идут новые;
+// без него — только новые. Доставка "at least once": клиент отбрасывает
+// повторы по id
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := stream.Filter{
+		UserID:   query.Get("user_id"),
+		TeamName: query.Get("team_name"),
+		PRID:     query.Get("pull_request_id"),
+	}
+	if raw := query.Get("event_types"); raw != "" {
+		for _, typ := range strings.Split(raw, ",") {
+			if !slices.Contains(models.EventTypes, typ) {
+				response.BadRequest(w, r, fmt.Sprintf("unknown event type %q", typ))
+				return
+			}
+			filter.Types = append(filter.Types, typ)
+		}
+	}
+
+	// EventSource ставит Last-Event-ID сам только при переподключении,
+	// поэтому первое подключение может передать его параметром
+	rawLast := r.Header.Get("Last-Event-ID")
+	if rawLast == "" {
+		rawLast = query.Get("last_event_id")
+	}
+	var lastID int64
+	resume := rawLast != ""
+	if resume {
+		var err error
+		lastID, err = strconv.ParseInt(rawLast, 10, 64)
+		if err != nil || lastID < 0 {
+			response.BadRequest(w, r, "Last-Event-ID must be a non-negative integer")
+			return
+		}
+	}
+
+	sub, err := h.broker.Subscribe(filter)
+	if err != nil {
+		response.Fail(w, r, http.StatusServiceUnavailable, models.ErrorUnavailable, err.Error(), nil)
+		return
+	}
+	defer h.broker.Unsubscribe(sub)
+
+	// проверка до заголовков: при 410 EventSource не переподключается,
+	// и клиент перечитывает состояние через API
+	if resume {
+		if err := h.broker.CheckResume(r.Context(), lastID); err != nil {
+			if errors.Is(err, stream.ErrResumeExpired) {
+				response.Fail(w, r, http.StatusGone, models.ErrorResumeExpired, err.Error(), nil)
+				return
+			}
+			response.Error(w, r, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		h.log.ErrorContext(r.Context(), "event stream is not supported by the connection", "error", err)
+		return
+	}
+
+	ctx := r.Context()
+	send := func(event models.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if resume {
+		if err := h.broker.Replay(ctx, lastID, sub.Cursor, filter, send); err != nil {
+			h.log.WarnContext(ctx, "event stream replay failed", "last_event_id", lastID, "error", err)
+			return
+		}
+		for _, event := range sub.Pending {
+			if event.ID <= lastID {
+				continue
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Done():
+			return
+		case event := <-sub.Events():
+			if err := send(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			// комментарий держит соединение открытым через прокси
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
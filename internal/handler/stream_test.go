@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/stream"
+	"bufio"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryEventLog struct {
+	mu      sync.Mutex
+	events  []models.Event
+	deleted int64
+}
+
+func (l *memoryEventLog) add(events ...models.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, events...)
+}
+
+func (l *memoryEventLog) ListEvents(_ context.Context, afterID int64, limit int) ([]models.Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := []models.Event{}
+	for _, ev := range l.events {
+		if ev.ID > afterID && len(out) < limit {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (l *memoryEventLog) GetEvents(context.Context, []int64) ([]models.Event, error) {
+	return nil, nil
+}
+
+func (l *memoryEventLog) LastEventID(context.Context) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) == 0 {
+		return 0, nil
+	}
+	return l.events[len(l.events)-1].ID, nil
+}
+
+func (l *memoryEventLog) DeletedUpTo(context.Context) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.deleted, nil
+}
+
+// readFrame читает одно SSE-сообщение до пустой строки
+func readFrame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var frame strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			return frame.String()
+		}
+		frame.WriteString(line)
+	}
+}
+
+func openStream(t *testing.T, url, lastEventID string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestStreamHandler_ResumeThenLive(t *testing.T) {
+	log := &memoryEventLog{}
+	log.add(
+		models.Event{ID: 1, Type: models.EventPRCreated, PRID: "pr-1", TeamName: "backend"},
+		models.Event{ID: 2, Type: models.EventPRCreated, PRID: "pr-2", TeamName: "frontend"},
+		models.Event{ID: 3, Type: models.EventReviewerAssigned, PRID: "pr-1", TeamName: "backend"},
+	)
+	broker := stream.NewBroker(log, testLogger())
+	_, err := broker.Poll(context.Background())
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	NewStreamHandler(broker, time.Hour, testLogger()).Register(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp := openStream(t, srv.URL+"/events/stream?team_name=backend", "1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body := bufio.NewReader(resp.Body)
+
+	frame := readFrame(t, body)
+	require.Contains(t, frame, "id: 3\nevent: reviewer.assigned\n")
+	require.Contains(t, frame, `"pull_request_id":"pr-1"`)
+
+	log.add(
+		models.Event{ID: 4, Type: models.EventPRMerged, PRID: "pr-2", TeamName: "frontend"},
+		models.Event{ID: 5, Type: models.EventPRMerged, PRID: "pr-1", TeamName: "backend"},
+	)
+	_, err = broker.Poll(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, readFrame(t, body), "id: 5\nevent: pr.merged\n")
+
+	broker.Close()
+	_, err = io.ReadAll(body)
+	require.NoError(t, err, "stream must end cleanly when the broker closes")
+}
+
+func TestStreamHandler_ResumePastRetention(t *testing.T) {
+	log := &memoryEventLog{deleted: 2}
+	log.add(models.Event{ID: 3, Type: models.EventPRCreated, PRID: "pr-3", TeamName: "backend"})
+	broker := stream.NewBroker(log, testLogger())
+	_, err := broker.Poll(context.Background())
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	NewStreamHandler(broker, time.Hour, testLogger()).Register(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	defer broker.Close()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/stream?last_event_id=1", nil))
+	require.Equal(t, http.StatusGone, w.Code)
+	require.Contains(t, w.Body.String(), string(models.ErrorResumeExpired))
+
+	// с границы очистки продолжить можно: после неё ничего не удалено
+	resp := openStream(t, srv.URL+"/events/stream", "2")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, readFrame(t, bufio.NewReader(resp.Body)), "id: 3\n")
+}
+
+func TestStreamHandler_Heartbeat(t *testing.T) {
+	broker := stream.NewBroker(&memoryEventLog{}, testLogger())
+	_, err := broker.Poll(context.Background())
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	NewStreamHandler(broker, 10*time.Millisecond, testLogger()).Register(r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	// иначе srv.Close ждёт открытый поток
+	defer broker.Close()
+
+	resp := openStream(t, srv.URL+"/events/stream", "")
+	require.Equal(t, ": heartbeat\n", readFrame(t, bufio.NewReader(resp.Body)))
+}
+
+func TestStreamHandler_Errors(t *testing.T) {
+	broker := stream.NewBroker(&memoryEventLog{}, testLogger())
+	r := chi.NewRouter()
+	NewStreamHandler(broker, time.Hour, testLogger()).Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/stream?event_types=pr.created,pr.closed", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/stream?last_event_id=abc", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// брокер ещё не прочитал конец журнала
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/stream", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Contains(t, w.Body.String(), string(models.ErrorUnavailable))
+
+	_, err := broker.Poll(context.Background())
+	require.NoError(t, err)
+	broker.Close()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/stream", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
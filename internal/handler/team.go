@@ -9,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"log/slog"
 	"net/http"
+	"strconv"
 )
 
 type TeamHandler struct {
@@ -26,56 +27,58 @@ func (h *TeamHandler) Register(r chi.Router) {
 	r.Post("/team/add", h.AddTeam)
 	r.Get("/team/get", h.GetTeam)
 	r.Post("/team/deactivate", h.DeactivateTeam)
+	r.Post("/team/activate", h.ActivateTeam)
+	r.Get("/team/deactivateStatus", h.DeactivateStatus)
 }
 
 func (h *TeamHandler) AddTeam(w http.ResponseWriter, r *http.Request) {
-	h.log.Info("AddTeam request", "remote_addr", r.RemoteAddr, "user_agent", r.UserAgent())
+	h.log.InfoContext(r.Context(), "AddTeam request", "remote_addr", r.RemoteAddr, "user_agent", r.UserAgent())
 
 	var team models.Team
 	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
-		h.log.Warn("invalid JSON", "error", err)
-		response.BadRequest(w, "invalid JSON")
+		h.log.WarnContext(r.Context(), "invalid JSON", "error", err)
+		response.BadRequest(w, r, "invalid JSON")
 		return
 	}
 
 	if err := models.Validate(&team); err != nil {
-		h.log.Warn("validation failed", "error", err, "team", team)
-		response.ValidationError(w, err)
+		h.log.WarnContext(r.Context(), "validation failed", "error", err, "team", team)
+		response.ValidationError(w, r, err)
 		return
 	}
 
 	if err := h.uc.AddTeam(r.Context(), team); err != nil {
-		h.log.Error("usecase error", "error", err, "team_name", team.Name)
+		h.log.ErrorContext(r.Context(), "usecase error", "error", err, "team_name", team.Name)
 		if errors.Is(err, models.ErrUserInAnotherTeam) {
-			response.Error(w, models.ErrUserInAnotherTeam, http.StatusBadRequest)
+			response.Error(w, r, models.ErrUserInAnotherTeam, http.StatusBadRequest)
 			return
 		} else if errors.Is(err, models.ErrEmptyTeam) {
-			response.Error(w, models.ErrEmptyTeam, http.StatusBadRequest)
+			response.Error(w, r, models.ErrEmptyTeam, http.StatusBadRequest)
 			return
 		}
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
 	createdTeam, err := h.uc.GetTeam(r.Context(), team.Name)
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 	}
 
-	h.log.Info("team created", "team_name", team.Name, "members_count", len(team.Members))
+	h.log.InfoContext(r.Context(), "team created", "team_name", team.Name, "members_count", len(team.Members))
 	response.JSON(w, createdTeam, http.StatusCreated)
 }
 
 func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("team_name")
 	if name == "" {
-		response.BadRequest(w, "team_name required")
+		response.BadRequest(w, r, "team_name required")
 		return
 	}
 
 	team, err := h.uc.GetTeam(r.Context(), name)
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -85,19 +88,73 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 func (h *TeamHandler) DeactivateTeam(w http.ResponseWriter, r *http.Request) {
 	var req models.DeactivateTeamRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "invalid JSON")
+		response.BadRequest(w, r, "invalid JSON")
 		return
 	}
 	if err := models.Validate(&req); err != nil {
-		response.ValidationError(w, err)
+		response.ValidationError(w, r, err)
+		return
+	}
+
+	async := false
+	if raw := r.URL.Query().Get("async"); raw != "" {
+		var err error
+		if async, err = strconv.ParseBool(raw); err != nil {
+			response.BadRequest(w, r, "async must be true or false")
+			return
+		}
+	}
+
+	if async {
+		d, err := h.uc.StartDeactivation(r.Context(), req)
+		if err != nil {
+			response.Error(w, r, err, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Location", "/team/deactivateStatus?id="+strconv.FormatInt(d.ID, 10))
+		response.JSON(w, map[string]any{"operation": d}, http.StatusAccepted)
 		return
 	}
 
 	resp, err := h.uc.DeactivateTeam(r.Context(), req)
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
 	response.JSON(w, map[string]any{"deactivate": resp}, http.StatusOK)
 }
+
+func (h *TeamHandler) ActivateTeam(w http.ResponseWriter, r *http.Request) {
+	var req models.ActivateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, "invalid JSON")
+		return
+	}
+	if err := models.Validate(&req); err != nil {
+		response.ValidationError(w, r, err)
+		return
+	}
+
+	resp, err := h.uc.ActivateTeam(r.Context(), req)
+	if err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"activate": resp}, http.StatusOK)
+}
+
+func (h *TeamHandler) DeactivateStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, r, "id must be a positive integer")
+		return
+	}
+
+	d, err := h.uc.GetDeactivation(r.Context(), id)
+	if err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"operation": d}, http.StatusOK)
+}
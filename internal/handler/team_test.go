@@ -48,6 +48,15 @@ func (m *mockTeamUsecase) GetTeam(ctx context.Context, name string) (models.Team
 	}
 	return team, nil
 }
+
+func (m *mockTeamUsecase) ListTeams(ctx context.Context) ([]models.Team, error) {
+	teams := make([]models.Team, 0, len(m.teams))
+	for _, t := range m.teams {
+		teams = append(teams, t)
+	}
+	return teams, nil
+}
+
 func (m *mockTeamUsecase) DeactivateTeam(ctx context.Context, req models.DeactivateTeamRequest) (models.DeactivateTeamResponse, error) {
 	args := m.Called(ctx, req)
 
@@ -77,6 +86,30 @@ func (m *mockTeamUsecase) DeactivateTeam(ctx context.Context, req models.Deactiv
 	return resp, nil
 }
 
+func (m *mockTeamUsecase) ActivateTeam(ctx context.Context, req models.ActivateTeamRequest) (models.ActivateTeamResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.ActivateTeamResponse), args.Error(1)
+}
+
+func (m *mockTeamUsecase) StartDeactivation(ctx context.Context, req models.DeactivateTeamRequest) (models.TeamDeactivation, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.TeamDeactivation), args.Error(1)
+}
+
+func (m *mockTeamUsecase) GetDeactivation(ctx context.Context, id int64) (models.TeamDeactivation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.TeamDeactivation), args.Error(1)
+}
+
+func (m *mockTeamUsecase) RunDeactivation(ctx context.Context, job models.Job) error {
+	return m.Called(ctx, job).Error(0)
+}
+
+func (m *mockTeamUsecase) ImportTeams(ctx context.Context, req models.ImportRequest) (models.ImportResult, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.ImportResult), args.Error(1)
+}
+
 func testLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(io.Discard, nil))
 }
@@ -183,3 +216,123 @@ func TestTeamHandler_DeactivateTeam_Success(t *testing.T) {
 
 	uc.AssertExpectations(t)
 }
+
+func TestTeamHandler_DeactivateTeam_AsyncInProgress(t *testing.T) {
+	uc := &mockTeamUsecase{teams: map[string]models.Team{"backend": {Name: "backend"}}}
+	h := NewTeamHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("DeactivateTeam", mock.Anything, models.DeactivateTeamRequest{TeamName: "backend"}).
+		Return(models.DeactivateTeamResponse{}, models.ErrTeamDeactivating)
+
+	req := httptest.NewRequest(http.MethodPost, "/team/deactivate", bytes.NewBufferString(`{"team_name":"backend"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Contains(t, w.Body.String(), string(models.ErrorDeactivating))
+}
+
+func TestTeamHandler_DeactivateTeam_Async(t *testing.T) {
+	uc := &mockTeamUsecase{teams: make(map[string]models.Team)}
+	h := NewTeamHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("StartDeactivation", mock.Anything, models.DeactivateTeamRequest{TeamName: "backend"}).
+		Return(models.TeamDeactivation{ID: 7, TeamName: "backend", Status: models.DeactivationPending}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/team/deactivate?async=true", bytes.NewBufferString(`{"team_name":"backend"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Equal(t, "/team/deactivateStatus?id=7", w.Header().Get("Location"))
+	var resp struct {
+		Operation models.TeamDeactivation `json:"operation"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, int64(7), resp.Operation.ID)
+	require.Equal(t, models.DeactivationPending, resp.Operation.Status)
+	uc.AssertNotCalled(t, "DeactivateTeam", mock.Anything, mock.Anything)
+}
+
+func TestTeamHandler_DeactivateTeam_InvalidAsync(t *testing.T) {
+	uc := &mockTeamUsecase{teams: make(map[string]models.Team)}
+	h := NewTeamHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/team/deactivate?async=maybe", bytes.NewBufferString(`{"team_name":"backend"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTeamHandler_DeactivateStatus(t *testing.T) {
+	uc := &mockTeamUsecase{teams: make(map[string]models.Team)}
+	h := NewTeamHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("GetDeactivation", mock.Anything, int64(7)).Return(models.TeamDeactivation{
+		ID: 7, TeamName: "backend", Status: models.DeactivationDone,
+		Progress: models.DeactivationProgress{TotalPRs: 3, ProcessedPRs: 3, Reassigned: 4, Stuck: 1},
+		Result:   &models.DeactivateTeamResponse{DeactivatedUsers: 5, ReassignedPRs: 4},
+	}, nil)
+	uc.On("GetDeactivation", mock.Anything, int64(8)).Return(models.TeamDeactivation{}, models.ErrDeactivationNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/team/deactivateStatus?id=7", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Operation models.TeamDeactivation `json:"operation"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 3, resp.Operation.Progress.ProcessedPRs)
+	require.NotNil(t, resp.Operation.Result)
+	require.Equal(t, 5, resp.Operation.Result.DeactivatedUsers)
+
+	req = httptest.NewRequest(http.MethodGet, "/team/deactivateStatus?id=8", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTeamHandler_ActivateTeam(t *testing.T) {
+	uc := &mockTeamUsecase{teams: make(map[string]models.Team)}
+	h := NewTeamHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("ActivateTeam", mock.Anything, models.ActivateTeamRequest{TeamName: "backend", Rebalance: true}).
+		Return(models.ActivateTeamResponse{
+			ActivatedUsers: []string{"u1", "u2"},
+			Moves:          []models.ReviewerMove{{PRID: "pr-1", FromID: "u3", ToID: "u1"}},
+		}, nil)
+	uc.On("ActivateTeam", mock.Anything, models.ActivateTeamRequest{TeamName: "frontend"}).
+		Return(models.ActivateTeamResponse{}, models.ErrTeamNotDeactivated)
+
+	req := httptest.NewRequest(http.MethodPost, "/team/activate", bytes.NewBufferString(`{"team_name":"backend","rebalance":true}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Activate models.ActivateTeamResponse `json:"activate"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []string{"u1", "u2"}, resp.Activate.ActivatedUsers)
+	require.Len(t, resp.Activate.Moves, 1)
+
+	req = httptest.NewRequest(http.MethodPost, "/team/activate", bytes.NewBufferString(`{"team_name":"frontend"}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Contains(t, w.Body.String(), "TEAM_NOT_DEACTIVATED")
+}
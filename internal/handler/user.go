@@ -27,27 +27,27 @@ func (h *UserHandler) Register(r chi.Router) {
 }
 
 func (h *UserHandler) SetActive(w http.ResponseWriter, r *http.Request) {
-	h.log.Info("SetActive request")
+	h.log.InfoContext(r.Context(), "SetActive request")
 
 	var req models.SetUserActiveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "invalid JSON")
+		response.BadRequest(w, r, "invalid JSON")
 		return
 	}
 
 	if err := models.Validate(&req); err != nil {
-		response.ValidationError(w, err)
+		response.ValidationError(w, r, err)
 		return
 	}
 
 	if err := h.uc.SetActive(r.Context(), req); err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
 	user, err := h.uc.GetUser(r.Context(), req.UserID)
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
+		response.Error(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
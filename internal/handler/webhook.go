@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"avito-pr-service/internal/usecase"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type WebhookHandler struct {
+	uc  usecase.WebhookUsecase
+	log *slog.Logger
+}
+
+func NewWebhookHandler(uc usecase.WebhookUsecase, log *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		uc:  uc,
+		log: log.With("handler", "webhook"),
+	}
+}
+
+func (h *WebhookHandler) Register(r chi.Router) {
+	r.Post("/webhooks/add", h.CreateSubscription)
+	r.Get("/webhooks/list", h.ListSubscriptions)
+	r.Post("/webhooks/delete", h.DeleteSubscription)
+	r.Get("/webhooks/deadLetters", h.ListDeadLetters)
+	r.Post("/webhooks/retry", h.RetryDelivery)
+}
+
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, "invalid JSON")
+		return
+	}
+	if err := models.Validate(&req); err != nil {
+		response.ValidationError(w, r, err)
+		return
+	}
+
+	sub, err := h.uc.CreateSubscription(r.Context(), req)
+	if err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"webhook": sub}, http.StatusCreated)
+}
+
+func (h *WebhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.uc.ListSubscriptions(r.Context())
+	if err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"webhooks": subs}, http.StatusOK)
+}
+
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	var req models.DeleteWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, "invalid JSON")
+		return
+	}
+	if err := models.Validate(&req); err != nil {
+		response.ValidationError(w, r, err)
+		return
+	}
+
+	if err := h.uc.DeleteSubscription(r.Context(), req.ID); err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			response.BadRequest(w, r, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.uc.ListDeadLetters(r.Context(), limit)
+	if err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]any{"deliveries": deliveries}, http.StatusOK)
+}
+
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	var req models.RetryDeliveryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, "invalid JSON")
+		return
+	}
+	if err := models.Validate(&req); err != nil {
+		response.ValidationError(w, r, err)
+		return
+	}
+
+	if err := h.uc.RetryDelivery(r.Context(), req.DeliveryID); err != nil {
+		response.Error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"avito-pr-service/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockWebhookUsecase struct{ mock.Mock }
+
+func (m *mockWebhookUsecase) CreateSubscription(ctx context.Context, req models.CreateWebhookRequest) (models.WebhookSubscription, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookUsecase) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookUsecase) DeleteSubscription(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockWebhookUsecase) ListDeadLetters(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookUsecase) RetryDelivery(ctx context.Context, deliveryID int64) error {
+	return m.Called(ctx, deliveryID).Error(0)
+}
+
+func TestWebhookHandler_CreateSubscription_Success(t *testing.T) {
+	uc := new(mockWebhookUsecase)
+	h := NewWebhookHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("CreateSubscription", mock.Anything, mock.Anything).Return(models.WebhookSubscription{
+		ID: 1, URL: "https://hooks.example.com/pr", Secret: "0123456789abcdef",
+		EventTypes: []string{models.EventReviewerAssigned}, IsActive: true,
+	}, nil)
+
+	body := `{"url":"https://hooks.example.com/pr","secret":"0123456789abcdef","event_types":["reviewer.assigned"]}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/add", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotContains(t, w.Body.String(), "0123456789abcdef")
+
+	var resp struct {
+		Webhook models.WebhookSubscription `json:"webhook"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, int64(1), resp.Webhook.ID)
+}
+
+func TestWebhookHandler_CreateSubscription_UnknownEventType(t *testing.T) {
+	uc := new(mockWebhookUsecase)
+	h := NewWebhookHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	body := `{"url":"https://hooks.example.com/pr","secret":"0123456789abcdef","event_types":["pr.deleted"]}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/add", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	uc.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything)
+}
+
+func TestWebhookHandler_ListDeadLetters_InvalidLimit(t *testing.T) {
+	uc := new(mockWebhookUsecase)
+	h := NewWebhookHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/webhooks/deadLetters?limit=abc", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWebhookHandler_RetryDelivery_NotFound(t *testing.T) {
+	uc := new(mockWebhookUsecase)
+	h := NewWebhookHandler(uc, testLogger())
+	r := chi.NewRouter()
+	h.Register(r)
+
+	uc.On("RetryDelivery", mock.Anything, int64(5)).Return(models.ErrDeliveryNotFound)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/retry", bytes.NewBufferString(`{"delivery_id":5}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
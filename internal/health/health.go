@@ -0,0 +1,106 @@
+package health
+
+import (
+	"avito-pr-service/internal/server/response"
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+	statusDraining    = "shutting_down"
+)
+
+type Database interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (version int64, dirty bool, err error)
+}
+
+type Checker struct {
+	db       Database
+	expected int64
+	log      *slog.Logger
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewChecker(db Database, expectedVersion int64, log *slog.Logger) *Checker {
+	return &Checker{
+		db:       db,
+		expected: expectedVersion,
+		log:      log.With("component", "health"),
+		timeout:  2 * time.Second,
+	}
+}
+
+func (c *Checker) Register(r chi.Router) {
+	r.Get("/healthz", c.Live)
+	r.Get("/readyz", c.Ready)
+}
+
+// после SetDraining /readyz отвечает 503, чтобы балансировщик успел снять под
+// до закрытия listener-а
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// liveness не ходит в БД: при недоступной базе рестарт процесса не поможет,
+// а перезапуск всех подов разом только усугубит ситуацию
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, map[string]any{"status": statusOK}, http.StatusOK)
+}
+
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	if c.draining.Load() {
+		response.JSON(w, map[string]any{"status": statusDraining}, http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeout)
+	defer cancel()
+
+	checks := map[string]string{
+		"database":   statusOK,
+		"migrations": statusOK,
+	}
+	ready := true
+
+	if err := c.db.Ping(ctx); err != nil {
+		c.log.WarnContext(ctx, "database ping failed", "error", err)
+		checks["database"] = err.Error()
+		checks["migrations"] = "unknown"
+		ready = false
+	} else if err := c.checkMigrations(ctx); err != nil {
+		c.log.WarnContext(ctx, "schema check failed", "error", err)
+		checks["migrations"] = err.Error()
+		ready = false
+	}
+
+	if !ready {
+		response.JSON(w, map[string]any{"status": statusUnavailable, "checks": checks}, http.StatusServiceUnavailable)
+		return
+	}
+	response.JSON(w, map[string]any{"status": statusOK, "checks": checks}, http.StatusOK)
+}
+
+// схема новее ожидаемой допустима: при rolling update новая версия
+// накатывает миграции раньше, чем уходят старые поды
+func (c *Checker) checkMigrations(ctx context.Context) error {
+	version, dirty, err := c.db.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < c.expected {
+		return fmt.Errorf("schema version %d, expected %d", version, c.expected)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeDatabase struct {
+	pingErr error
+	version int64
+	dirty   bool
+	err     error
+}
+
+func (f fakeDatabase) Ping(ctx context.Context) error { return f.pingErr }
+
+func (f fakeDatabase) SchemaVersion(ctx context.Context) (int64, bool, error) {
+	return f.version, f.dirty, f.err
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func probe(t *testing.T, c *Checker, path string) (int, map[string]any) {
+	r := chi.NewRouter()
+	c.Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestLive_DoesNotTouchDatabase(t *testing.T) {
+	c := NewChecker(fakeDatabase{pingErr: errors.New("connection refused")}, 7, testLogger())
+
+	code, body := probe(t, c, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", body["status"])
+}
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		db         fakeDatabase
+		wantStatus int
+	}{
+		{name: "up to date", db: fakeDatabase{version: 7}, wantStatus: http.StatusOK},
+		{name: "newer schema", db: fakeDatabase{version: 8}, wantStatus: http.StatusOK},
+		{name: "pending migrations", db: fakeDatabase{version: 6}, wantStatus: http.StatusServiceUnavailable},
+		{name: "dirty migration", db: fakeDatabase{version: 7, dirty: true}, wantStatus: http.StatusServiceUnavailable},
+		{name: "database down", db: fakeDatabase{pingErr: errors.New("connection refused")}, wantStatus: http.StatusServiceUnavailable},
+		{name: "version query failed", db: fakeDatabase{err: errors.New("timeout")}, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(tt.db, 7, testLogger())
+			code, _ := probe(t, c, "/readyz")
+			require.Equal(t, tt.wantStatus, code)
+		})
+	}
+}
+
+func TestReady_Draining(t *testing.T) {
+	c := NewChecker(fakeDatabase{version: 7}, 7, testLogger())
+
+	code, _ := probe(t, c, "/readyz")
+	require.Equal(t, http.StatusOK, code)
+
+	c.SetDraining()
+	code, body := probe(t, c, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "shutting_down", body["status"])
+
+	code, _ = probe(t, c, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+}
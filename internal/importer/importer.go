@@ -0,0 +1,259 @@
+package importer
+
+import (
+	"avito-pr-service/internal/models"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// файл JSON/YAML: либо {"teams": [...]}, либо сразу массив команд
+// в формате /team/add
+type file struct {
+	Teams []team `json:"teams" yaml:"teams"`
+}
+
+type team struct {
+	Name    string   `json:"team_name" yaml:"team_name"`
+	Members []member `json:"members" yaml:"members"`
+}
+
+type member struct {
+	UserID   string `json:"user_id" yaml:"user_id"`
+	Username string `json:"username" yaml:"username"`
+	// не указан — пользователь активен
+	IsActive *bool `json:"is_active" yaml:"is_active"`
+}
+
+var csvColumns = []string{"team_name", "user_id", "username", "is_active"}
+
+// FormatFromPath определяет формат по расширению файла
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return models.ImportFormatJSON, nil
+	case ".yaml", ".yml":
+		return models.ImportFormatYAML, nil
+	case ".csv":
+		return models.ImportFormatCSV, nil
+	default:
+		return "", fmt.Errorf("cannot detect format of %q, expected .json, .yaml, .yml or .csv", path)
+	}
+}
+
+// FormatFromContentType определяет формат по заголовку Content-Type
+func FormatFromContentType(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("invalid content type %q", contentType)
+	}
+	switch mediaType {
+	case "application/json":
+		return models.ImportFormatJSON, nil
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return models.ImportFormatYAML, nil
+	case "text/csv":
+		return models.ImportFormatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+// Parse разбирает файл целиком и возвращает все строки и все найденные
+// ошибки формата; бизнес-проверки выполняет usecase
+func Parse(data []byte, format string) ([]models.ImportRow, []models.ImportRowError) {
+	switch format {
+	case models.ImportFormatJSON:
+		teams, err := decodeJSON(data)
+		if err != nil {
+			return nil, []models.ImportRowError{{Location: "file", Message: err.Error()}}
+		}
+		return flatten(teams)
+	case models.ImportFormatYAML:
+		teams, err := decodeYAML(data)
+		if err != nil {
+			return nil, []models.ImportRowError{{Location: "file", Message: err.Error()}}
+		}
+		return flatten(teams)
+	case models.ImportFormatCSV:
+		return parseCSV(data)
+	default:
+		return nil, []models.ImportRowError{{Location: "file", Message: fmt.Sprintf("unsupported format %q", format)}}
+	}
+}
+
+func decodeJSON(data []byte) ([]team, error) {
+	trimmed := bytes.TrimSpace(data)
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.DisallowUnknownFields()
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var teams []team
+		if err := dec.Decode(&teams); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return teams, nil
+	}
+
+	var f file
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return f.Teams, nil
+}
+
+func decodeYAML(data []byte) ([]team, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+
+	// KnownFields работает только через Decoder, поэтому декодируем заново
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if root.Content[0].Kind == yaml.SequenceNode {
+		var teams []team
+		if err := dec.Decode(&teams); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		return teams, nil
+	}
+
+	var f file
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return f.Teams, nil
+}
+
+func flatten(teams []team) ([]models.ImportRow, []models.ImportRowError) {
+	var rows []models.ImportRow
+	var errs []models.ImportRowError
+	for i, t := range teams {
+		if len(t.Members) == 0 {
+			errs = append(errs, models.ImportRowError{
+				Location: fmt.Sprintf("teams[%d]", i),
+				Field:    "members",
+				Message:  "team has no members",
+			})
+			continue
+		}
+		for j, m := range t.Members {
+			isActive := true
+			if m.IsActive != nil {
+				isActive = *m.IsActive
+			}
+			rows = append(rows, models.ImportRow{
+				Location: fmt.Sprintf("teams[%d].members[%d]", i, j),
+				TeamName: t.Name,
+				UserID:   m.UserID,
+				Username: m.Username,
+				IsActive: isActive,
+			})
+		}
+	}
+	return rows, errs
+}
+
+func parseCSV(data []byte) ([]models.ImportRow, []models.ImportRowError) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []models.ImportRowError{{Location: "line 1", Message: fmt.Sprintf("invalid CSV: %v", err)}}
+	}
+
+	index := make(map[string]int, len(header))
+	var errs []models.ImportRowError
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !isCSVColumn(name) {
+			errs = append(errs, models.ImportRowError{Location: "line 1", Field: name, Message: "unknown column"})
+			continue
+		}
+		index[name] = i
+	}
+	for _, name := range csvColumns[:3] {
+		if _, ok := index[name]; !ok {
+			errs = append(errs, models.ImportRowError{Location: "line 1", Field: name, Message: "missing column"})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var rows []models.ImportRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// после синтаксической ошибки дальше читать файл бессмысленно
+			location := "file"
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				location = fmt.Sprintf("line %d", parseErr.StartLine)
+			}
+			errs = append(errs, models.ImportRowError{Location: location, Message: fmt.Sprintf("invalid CSV: %v", err)})
+			break
+		}
+		line, _ := r.FieldPos(0)
+		location := fmt.Sprintf("line %d", line)
+		if len(record) != len(header) {
+			errs = append(errs, models.ImportRowError{
+				Location: location,
+				Message:  fmt.Sprintf("expected %d fields, got %d", len(header), len(record)),
+			})
+			continue
+		}
+
+		row := models.ImportRow{
+			Location: location,
+			TeamName: strings.TrimSpace(record[index["team_name"]]),
+			UserID:   strings.TrimSpace(record[index["user_id"]]),
+			Username: strings.TrimSpace(record[index["username"]]),
+			IsActive: true,
+		}
+		if i, ok := index["is_active"]; ok && strings.TrimSpace(record[i]) != "" {
+			isActive, err := strconv.ParseBool(strings.TrimSpace(record[i]))
+			if err != nil {
+				errs = append(errs, models.ImportRowError{
+					Location: location,
+					Field:    "is_active",
+					Message:  fmt.Sprintf("invalid boolean %q", record[i]),
+				})
+				continue
+			}
+			row.IsActive = isActive
+		}
+		rows = append(rows, row)
+	}
+	return rows, errs
+}
+
+func isCSVColumn(name string) bool {
+	for _, c := range csvColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"avito-pr-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParse_Formats(t *testing.T) {
+	want := []models.ImportRow{
+		{TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true},
+		{TeamName: "backend", UserID: "u2", Username: "Bob", IsActive: false},
+		{TeamName: "frontend", UserID: "u3", Username: "Carol", IsActive: true},
+	}
+
+	tests := []struct {
+		name      string
+		format    string
+		data      string
+		locations []string
+	}{
+		{
+			name:   "json object",
+			format: models.ImportFormatJSON,
+			data: `{"teams": [
+				{"team_name": "backend", "members": [
+					{"user_id": "u1", "username": "Alice"},
+					{"user_id": "u2", "username": "Bob", "is_active": false}
+				]},
+				{"team_name": "frontend", "members": [{"user_id": "u3", "username": "Carol", "is_active": true}]}
+			]}`,
+			locations: []string{"teams[0].members[0]", "teams[0].members[1]", "teams[1].members[0]"},
+		},
+		{
+			name:   "json array",
+			format: models.ImportFormatJSON,
+			data: `[
+				{"team_name": "backend", "members": [
+					{"user_id": "u1", "username": "Alice"},
+					{"user_id": "u2", "username": "Bob", "is_active": false}
+				]},
+				{"team_name": "frontend", "members": [{"user_id": "u3", "username": "Carol"}]}
+			]`,
+			locations: []string{"teams[0].members[0]", "teams[0].members[1]", "teams[1].members[0]"},
+		},
+		{
+			name:   "yaml",
+			format: models.ImportFormatYAML,
+			data: `
+teams:
+  - team_name: backend
+    members:
+      - {user_id: u1, username: Alice}
+      - user_id: u2
+        username: Bob
+        is_active: false
+  - team_name: frontend
+    members:
+      - {user_id: u3, username: Carol}
+`,
+			locations: []string{"teams[0].members[0]", "teams[0].members[1]", "teams[1].members[0]"},
+		},
+		{
+			name:      "csv",
+			format:    models.ImportFormatCSV,
+			data:      "\ufeffteam_name,user_id,username,is_active\nbackend,u1,Alice,\nbackend,u2,Bob,false\n\nfrontend,u3,Carol,1\n",
+			locations: []string{"line 2", "line 3", "line 5"},
+		},
+		{
+			name:      "csv columns in any order",
+			format:    models.ImportFormatCSV,
+			data:      "username,team_name,is_active,user_id\nAlice,backend,true,u1\nBob,backend,f,u2\nCarol,frontend,t,u3\n",
+			locations: []string{"line 2", "line 3", "line 4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, errs := Parse([]byte(tt.data), tt.format)
+			require.Empty(t, errs)
+			require.Len(t, rows, len(want))
+			for i, row := range rows {
+				assert.Equal(t, tt.locations[i], row.Location)
+				row.Location = ""
+				assert.Equal(t, want[i], row)
+			}
+		})
+	}
+}
+
+func TestParse_ReportsAllRowErrors(t *testing.T) {
+	data := "team_name,user_id,username,is_active\n" +
+		"backend,u1,Alice,yes\n" +
+		"backend,u2\n" +
+		"backend,u3,Carol,maybe\n" +
+		"backend,u4,Dave,true\n"
+
+	rows, errs := Parse([]byte(data), models.ImportFormatCSV)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "u4", rows[0].UserID)
+	assert.Equal(t, []models.ImportRowError{
+		{Location: "line 2", Field: "is_active", Message: `invalid boolean "yes"`},
+		{Location: "line 3", Message: "expected 4 fields, got 2"},
+		{Location: "line 4", Field: "is_active", Message: `invalid boolean "maybe"`},
+	}, errs)
+}
+
+func TestParse_FileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		data     string
+		location string
+	}{
+		{"broken json", models.ImportFormatJSON, `{"teams": [`, "file"},
+		{"unknown json field", models.ImportFormatJSON, `{"teams": [{"team_name": "a", "members": [{"userid": "u1"}]}]}`, "file"},
+		{"unknown yaml field", models.ImportFormatYAML, "teams:\n  - name: a\n", "file"},
+		{"csv missing column", models.ImportFormatCSV, "team_name,user_id\nbackend,u1\n", "line 1"},
+		{"csv unknown column", models.ImportFormatCSV, "team_name,user_id,username,email\n", "line 1"},
+		{"csv bad quote", models.ImportFormatCSV, "team_name,user_id,username\nbackend,\"u1,Alice\n", "line 2"},
+		{"unknown format", "xml", "<teams/>", "file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := Parse([]byte(tt.data), tt.format)
+			require.NotEmpty(t, errs)
+			assert.Equal(t, tt.location, errs[0].Location)
+		})
+	}
+}
+
+func TestParse_EmptyTeam(t *testing.T) {
+	rows, errs := Parse([]byte(`[{"team_name": "a", "members": []}, {"team_name": "b", "members": [{"user_id": "u1", "username": "x"}]}]`), models.ImportFormatJSON)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, []models.ImportRowError{{Location: "teams[0]", Field: "members", Message: "team has no members"}}, errs)
+}
+
+func TestFormatDetection(t *testing.T) {
+	format, err := FormatFromPath("teams.YML")
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportFormatYAML, format)
+
+	_, err = FormatFromPath("teams.txt")
+	assert.Error(t, err)
+
+	format, err = FormatFromContentType("text/csv; charset=utf-8")
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportFormatCSV, format)
+
+	_, err = FormatFromContentType("application/xml")
+	assert.Error(t, err)
+}
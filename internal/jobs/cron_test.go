@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	// понедельник
+	from := time.Date(2024, 5, 13, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@every 15m", time.Date(2024, 5, 13, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 5, 13, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2024, 5, 13, 10, 18, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2024, 5, 13, 10, 20, 0, 0, time.UTC)},
+		{"5,45 9-11 * * *", time.Date(2024, 5, 13, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 0", time.Date(2024, 5, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// день месяца или день недели: ближайшая пятница раньше 20-го
+		{"0 0 20 * 5", time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(from).UTC())
+		})
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"@weekly",
+		"@every",
+		"@every 10ms",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseSchedule(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseSchedule_Never(t *testing.T) {
+	s, err := ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
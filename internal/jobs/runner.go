@@ -0,0 +1,269 @@
+package jobs
+
+import (
+	"avito-pr-service/internal/metrics"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// KindCleanup удаляет успешно выполненные задачи и упавшие запуски
+// по расписанию старше Retention
+const KindCleanup = "jobs.cleanup"
+
+// Handler выполняет задачу. Ошибка — повтор с экспоненциальной задержкой,
+// после MaxAttempts попыток задача становится FAILED. Задача может быть
+// выполнена повторно, если воркер упал, поэтому обработчики должны быть
+// идемпотентны
+type Handler func(ctx context.Context, job models.Job) error
+
+type scheduled struct {
+	kind     string
+	schedule Schedule
+	next     time.Time
+}
+
+// Runner — пул воркеров над таблицей jobs и планировщик повторяющихся
+// задач; Register и Schedule вызываются до Run
+type Runner struct {
+	repo      repository.JobRepository
+	log       *slog.Logger
+	handlers  map[string]Handler
+	schedules []*scheduled
+
+	Workers      int
+	PollInterval time.Duration
+	// сколько задача может выполняться, прежде чем её заберёт другой воркер
+	Lease       time.Duration
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Retention   time.Duration
+}
+
+func NewRunner(repo repository.JobRepository, log *slog.Logger) *Runner {
+	r := &Runner{
+		repo:         repo,
+		log:          log.With("component", "jobs"),
+		handlers:     make(map[string]Handler),
+		Workers:      4,
+		PollInterval: time.Second,
+		Lease:        5 * time.Minute,
+		MaxAttempts:  5,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   time.Hour,
+		Retention:    7 * 24 * time.Hour,
+	}
+	r.Register(KindCleanup, r.cleanup)
+	if err := r.Schedule(KindCleanup, "@hourly"); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r *Runner) Register(kind string, h Handler) {
+	r.handlers[kind] = h
+}
+
+// Schedule ставит задачу kind по расписанию spec (см. ParseSchedule).
+// Каждый запуск получает ключ kind@время, поэтому при нескольких
+// репликах он попадает в очередь один раз. Пропущенные, пока сервис
+// не работал, запуски не догоняются
+func (r *Runner) Schedule(kind, spec string) error {
+	if _, ok := r.handlers[kind]; !ok {
+		return fmt.Errorf("schedule %s: no handler registered", kind)
+	}
+	s, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	r.schedules = append(r.schedules, &scheduled{kind: kind, schedule: s})
+	return nil
+}
+
+// Enqueue ставит разовую задачу; runAt в прошлом или нулевой — как можно скорее
+func (r *Runner) Enqueue(ctx context.Context, kind string, payload any, runAt time.Time) (models.Job, error) {
+	if _, ok := r.handlers[kind]; !ok {
+		return models.Job{}, fmt.Errorf("enqueue %s: no handler registered", kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return models.Job{}, fmt.Errorf("encode job payload: %w", err)
+	}
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+	job, _, err := r.repo.Enqueue(ctx, models.Job{Kind: kind, Payload: data, MaxAttempts: r.MaxAttempts, RunAt: runAt})
+	return job, err
+}
+
+// Run блокируется до отмены ctx и ждёт, пока воркеры допишут текущие задачи
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range r.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+
+	now := time.Now()
+	for _, s := range r.schedules {
+		s.next = s.schedule.Next(now)
+	}
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			r.EnqueueScheduled(ctx, time.Now())
+		}
+	}
+}
+
+// EnqueueScheduled ставит в очередь повторяющиеся задачи, время которых пришло
+func (r *Runner) EnqueueScheduled(ctx context.Context, now time.Time) {
+	for _, s := range r.schedules {
+		if s.next.IsZero() || now.Before(s.next) {
+			continue
+		}
+		// повтором для повторяющейся задачи служит следующий запуск
+		_, created, err := r.repo.Enqueue(ctx, models.Job{
+			Kind:        s.kind,
+			MaxAttempts: 1,
+			RunAt:       s.next,
+			UniqueKey:   s.kind + "@" + s.next.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			r.log.ErrorContext(ctx, "failed to enqueue scheduled job", "kind", s.kind, "error", err)
+			continue
+		}
+		if created {
+			r.log.DebugContext(ctx, "scheduled job enqueued", "kind", s.kind, "run_at", s.next)
+		}
+		s.next = s.schedule.Next(now)
+	}
+}
+
+func (r *Runner) work(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// пока очередь не пуста, берём задачи без ожидания тикера
+			for ctx.Err() == nil && r.RunNext(ctx) {
+			}
+		}
+	}
+}
+
+// RunNext выполняет одну задачу и сообщает, нашлась ли она
+func (r *Runner) RunNext(ctx context.Context) bool {
+	jobs, err := r.repo.ClaimDue(ctx, slices.Sorted(maps.Keys(r.handlers)), 1, r.Lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.log.ErrorContext(ctx, "failed to claim jobs", "error", err)
+		}
+		return false
+	}
+	for _, job := range jobs {
+		r.process(ctx, job)
+	}
+	return len(jobs) > 0
+}
+
+func (r *Runner) process(ctx context.Context, job models.Job) {
+	runErr := r.call(ctx, job)
+
+	// результат записываем и при остановке сервера, иначе задача
+	// провисит RUNNING до конца аренды
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if runErr == nil {
+		metrics.JobsProcessed.WithLabelValues(job.Kind, "done").Inc()
+		if err := r.repo.MarkDone(saveCtx, job); err != nil {
+			r.markError(ctx, job, err)
+		}
+		return
+	}
+
+	attempts, next, dead := job.Attempts, time.Now().Add(r.backoff(job.Attempts)), job.Attempts >= job.MaxAttempts
+	switch {
+	case ctx.Err() != nil:
+		// прервана остановкой сервера: попытку не засчитываем
+		attempts, next, dead = job.Attempts-1, time.Now(), false
+		r.log.InfoContext(ctx, "job interrupted by shutdown", "id", job.ID, "kind", job.Kind)
+	case dead:
+		metrics.JobsProcessed.WithLabelValues(job.Kind, "failed").Inc()
+		r.log.WarnContext(ctx, "job failed", "id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", runErr)
+	default:
+		metrics.JobsProcessed.WithLabelValues(job.Kind, "retry").Inc()
+		r.log.InfoContext(ctx, "job failed, will retry", "id", job.ID, "kind", job.Kind, "attempt", job.Attempts, "next", next, "error", runErr)
+	}
+
+	if err := r.repo.MarkFailed(saveCtx, job, attempts, next, runErr.Error(), dead); err != nil {
+		r.markError(ctx, job, err)
+	}
+}
+
+func (r *Runner) markError(ctx context.Context, job models.Job, err error) {
+	if errors.Is(err, repository.ErrLeaseLost) {
+		r.log.WarnContext(ctx, "job outlived its lease, result dropped", "id", job.ID, "kind", job.Kind, "attempt", job.Attempts)
+		return
+	}
+	r.log.ErrorContext(ctx, "failed to mark job", "id", job.ID, "error", err)
+}
+
+func (r *Runner) call(ctx context.Context, job models.Job) (err error) {
+	ctx, span := tracing.Start(ctx, "Job "+job.Kind)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.Lease)
+	defer cancel()
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return r.handlers[job.Kind](ctx, job)
+}
+
+func (r *Runner) backoff(attempt int) time.Duration {
+	delay := r.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= r.MaxBackoff {
+			return r.MaxBackoff
+		}
+	}
+	return delay
+}
+
+func (r *Runner) cleanup(ctx context.Context, _ models.Job) error {
+	n, err := r.repo.DeleteFinished(ctx, time.Now().Add(-r.Retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		r.log.InfoContext(ctx, "finished jobs deleted", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package jobs
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type failedCall struct {
+	id       int64
+	attempts int
+	runAt    time.Time
+	lastErr  string
+	dead     bool
+}
+
+// fakeJobRepository хранит очередь в памяти; ClaimDue отдаёт задачи
+// в порядке постановки, не глядя на run_at
+type fakeJobRepository struct {
+	mu      sync.Mutex
+	queue   []models.Job
+	keys    map[string]bool
+	done    []int64
+	failed  []failedCall
+	kinds   []string
+	deleted time.Time
+	nextID  int64
+}
+
+func newFakeJobRepository() *fakeJobRepository {
+	return &fakeJobRepository{keys: make(map[string]bool)}
+}
+
+func (f *fakeJobRepository) Enqueue(ctx context.Context, job models.Job) (models.Job, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if job.UniqueKey != "" {
+		if f.keys[job.UniqueKey] {
+			return models.Job{}, false, nil
+		}
+		f.keys[job.UniqueKey] = true
+	}
+	f.nextID++
+	job.ID = f.nextID
+	job.Status = models.JobPending
+	f.queue = append(f.queue, job)
+	return job, true, nil
+}
+
+func (f *fakeJobRepository) ClaimDue(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]models.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kinds = kinds
+	if len(f.queue) == 0 {
+		return nil, nil
+	}
+	job := f.queue[0]
+	f.queue = f.queue[1:]
+	job.Status = models.JobRunning
+	job.Attempts++
+	return []models.Job{job}, nil
+}
+
+func (f *fakeJobRepository) MarkDone(ctx context.Context, job models.Job) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.done = append(f.done, job.ID)
+	return nil
+}
+
+func (f *fakeJobRepository) MarkFailed(ctx context.Context, job models.Job, attempts int, runAt time.Time, lastErr string, dead bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed = append(f.failed, failedCall{id: job.ID, attempts: attempts, runAt: runAt, lastErr: lastErr, dead: dead})
+	return nil
+}
+
+func (f *fakeJobRepository) List(ctx context.Context, filter models.JobFilter) ([]models.Job, error) {
+	return nil, nil
+}
+
+func (f *fakeJobRepository) Get(ctx context.Context, id int64) (models.Job, error) {
+	return models.Job{}, models.ErrJobNotFound
+}
+
+func (f *fakeJobRepository) Retry(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (f *fakeJobRepository) DeleteFinished(ctx context.Context, before time.Time) (int, error) {
+	f.deleted = before
+	return 3, nil
+}
+
+func newTestRunner(repo *fakeJobRepository) *Runner {
+	r := NewRunner(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.BaseBackoff = time.Second
+	r.MaxBackoff = 5 * time.Second
+	return r
+}
+
+func TestRunner_RunNext_Done(t *testing.T) {
+	repo := newFakeJobRepository()
+	r := newTestRunner(repo)
+
+	var got models.Job
+	r.Register("email", func(ctx context.Context, job models.Job) error {
+		got = job
+		return nil
+	})
+
+	_, err := r.Enqueue(context.Background(), "email", map[string]string{"to": "u1"}, time.Time{})
+	require.NoError(t, err)
+
+	assert.True(t, r.RunNext(context.Background()))
+	assert.Equal(t, "email", got.Kind)
+	assert.JSONEq(t, `{"to":"u1"}`, string(got.Payload))
+	assert.Equal(t, []int64{1}, repo.done)
+	assert.Empty(t, repo.failed)
+	assert.Equal(t, []string{"email", KindCleanup}, repo.kinds)
+
+	assert.False(t, r.RunNext(context.Background()))
+}
+
+func TestRunner_RunNext_RetryWithBackoff(t *testing.T) {
+	repo := newFakeJobRepository()
+	r := newTestRunner(repo)
+	r.Register("email", func(ctx context.Context, job models.Job) error {
+		return errors.New("smtp is down")
+	})
+
+	_, _, err := repo.Enqueue(context.Background(), models.Job{Kind: "email", Attempts: 2, MaxAttempts: 5})
+	require.NoError(t, err)
+
+	before := time.Now()
+	require.True(t, r.RunNext(context.Background()))
+	require.Len(t, repo.failed, 1)
+	call := repo.failed[0]
+	assert.False(t, call.dead)
+	assert.Equal(t, 3, call.attempts)
+	assert.Equal(t, "smtp is down", call.lastErr)
+	// третья попытка: 1s * 2 * 2
+	assert.WithinDuration(t, before.Add(4*time.Second), call.runAt, time.Second)
+}
+
+func TestRunner_RunNext_Dead(t *testing.T) {
+	repo := newFakeJobRepository()
+	r := newTestRunner(repo)
+	r.Register("email", func(ctx context.Context, job models.Job) error {
+		return errors.New("smtp is down")
+	})
+
+	_, _, err := repo.Enqueue(context.Background(), models.Job{Kind: "email", Attempts: 4, MaxAttempts: 5})
+	require.NoError(t, err)
+
+	require.True(t, r.RunNext(context.Background()))
+	require.Len(t, repo.failed, 1)
+	assert.True(t, repo.failed[0].dead)
+	assert.Equal(t, 5, repo.failed[0].attempts)
+	assert.Empty(t, repo.done)
+}
+
+func TestRunner_RunNext_Panic(t *testing.T) {
+	repo := newFakeJobRepository()
+	r := newTestRunner(repo)
+	r.Register("email", func(ctx context.Context, job models.Job) error {
+		panic("nil map")
+	})
+
+	_, err := r.Enqueue(context.Background(), "email", nil, time.Time{})
+	require.NoError(t, err)
+
+	require.True(t, r.RunNext(context.Background()))
+	require.Len(t, repo.failed, 1)
+	assert.Equal(t, "panic: nil map", repo.failed[0].lastErr)
+}
+
+func TestRunner_RunNext_Shutdown(t *testing.T) {
+	repo := newFakeJobRepository()
+	r := newTestRunner(repo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Register("email", func(ctx context.Context, job models.Job) error {
+		cancel()
+		return ctx.Err()
+	})
+
+	_, _, err := repo.Enqueue(context.Background(), models.Job{Kind: "email", Attempts: 4, MaxAttempts: 5})
+	require.NoError(t, err)
+
+	require.True(t, r.RunNext(ctx))
+	require.Len(t, repo.failed, 1)
+	// попытка, прерванная остановкой, не засчитывается
+	assert.False(t, repo.failed[0].dead)
+	assert.Equal(t, 4, repo.failed[0].attempts)
+}
+
+func TestRunner_Backoff(t *testing.T) {
+	r := newTestRunner(newFakeJobRepository())
+
+	assert.Equal(t, time.Second, r.backoff(1))
+	assert.Equal(t, 2*time.Second, r.backoff(2))
+	assert.Equal(t, 4*time.Second, r.backoff(3))
+	assert.Equal(t, 5*time.Second, r.backoff(4))
+	assert.Equal(t, 5*time.Second, r.backoff(50))
+}
+
+func TestRunner_EnqueueScheduled(t *testing.T) {
+	repo := newFakeJobRepository()
+	r := newTestRunner(repo)
+	r.Register("report", func(ctx context.Context, job models.Job) error { return nil })
+	require.NoError(t, r.Schedule("report", "@every 10m"))
+
+	start := time.Date(2024, 5, 13, 10, 0, 0, 0, time.UTC)
+	startSchedule(r, "report", start)
+
+	r.EnqueueScheduled(context.Background(), start.Add(5*time.Minute))
+	assert.Empty(t, repo.queue)
+
+	r.EnqueueScheduled(context.Background(), start.Add(10*time.Minute))
+	require.Len(t, repo.queue, 1)
+	job := repo.queue[0]
+	assert.Equal(t, "report", job.Kind)
+	assert.Equal(t, 1, job.MaxAttempts)
+	assert.Equal(t, "report@2024-05-13T10:10:00Z", job.UniqueKey)
+
+	// вторая реплика с тем же расписанием не создаёт дубль
+	other := newTestRunner(repo)
+	other.Register("report", func(ctx context.Context, job models.Job) error { return nil })
+	require.NoError(t, other.Schedule("report", "@every 10m"))
+	startSchedule(other, "report", start)
+	other.EnqueueScheduled(context.Background(), start.Add(10*time.Minute))
+	assert.Len(t, repo.queue, 1)
+
+	// сервис простоял: пропущенные запуски не догоняются
+	r.EnqueueScheduled(context.Background(), start.Add(time.Hour))
+	r.EnqueueScheduled(context.Background(), start.Add(time.Hour+time.Minute))
+	require.Len(t, repo.queue, 2)
+	assert.Equal(t, "report@2024-05-13T10:20:00Z", repo.queue[1].UniqueKey)
+	assert.True(t, slices.ContainsFunc(r.schedules, func(s *scheduled) bool {
+		return s.kind == "report" && s.next.Equal(start.Add(70*time.Minute))
+	}))
+}
+
+// startSchedule делает то же, что Run при старте, но только для kind:
+// остальные расписания, в том числе очистка, остаются выключенными
+func startSchedule(r *Runner, kind string, now time.Time) {
+	for _, s := range r.schedules {
+		if s.kind == kind {
+			s.next = s.schedule.Next(now)
+		}
+	}
+}
+
+func TestRunner_Cleanup(t *testing.T) {
+	repo := newFakeJobRepository()
+	r := newTestRunner(repo)
+	r.Retention = time.Hour
+
+	_, _, err := repo.Enqueue(context.Background(), models.Job{Kind: KindCleanup, MaxAttempts: 1})
+	require.NoError(t, err)
+
+	require.True(t, r.RunNext(context.Background()))
+	assert.Equal(t, []int64{1}, repo.done)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), repo.deleted, time.Second)
+}
+
+func TestRunner_UnknownKind(t *testing.T) {
+	r := newTestRunner(newFakeJobRepository())
+
+	_, err := r.Enqueue(context.Background(), "email", nil, time.Time{})
+	assert.Error(t, err)
+	assert.Error(t, r.Schedule("email", "@hourly"))
+}
@@ -0,0 +1,34 @@
+package logging
+
+import "context"
+
+type ctxKey struct{}
+
+// requestInfo общий для всего запроса: внутренние middleware (например,
+// аутентификация) дописывают в него данные, которые попадут в access-лог
+type requestInfo struct {
+	id    string
+	actor string
+}
+
+func withRequestInfo(ctx context.Context, info *requestInfo) context.Context {
+	return context.WithValue(ctx, ctxKey{}, info)
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return withRequestInfo(ctx, &requestInfo{id: id})
+}
+
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// SetActor запоминает пользователя, от имени которого выполняется запрос
+func SetActor(ctx context.Context, actor string) {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		info.actor = actor
+	}
+}
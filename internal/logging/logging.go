@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"avito-pr-service/config"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"io"
+	"log/slog"
+)
+
+// New собирает логгер сервиса: формат и уровень из конфигурации,
+// request_id, trace_id и span_id из контекста записи
+func New(cfg config.LogConfig, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.SlogLevel()}
+
+	var h slog.Handler
+	if cfg.Format == config.LogFormatJSON {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(tracing.NewLogHandler(NewHandler(h)))
+}
+
+// Handler добавляет request_id в записи, залогированные через
+// *Context-методы slog в рамках HTTP-запроса
+type Handler struct {
+	slog.Handler
+}
+
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{Handler: h}
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
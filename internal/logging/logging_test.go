@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"avito-pr-service/config"
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func jsonLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestMiddleware_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(config.LogConfig{Level: "info", Format: config.LogFormatJSON}, &buf)
+
+	r := chi.NewRouter()
+	r.Use(Middleware(log))
+	r.Get("/team/get", func(w http.ResponseWriter, r *http.Request) {
+		SetActor(r.Context(), "u1")
+		// так пишут usecase-ы: request_id берётся из контекста
+		log.InfoContext(r.Context(), "loading team")
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-42", w.Header().Get(RequestIDHeader))
+
+	lines := jsonLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "loading team", lines[0]["msg"])
+	assert.Equal(t, "req-42", lines[0]["request_id"])
+
+	access := lines[1]
+	assert.Equal(t, "http request", access["msg"])
+	assert.Equal(t, "WARN", access["level"])
+	assert.Equal(t, "req-42", access["request_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/team/get", access["route"])
+	assert.Equal(t, float64(http.StatusNotFound), access["status"])
+	assert.Equal(t, "u1", access["actor"])
+	assert.Contains(t, access, "latency")
+}
+
+func TestMiddleware_RequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"missing", "", false},
+		{"valid", "0f8fad5b-d9cb-469f-a165-70867728950e", true},
+		{"with spaces", "abc def", false},
+		{"newline injection", "abc\nlevel=ERROR", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := Middleware(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = RequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			require.NotEmpty(t, seen)
+			assert.Equal(t, seen, w.Header().Get(RequestIDHeader))
+			if tt.keep {
+				assert.Equal(t, tt.incoming, seen)
+			} else {
+				assert.NotEqual(t, tt.incoming, seen)
+				assert.Len(t, seen, 32)
+			}
+		})
+	}
+}
+
+func TestNew_LevelAndFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(config.LogConfig{Level: "warn", Format: config.LogFormatText}, &buf)
+
+	log.Info("hidden")
+	log.WarnContext(WithRequestID(t.Context(), "req-1"), "shown")
+
+	out := buf.String()
+	assert.NotContains(t, out, "hidden")
+	assert.Contains(t, out, "msg=shown")
+	assert.Contains(t, out, "request_id=req-1")
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// Middleware берёт X-Request-ID из запроса (или генерирует новый),
+// возвращает его в ответе, кладёт в контекст и пишет одну строку
+// access-лога на запрос
+func Middleware(log *slog.Logger) func(http.Handler) http.Handler {
+	log = log.With("component", "http")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			info := &requestInfo{id: id}
+			r = r.WithContext(withRequestInfo(r.Context(), info))
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			defer func() {
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				level := slog.LevelInfo
+				switch {
+				case status >= 500:
+					level = slog.LevelError
+				case status >= 400:
+					level = slog.LevelWarn
+				}
+
+				attrs := []slog.Attr{
+					slog.String("method", r.Method),
+					slog.String("route", route(r)),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", status),
+					slog.Int("bytes", ww.BytesWritten()),
+					slog.Duration("latency", time.Since(start)),
+					slog.String("remote_addr", r.RemoteAddr),
+				}
+				if info.actor != "" {
+					attrs = append(attrs, slog.String("actor", info.actor))
+				}
+				log.LogAttrs(r.Context(), level, "http request", attrs...)
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// шаблон маршрута вместо пути, чтобы id в query не размножали ключи в логах
+func route(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return "unmatched"
+}
+
+// чужой идентификатор принимаем, только если он не сломает логи
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
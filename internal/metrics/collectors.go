@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"log/slog"
+	"time"
+)
+
+type poolCollector struct {
+	stat func() *pgxpool.Stat
+
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	acquireCount *prometheus.Desc
+	acquireWait  *prometheus.Desc
+	emptyAcquire *prometheus.Desc
+}
+
+func NewPoolCollector(stat func() *pgxpool.Stat) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		stat:         stat,
+		acquired:     desc("acquired_conns", "Connections currently in use."),
+		idle:         desc("idle_conns", "Idle connections in the pool."),
+		total:        desc("total_conns", "Total connections in the pool."),
+		max:          desc("max_conns", "Maximum size of the pool."),
+		acquireCount: desc("acquires_total", "Successful connection acquires."),
+		acquireWait:  desc("acquire_wait_seconds_total", "Total time spent waiting for a connection."),
+		emptyAcquire: desc("empty_acquires_total", "Acquires that had to wait because the pool was empty."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquireCount
+	ch <- c.acquireWait
+	ch <- c.emptyAcquire
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+}
+
+type OpenPRCounter interface {
+	CountOpenPRsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+// количество открытых PR считается запросом к БД на каждый scrape, поэтому
+// не отстаёт от данных после рестарта и при нескольких репликах
+type openPRCollector struct {
+	repo    OpenPRCounter
+	log     *slog.Logger
+	timeout time.Duration
+	desc    *prometheus.Desc
+}
+
+func NewOpenPRCollector(repo OpenPRCounter, log *slog.Logger) prometheus.Collector {
+	return &openPRCollector{
+		repo:    repo,
+		log:     log.With("component", "metrics"),
+		timeout: 5 * time.Second,
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "open_prs"),
+			"Open pull requests by author team.", []string{"team"}, nil),
+	}
+}
+
+func (c *openPRCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *openPRCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.repo.CountOpenPRsByTeam(ctx)
+	if err != nil {
+		c.log.Error("failed to count open PRs", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for team, n := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), team)
+	}
+}
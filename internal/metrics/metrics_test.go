@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeOpenPRCounter struct {
+	counts map[string]int
+	err    error
+}
+
+func (f fakeOpenPRCounter) CountOpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	return f.counts, f.err
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestMiddleware_LabelsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/team/{name}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	before := testutil.ToFloat64(HTTPRequests.WithLabelValues(http.MethodGet, "/team/{name}", "404"))
+	for _, name := range []string{"backend", "frontend"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/team/"+name, nil))
+	}
+
+	require.Equal(t, before+2, testutil.ToFloat64(HTTPRequests.WithLabelValues(http.MethodGet, "/team/{name}", "404")))
+}
+
+func TestMiddleware_UnmatchedRoute(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	before := testutil.ToFloat64(HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404"))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-admin/setup.php", nil))
+
+	require.Equal(t, before+1, testutil.ToFloat64(HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+}
+
+func TestOpenPRCollector(t *testing.T) {
+	c := NewOpenPRCollector(fakeOpenPRCounter{counts: map[string]int{"backend": 3, "frontend": 1}}, testLogger())
+
+	expected := `
+# HELP pr_service_open_prs Open pull requests by author team.
+# TYPE pr_service_open_prs gauge
+pr_service_open_prs{team="backend"} 3
+pr_service_open_prs{team="frontend"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+}
+
+func TestOpenPRCollector_Error(t *testing.T) {
+	c := NewOpenPRCollector(fakeOpenPRCounter{err: errors.New("db down")}, testLogger())
+
+	_, err := testutil.CollectAndLint(c)
+	require.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package models
+
+import "time"
+
+// ArchiveVersion увеличивается при несовместимых изменениях формата;
+// новые поля в записях совместимы и версию не меняют, новые типы записей —
+// меняют. Версия 2 добавила историю переназначений, версия 3 — снимки
+// участников перед деактивацией и шаги обработки зависших PR
+const (
+	ArchiveFormat  = "avito-pr-service"
+	ArchiveVersion = 3
+)
+
+const (
+	ArchiveRecordHeader       = "header"
+	ArchiveRecordTeam         = "team"
+	ArchiveRecordUser         = "user"
+	ArchiveRecordPullRequest  = "pull_request"
+	ArchiveRecordReviewer     = "pr_reviewer"
+	ArchiveRecordReassignment = "reviewer_reassignment"
+	ArchiveRecordVCSIdentity  = "vcs_identity"
+	ArchiveRecordWebhook      = "webhook_subscription"
+	ArchiveRecordSnapshot     = "team_member_snapshot"
+	ArchiveRecordStaleStep    = "stale_pr_step"
+)
+
+type ArchiveHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArchiveTeam struct {
+	Name string `json:"team_name"`
+}
+
+type ArchivePullRequest struct {
+	ID        string     `json:"pull_request_id"`
+	Name      string     `json:"pull_request_name"`
+	AuthorID  string     `json:"author_id"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	MergedAt  *time.Time `json:"merged_at,omitempty"`
+}
+
+type ArchiveReviewer struct {
+	PRID      string     `json:"pull_request_id"`
+	UserID    string     `json:"user_id"`
+	Verdict   string     `json:"verdict,omitempty"`
+	VerdictAt *time.Time `json:"verdict_at,omitempty"`
+}
+
+// ArchiveReassignment.Verdict — вердикт, который снятый ревьювер успел вынести
+type ArchiveReassignment struct {
+	PRID          string     `json:"pull_request_id"`
+	OldReviewerID string     `json:"old_reviewer_id"`
+	NewReviewerID string     `json:"new_reviewer_id"`
+	ReassignedAt  time.Time  `json:"reassigned_at"`
+	Verdict       string     `json:"verdict,omitempty"`
+	VerdictAt     *time.Time `json:"verdict_at,omitempty"`
+}
+
+// ArchiveMemberSnapshot — состояние участника перед последней деактивацией
+// команды; без него /team/activate после восстановления нечего отменять
+type ArchiveMemberSnapshot struct {
+	TeamName  string    `json:"team_name"`
+	UserID    string    `json:"user_id"`
+	WasActive bool      `json:"was_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ArchiveStaleStep — шаг обработки зависшего PR; без них напоминания
+// и эскалации после восстановления повторились бы
+type ArchiveStaleStep struct {
+	PRID          string    `json:"pull_request_id"`
+	Step          string    `json:"step"`
+	ReviewerID    string    `json:"reviewer_id,omitempty"`
+	NewReviewerID string    `json:"new_reviewer_id,omitempty"`
+	LeadID        string    `json:"lead_id,omitempty"`
+	InactiveSince time.Time `json:"inactive_since"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// в отличие от WebhookSubscription секрет попадает в архив,
+// иначе после восстановления подписи перестанут сходиться
+type ArchiveWebhook struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret"`
+	EventTypes []string  `json:"event_types"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Archive — полный снимок данных сервиса; очереди доставки вебхуков
+// и outbox не сохраняются
+type Archive struct {
+	Header        ArchiveHeader
+	Teams         []ArchiveTeam
+	Users         []User
+	PullRequests  []ArchivePullRequest
+	Reviewers     []ArchiveReviewer
+	Reassignments []ArchiveReassignment
+	VCSIdentities []VCSIdentity
+	Webhooks      []ArchiveWebhook
+	Snapshots     []ArchiveMemberSnapshot
+	StaleSteps    []ArchiveStaleStep
+}
+
+type ArchiveSummary struct {
+	Teams         int `json:"teams"`
+	Users         int `json:"users"`
+	PullRequests  int `json:"pull_requests"`
+	Reviewers     int `json:"pr_reviewers"`
+	Reassignments int `json:"reviewer_reassignments"`
+	VCSIdentities int `json:"vcs_identities"`
+	Webhooks      int `json:"webhook_subscriptions"`
+	Snapshots     int `json:"team_member_snapshots"`
+	StaleSteps    int `json:"stale_pr_steps"`
+}
+
+func (a Archive) Summary() ArchiveSummary {
+	return ArchiveSummary{
+		Teams:         len(a.Teams),
+		Users:         len(a.Users),
+		PullRequests:  len(a.PullRequests),
+		Reviewers:     len(a.Reviewers),
+		Reassignments: len(a.Reassignments),
+		VCSIdentities: len(a.VCSIdentities),
+		Webhooks:      len(a.Webhooks),
+		Snapshots:     len(a.Snapshots),
+		StaleSteps:    len(a.StaleSteps),
+	}
+}
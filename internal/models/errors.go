@@ -15,6 +15,18 @@ const (
 	ErrorDuplicateUserID   ErrorCode = "DUPLICATE_USER_ID"
 	ErrorUserInAnotherTeam ErrorCode = "USER_IN_ANOTHER_TEAM"
 	ErrorEmptyTeam         ErrorCode = "EMPTY_TEAM"
+	ErrorUnauthorized      ErrorCode = "UNAUTHORIZED"
+	ErrorForbidden         ErrorCode = "FORBIDDEN"
+	ErrorInvalidImport     ErrorCode = "INVALID_IMPORT"
+	ErrorDatabaseNotEmpty  ErrorCode = "DATABASE_NOT_EMPTY"
+	ErrorInvalidArchive    ErrorCode = "INVALID_ARCHIVE"
+	ErrorNotDeactivated    ErrorCode = "TEAM_NOT_DEACTIVATED"
+	ErrorDeactivating      ErrorCode = "DEACTIVATION_IN_PROGRESS"
+	ErrorReviewSubmitted   ErrorCode = "REVIEW_SUBMITTED"
+	ErrorUnavailable       ErrorCode = "UNAVAILABLE"
+	ErrorResumeExpired     ErrorCode = "RESUME_EXPIRED"
+	ErrorValidation        ErrorCode = "VALIDATION_ERROR"
+	ErrorInternal          ErrorCode = "INTERNAL"
 )
 
 type AppError struct {
@@ -35,19 +47,30 @@ func (e AppError) Is(target error) bool {
 }
 
 var (
-	ErrTeamExists        = AppError{Code: ErrorTeamExists, Message: "team already exists"}
-	ErrPRNotFound        = AppError{Code: ErrorNotFound, Message: "pull request not found"}
-	ErrEmptyTeam         = AppError{Code: ErrorEmptyTeam, Message: "empty team with no participants"}
-	ErrTeamNotFound      = AppError{Code: ErrorNotFound, Message: "team not found"}
-	ErrUserNotFound      = AppError{Code: ErrorNotFound, Message: "user not found"}
-	ErrNotFound          = AppError{Code: ErrorNotFound, Message: "not found"}
-	ErrPRExists          = AppError{Code: ErrorPRExists, Message: "pull request already exists"}
-	ErrNotAssigned       = AppError{Code: ErrorNotAssigned, Message: "reviewer is not assigned to this PR"}
-	ErrNoCandidate       = AppError{Code: ErrorNoCandidate, Message: "no active replacement candidate in team"}
-	ErrPRMerged          = AppError{Code: ErrorPRMerged, Message: "cannot reassign on merged PR"}
-	ErrInvalidStatus     = AppError{Code: ErrorInvalidStatus, Message: "invalid pull request status"}
-	ErrInvalidReviewer   = AppError{Code: ErrorInvalidReviewer, Message: "new reviewer must be active and from the same team"}
-	ErrAlreadyAssigned   = AppError{Code: ErrorAlreadyAssigned, Message: "new reviewer already assigned"}
-	ErrDuplicateUserID   = AppError{Code: ErrorDuplicateUserID, Message: "duplicate user_id in team"}
-	ErrUserInAnotherTeam = AppError{Code: ErrorUserInAnotherTeam, Message: "user already in another team"}
+	ErrTeamExists           = AppError{Code: ErrorTeamExists, Message: "team already exists"}
+	ErrPRNotFound           = AppError{Code: ErrorNotFound, Message: "pull request not found"}
+	ErrEmptyTeam            = AppError{Code: ErrorEmptyTeam, Message: "empty team with no participants"}
+	ErrTeamNotFound         = AppError{Code: ErrorNotFound, Message: "team not found"}
+	ErrUserNotFound         = AppError{Code: ErrorNotFound, Message: "user not found"}
+	ErrNotFound             = AppError{Code: ErrorNotFound, Message: "not found"}
+	ErrPRExists             = AppError{Code: ErrorPRExists, Message: "pull request already exists"}
+	ErrNotAssigned          = AppError{Code: ErrorNotAssigned, Message: "reviewer is not assigned to this PR"}
+	ErrNoCandidate          = AppError{Code: ErrorNoCandidate, Message: "no active replacement candidate in team"}
+	ErrPRMerged             = AppError{Code: ErrorPRMerged, Message: "cannot reassign on merged PR"}
+	ErrInvalidStatus        = AppError{Code: ErrorInvalidStatus, Message: "invalid pull request status"}
+	ErrInvalidReviewer      = AppError{Code: ErrorInvalidReviewer, Message: "new reviewer must be active and from the same team"}
+	ErrAlreadyAssigned      = AppError{Code: ErrorAlreadyAssigned, Message: "new reviewer already assigned"}
+	ErrDuplicateUserID      = AppError{Code: ErrorDuplicateUserID, Message: "duplicate user_id in team"}
+	ErrUserInAnotherTeam    = AppError{Code: ErrorUserInAnotherTeam, Message: "user already in another team"}
+	ErrWebhookNotFound      = AppError{Code: ErrorNotFound, Message: "webhook subscription not found"}
+	ErrDeliveryNotFound     = AppError{Code: ErrorNotFound, Message: "webhook delivery not found"}
+	ErrJobNotFound          = AppError{Code: ErrorNotFound, Message: "job not found"}
+	ErrDeactivationNotFound = AppError{Code: ErrorNotFound, Message: "team deactivation not found"}
+	ErrIdentityNotFound     = AppError{Code: ErrorNotFound, Message: "no user mapped to VCS login"}
+	ErrUnauthorized         = AppError{Code: ErrorUnauthorized, Message: "missing or invalid bearer token"}
+	ErrForbidden            = AppError{Code: ErrorForbidden, Message: "insufficient permissions"}
+	ErrDatabaseNotEmpty     = AppError{Code: ErrorDatabaseNotEmpty, Message: "restore requires an empty database"}
+	ErrTeamNotDeactivated   = AppError{Code: ErrorNotDeactivated, Message: "team has no deactivation to undo"}
+	ErrTeamDeactivating     = AppError{Code: ErrorDeactivating, Message: "team deactivation is still in progress"}
+	ErrReviewSubmitted      = AppError{Code: ErrorReviewSubmitted, Message: "reviewer has already submitted a verdict"}
 )
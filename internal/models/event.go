@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+const (
+	EventPRCreated          = "pr.created"
+	EventReviewerAssigned   = "reviewer.assigned"
+	EventReviewerReassigned = "reviewer.reassigned"
+	EventPRMerged           = "pr.merged"
+	EventTeamDeactivated    = "team.deactivated"
+	EventTeamActivated      = "team.activated"
+	EventPRStaleReminder    = "pr.stale_reminder"
+	EventPRStaleEscalated   = "pr.stale_escalated"
+)
+
+var EventTypes = []string{
+	EventPRCreated,
+	EventReviewerAssigned,
+	EventReviewerReassigned,
+	EventPRMerged,
+	EventTeamDeactivated,
+	EventTeamActivated,
+	EventPRStaleReminder,
+	EventPRStaleEscalated,
+}
+
+type Event struct {
+	ID         int64     `json:"id,omitempty"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	PRID       string    `json:"pull_request_id,omitempty"`
+	TeamName   string    `json:"team_name,omitempty"`
+	Data       any       `json:"data"`
+}
+
+type ReviewerAssignedData struct {
+	PRID       string `json:"pull_request_id"`
+	ReviewerID string `json:"reviewer_id"`
+}
+
+type ReviewerReassignedData struct {
+	PRID          string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+}
+
+type TeamDeactivatedData struct {
+	TeamName         string `json:"team_name"`
+	DeactivatedUsers int    `json:"deactivated_users"`
+	ReassignedPRs    int    `json:"reassigned_prs"`
+}
+
+type TeamActivatedData struct {
+	TeamName       string   `json:"team_name"`
+	ActivatedUsers []string `json:"activated_users"`
+}
+
+type PRStaleReminderData struct {
+	PRID          string    `json:"pull_request_id"`
+	ReviewerIDs   []string  `json:"reviewer_ids"`
+	InactiveSince time.Time `json:"inactive_since"`
+}
+
+// LeadID пустой, если лид команды не настроен
+type PRStaleEscalatedData struct {
+	PRID          string    `json:"pull_request_id"`
+	TeamName      string    `json:"team_name"`
+	LeadID        string    `json:"lead_id,omitempty"`
+	ReviewerIDs   []string  `json:"reviewer_ids"`
+	InactiveSince time.Time `json:"inactive_since"`
+}
+
+// события одного PR (или одной команды) доставляются строго по порядку
+func (e Event) AggregateID() string {
+	if e.PRID != "" {
+		return "pr:" + e.PRID
+	}
+	return "team:" + e.TeamName
+}
@@ -0,0 +1,65 @@
+package models
+
+import "fmt"
+
+const (
+	ImportFormatJSON = "json"
+	ImportFormatYAML = "yaml"
+	ImportFormatCSV  = "csv"
+)
+
+type ImportRequest struct {
+	Format string
+	Data   []byte
+	DryRun bool
+}
+
+// одна строка импорта — один участник команды;
+// Location указывает на место в исходном файле для отчёта об ошибках
+type ImportRow struct {
+	Location string
+	TeamName string
+	UserID   string
+	Username string
+	IsActive bool
+}
+
+type ImportRowError struct {
+	Location string `json:"location"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ImportValidationError содержит все найденные в файле ошибки,
+// а не только первую
+type ImportValidationError struct {
+	Errors []ImportRowError
+}
+
+func (e *ImportValidationError) Error() string {
+	return fmt.Sprintf("import file has %d errors", len(e.Errors))
+}
+
+type ImportFieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+type ImportUserChange struct {
+	UserID   string              `json:"user_id"`
+	TeamName string              `json:"team_name"`
+	Changes  []ImportFieldChange `json:"changes,omitempty"`
+}
+
+type ImportDiff struct {
+	TeamsCreated   []string           `json:"teams_created"`
+	UsersCreated   []ImportUserChange `json:"users_created"`
+	UsersUpdated   []ImportUserChange `json:"users_updated"`
+	UsersUnchanged int                `json:"users_unchanged"`
+}
+
+type ImportResult struct {
+	DryRun bool       `json:"dry_run"`
+	Diff   ImportDiff `json:"diff"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	JobPending = "PENDING"
+	JobRunning = "RUNNING"
+	JobDone    = "DONE"
+	JobFailed  = "FAILED"
+)
+
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	// для PENDING — время запуска, для RUNNING — конец аренды
+	RunAt      time.Time  `json:"run_at"`
+	UniqueKey  string     `json:"unique_key,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// JobFilter для /jobs/list; пустые поля не фильтруют
+type JobFilter struct {
+	Status string
+	Kind   string
+	Limit  int
+}
+
+type RetryJobRequest struct {
+	ID int64 `json:"id" validate:"required"`
+}
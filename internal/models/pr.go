@@ -7,6 +7,19 @@ const (
 	StatusMerged = "MERGED"
 )
 
+const (
+	ReassignReasonManual           = "manual"
+	ReassignReasonTeamDeactivation = "team_deactivation"
+	ReassignReasonStale            = "stale"
+	ReassignReasonRebalance        = "rebalance"
+)
+
+// вердикты ревьювера, приходящие из VCS
+const (
+	VerdictApproved         = "APPROVED"
+	VerdictChangesRequested = "CHANGES_REQUESTED"
+)
+
 type PullRequest struct {
 	ID                string     `json:"pull_request_id"`
 	Name              string     `json:"pull_request_name"`
@@ -26,6 +39,18 @@ type CreatePRRequest struct {
 type ReassignRequest struct {
 	PRID          string `json:"pull_request_id" validate:"required"`
 	OldReviewerID string `json:"old_reviewer_id" validate:"required"`
+	// причина переназначения для метрик; из API не принимается
+	Reason string `json:"-"`
+	// не трогать ревьювера, уже вынесшего вердикт (ErrReviewSubmitted);
+	// из API не принимается
+	PendingOnly bool `json:"-"`
+}
+
+// ReviewerMove — перенос одного назначения при выравнивании нагрузки
+type ReviewerMove struct {
+	PRID   string `json:"pull_request_id"`
+	FromID string `json:"from_reviewer_id"`
+	ToID   string `json:"to_reviewer_id"`
 }
 
 type MergePRRequest struct {
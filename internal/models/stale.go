@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// шаги обработки зависшего PR идут строго по порядку; после эскалации
+// PR больше не трогаем, пока на нём не появится новая активность
+const (
+	StaleStepRemind   = "remind"
+	StaleStepReassign = "reassign"
+	StaleStepEscalate = "escalate"
+)
+
+// StalePR — открытый PR без активности. LastActivity — создание PR,
+// последнее переназначение или вердикт, LastStep — последний шаг после этой активности
+type StalePR struct {
+	ID       string
+	Name     string
+	AuthorID string
+	TeamName string
+	// ревьюверы по давности назначения, первым — самый давний
+	Reviewers []string
+	// ревьюверы, уже вынесшие вердикт: их не переназначаем
+	Reviewed     []string
+	LastActivity time.Time
+	LastStep     string
+	LastStepAt   *time.Time
+}
+
+type StaleStep struct {
+	PRID          string
+	Step          string
+	ReviewerID    string
+	NewReviewerID string
+	LeadID        string
+	InactiveSince time.Time
+	CreatedAt     time.Time
+}
+
+// StalePolicy — порог простоя и лид по командам; команды без своих
+// настроек получают общий порог и эскалацию без лида
+type StalePolicy struct {
+	Threshold time.Duration
+	Teams     map[string]StaleTeamPolicy
+}
+
+type StaleTeamPolicy struct {
+	Threshold time.Duration
+	LeadID    string
+}
+
+func (p StalePolicy) Team(name string) StaleTeamPolicy {
+	team := p.Teams[name]
+	if team.Threshold <= 0 {
+		team.Threshold = p.Threshold
+	}
+	return team
+}
+
+// MinThreshold — самый короткий порог среди команд, чтобы не
+// выбирать из базы заведомо свежие PR
+func (p StalePolicy) MinThreshold() time.Duration {
+	least := p.Threshold
+	for _, team := range p.Teams {
+		if team.Threshold > 0 && team.Threshold < least {
+			least = team.Threshold
+		}
+	}
+	return least
+}
+
+type StaleRunResult struct {
+	Reminded   int `json:"reminded"`
+	Reassigned int `json:"reassigned"`
+	Escalated  int `json:"escalated"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type UserStats struct {
 	UserID          string   `json:"user_id"`
 	TeamName        string   `json:"team_name"`
@@ -7,3 +9,129 @@ type UserStats struct {
 	AssignmentCount int      `json:"assignment_count"`
 	AssignedPRs     []string `json:"assigned_prs"`
 }
+
+// StatsFilter ограничивает выборку полуинтервалом [From, To): ревью и
+// авторство считаются по дате создания PR, переназначения — по дате
+// переназначения; пустые поля не фильтруют
+type StatsFilter struct {
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	TeamName string     `json:"team_name,omitempty"`
+	Status   string     `json:"status,omitempty"`
+}
+
+type ReviewLoad struct {
+	OpenReviews   int `json:"open_reviews"`
+	MergedReviews int `json:"merged_reviews"`
+	AuthoredPRs   int `json:"authored_prs"`
+	ReassignedIn  int `json:"reassigned_in"`
+	ReassignedOut int `json:"reassigned_out"`
+}
+
+func (l *ReviewLoad) Add(other ReviewLoad) {
+	l.OpenReviews += other.OpenReviews
+	l.MergedReviews += other.MergedReviews
+	l.AuthoredPRs += other.AuthoredPRs
+	l.ReassignedIn += other.ReassignedIn
+	l.ReassignedOut += other.ReassignedOut
+}
+
+type UserReviewStats struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+	ReviewLoad
+}
+
+type TeamReviewStats struct {
+	TeamName string `json:"team_name"`
+	Members  int    `json:"members"`
+	ReviewLoad
+}
+
+const (
+	CycleTimeByTeam     = "team"
+	CycleTimeByAuthor   = "author"
+	CycleTimeByReviewer = "reviewer"
+)
+
+// CycleTimeFilter выбирает PR, смёрженные в [From, To); TeamName —
+// команда автора PR
+type CycleTimeFilter struct {
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	TeamName string     `json:"team_name,omitempty"`
+	GroupBy  string     `json:"group_by"`
+}
+
+// CycleTime — перцентили интервала в секундах по Count PR
+type CycleTime struct {
+	Count         int     `json:"count"`
+	MedianSeconds float64 `json:"median_seconds"`
+	P90Seconds    float64 `json:"p90_seconds"`
+	P99Seconds    float64 `json:"p99_seconds"`
+}
+
+// CycleTimeRow — строка агрегата из репозитория: WeekStart пустой
+// у итога по группе за всё окно. CycleTime — от создания PR до мёржа,
+// FirstReview — до первого вердикта, только по PR, где он есть
+type CycleTimeRow struct {
+	Key       string
+	WeekStart *time.Time
+	CycleTime
+	FirstReview CycleTime
+}
+
+type CycleTimeWeek struct {
+	WeekStart         time.Time `json:"week_start"`
+	TimeToMerge       CycleTime `json:"time_to_merge"`
+	TimeToFirstReview CycleTime `json:"time_to_first_review"`
+}
+
+type CycleTimeGroup struct {
+	Key               string          `json:"key"`
+	TimeToMerge       CycleTime       `json:"time_to_merge"`
+	TimeToFirstReview CycleTime       `json:"time_to_first_review"`
+	Weeks             []CycleTimeWeek `json:"weeks"`
+}
+
+const (
+	FairnessOverloaded  = "overloaded"
+	FairnessUnderloaded = "underloaded"
+)
+
+// FairnessRow — назначения участника команды, включая снятые при
+// переназначении, и его ожидаемая доля: по каждому PR команды назначения
+// делятся поровну между активными участниками, кроме автора, и теми, кто
+// был на PR ревьювером
+type FairnessRow struct {
+	TeamName    string
+	UserID      string
+	Username    string
+	IsActive    bool
+	Assignments int
+	Expected    float64
+}
+
+type MemberFairness struct {
+	UserID        string  `json:"user_id"`
+	Username      string  `json:"username"`
+	IsActive      bool    `json:"is_active"`
+	Assignments   int     `json:"assignments"`
+	Expected      float64 `json:"expected"`
+	Share         float64 `json:"share"`
+	ExpectedShare float64 `json:"expected_share"`
+	// отношение фактических назначений к ожидаемым; нет, если ожидаемых нет
+	LoadRatio *float64 `json:"load_ratio"`
+	Flag      string   `json:"flag,omitempty"`
+}
+
+type TeamFairness struct {
+	TeamName    string  `json:"team_name"`
+	Assignments int     `json:"assignments"`
+	Gini        float64 `json:"gini"`
+	// max/min load_ratio; нет, если кто-то из участников не получил ничего
+	MaxMinRatio *float64         `json:"max_min_ratio"`
+	Members     []MemberFairness `json:"members"`
+}
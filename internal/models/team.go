@@ -1,5 +1,21 @@
 package models
 
+import "time"
+
+// JobTeamDeactivation — задача очереди jobs, выполняющая асинхронную деактивацию
+const (
+	JobTeamDeactivation = "team.deactivate"
+	// JobTeamRebalance — выравнивание нагрузки во всех командах по расписанию
+	JobTeamRebalance = "team.rebalance"
+)
+
+const (
+	DeactivationPending = "PENDING"
+	DeactivationRunning = "RUNNING"
+	DeactivationDone    = "DONE"
+	DeactivationFailed  = "FAILED"
+)
+
 type Team struct {
 	Name    string       `json:"team_name" validate:"required"`
 	Members []TeamMember `json:"members" validate:"required,dive"`
@@ -19,3 +35,73 @@ type DeactivateTeamResponse struct {
 	DeactivatedUsers int `json:"deactivated_users"`
 	ReassignedPRs    int `json:"reassigned_prs"`
 }
+
+type ActivateTeamRequest struct {
+	TeamName string `json:"team_name" validate:"required"`
+	// перенести часть открытых ревью с остальных участников на вернувшихся
+	Rebalance bool `json:"rebalance"`
+}
+
+type ActivateTeamResponse struct {
+	ActivatedUsers []string       `json:"activated_users"`
+	Moves          []ReviewerMove `json:"moves"`
+}
+
+// RebalanceLimits ограничивает перенос назначений: не больше MaxMoves
+// переносов за раз (0 — без ограничения) и только пока разница нагрузок
+// донора и получателя не меньше MinGap
+type RebalanceLimits struct {
+	MaxMoves int
+	MinGap   int
+}
+
+type RebalanceTeamRequest struct {
+	TeamName string `json:"team_name" validate:"required"`
+	// только посчитать план, ничего не переназначая
+	DryRun bool `json:"dry_run"`
+	// ноль — значение из конфигурации
+	MaxMoves int `json:"max_moves" validate:"min=0"`
+	MinGap   int `json:"min_gap" validate:"omitempty,min=2"`
+}
+
+type RebalanceTeamResponse struct {
+	TeamName string         `json:"team_name"`
+	DryRun   bool           `json:"dry_run"`
+	Moves    []ReviewerMove `json:"moves"`
+	Load     []ReviewerLoad `json:"load"`
+}
+
+// ReviewerLoad — число открытых PR, где ревьювер ещё не вынес вердикт,
+// до и после выравнивания (для dry_run — по плану)
+type ReviewerLoad struct {
+	UserID string `json:"user_id"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// TeamDeactivation — асинхронная деактивация команды (POST /team/deactivate?async=true)
+type TeamDeactivation struct {
+	ID       int64                `json:"id"`
+	TeamName string               `json:"team_name"`
+	Status   string               `json:"status"`
+	Progress DeactivationProgress `json:"progress"`
+	// заполняется, когда операция завершена
+	Result     *DeactivateTeamResponse `json:"result,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	CreatedAt  time.Time               `json:"created_at"`
+	FinishedAt *time.Time              `json:"finished_at,omitempty"`
+}
+
+// DeactivationProgress считает PR из снимка, сделанного при старте;
+// reassigned и stuck — ревьюверы, которых удалось и не удалось заменить
+type DeactivationProgress struct {
+	TotalPRs     int `json:"total_prs"`
+	ProcessedPRs int `json:"processed_prs"`
+	Reassigned   int `json:"reassigned"`
+	Stuck        int `json:"stuck"`
+}
+
+// DeactivationJob — payload задачи JobTeamDeactivation
+type DeactivationJob struct {
+	DeactivationID int64 `json:"deactivation_id"`
+}
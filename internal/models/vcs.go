@@ -0,0 +1,55 @@
+package models
+
+import "fmt"
+
+const (
+	VCSProviderGitHub = "github"
+	VCSProviderGitLab = "gitlab"
+)
+
+const (
+	VCSActionOpen   = "open"
+	VCSActionMerge  = "merge"
+	VCSActionClose  = "close"
+	VCSActionReview = "review"
+	VCSActionIgnore = "ignore"
+)
+
+const (
+	VCSResultCreated  = "created"
+	VCSResultExists   = "exists"
+	VCSResultMerged   = "merged"
+	VCSResultReviewed = "reviewed"
+	VCSResultIgnored  = "ignored"
+)
+
+type VCSIdentity struct {
+	Provider string `json:"provider" validate:"required,oneof=github gitlab"`
+	Login    string `json:"login" validate:"required"`
+	UserID   string `json:"user_id" validate:"required"`
+}
+
+// VCSPullRequestEvent — событие PR/MR, уже переведённое из формата
+// конкретной VCS в действие над нашим PR
+type VCSPullRequestEvent struct {
+	Provider   string
+	Action     string
+	Repository string
+	Number     int64
+	Title      string
+	// пустой, если VCS не сообщила логин автора: тогда VCSActionOpen не
+	// заводит PR, а только находит уже заведённый
+	AuthorLogin string
+	// для VCSActionReview: кто и какой вердикт вынес
+	ReviewerLogin string
+	Verdict       string
+}
+
+func (e VCSPullRequestEvent) PRID() string {
+	return fmt.Sprintf("%s:%s#%d", e.Provider, e.Repository, e.Number)
+}
+
+type VCSEventResult struct {
+	Result string       `json:"result"`
+	PR     *PullRequest `json:"pr,omitempty"`
+}
@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// KindCleanup — задача очереди jobs, удаляющая доставленные события старше Retention
+const KindCleanup = "outbox.cleanup"
+
+// Sink получает события из outbox. Доставка "at least once": при ошибке любого
+// sink событие будет повторено для всех, поэтому обработчики должны быть
+// идемпотентны по Event.ID
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, event models.Event) error
+}
+
+type Dispatcher struct {
+	repo  repository.OutboxRepository
+	sinks []Sink
+	log   *slog.Logger
+
+	PollInterval time.Duration
+	BatchSize    int
+	Retention    time.Duration
+}
+
+func NewDispatcher(repo repository.OutboxRepository, log *slog.Logger, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{
+		repo:         repo,
+		sinks:        sinks,
+		log:          log.With("component", "outbox"),
+		PollInterval: 500 * time.Millisecond,
+		BatchSize:    100,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// пока пачки полные, разбираем очередь без ожидания тикера
+			for ctx.Err() == nil {
+				n, err := d.DispatchBatch(ctx)
+				if err != nil {
+					d.log.Error("outbox dispatch failed", "error", err)
+					break
+				}
+				if n < d.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	return d.repo.ProcessBatch(ctx, d.BatchSize, d.deliver)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, event models.Event) error {
+	for _, sink := range d.sinks {
+		if err := sink.Deliver(ctx, event); err != nil {
+			d.log.Warn("sink failed to accept event", "sink", sink.Name(), "event_id", event.ID, "type", event.Type, "error", err)
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Cleanup — обработчик задачи KindCleanup. Недоставленные события не
+// удаляются, сколько бы они ни ждали
+func (d *Dispatcher) Cleanup(ctx context.Context, _ models.Job) error {
+	n, err := d.repo.DeleteDispatched(ctx, time.Now().Add(-d.Retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		d.log.InfoContext(ctx, "dispatched outbox events deleted", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// fakeRepository повторяет контракт ProcessBatch: ошибка обработчика
+// оставляет событие и все следующие события того же агрегата в очереди
+type fakeRepository struct {
+	pending []models.Event
+	// deletedBefore — граница последнего вызова DeleteDispatched
+	deletedBefore time.Time
+}
+
+func (r *fakeRepository) ProcessBatch(ctx context.Context, limit int, handle func(ctx context.Context, event models.Event) error) (int, error) {
+	blocked := map[string]bool{}
+	var rest []models.Event
+	processed := 0
+	for i, ev := range r.pending {
+		if i >= limit || blocked[ev.AggregateID()] {
+			rest = append(rest, ev)
+			continue
+		}
+		if err := handle(ctx, ev); err != nil {
+			blocked[ev.AggregateID()] = true
+			rest = append(rest, ev)
+			continue
+		}
+		processed++
+	}
+	r.pending = rest
+	return processed, nil
+}
+
+func (r *fakeRepository) DeleteDispatched(_ context.Context, before time.Time) (int, error) {
+	r.deletedBefore = before
+	return 2, nil
+}
+
+type recordingSink struct {
+	name    string
+	fail    map[int64]bool
+	handled []int64
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Deliver(_ context.Context, event models.Event) error {
+	if s.fail[event.ID] {
+		return errors.New("sink unavailable")
+	}
+	s.handled = append(s.handled, event.ID)
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestDispatcher_DeliversToAllSinksInOrder(t *testing.T) {
+	repo := &fakeRepository{pending: []models.Event{
+		{ID: 1, PRID: "pr-1", Type: models.EventPRCreated},
+		{ID: 2, PRID: "pr-2", Type: models.EventPRCreated},
+		{ID: 3, PRID: "pr-1", Type: models.EventPRMerged},
+	}}
+	first := &recordingSink{name: "first"}
+	second := &recordingSink{name: "second"}
+
+	d := NewDispatcher(repo, testLogger(), first, second)
+	n, err := d.DispatchBatch(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []int64{1, 2, 3}, first.handled)
+	require.Equal(t, []int64{1, 2, 3}, second.handled)
+	require.Empty(t, repo.pending)
+}
+
+func TestDispatcher_FailedSinkKeepsAggregateOrder(t *testing.T) {
+	repo := &fakeRepository{pending: []models.Event{
+		{ID: 1, PRID: "pr-1", Type: models.EventPRCreated},
+		{ID: 2, PRID: "pr-2", Type: models.EventPRCreated},
+		{ID: 3, PRID: "pr-1", Type: models.EventPRMerged},
+	}}
+	sink := &recordingSink{name: "webhook", fail: map[int64]bool{1: true}}
+
+	d := NewDispatcher(repo, testLogger(), sink)
+	n, err := d.DispatchBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int64{2}, sink.handled)
+
+	sink.fail = nil
+	n, err = d.DispatchBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []int64{2, 1, 3}, sink.handled)
+}
+
+func TestDispatcher_CleanupUsesRetention(t *testing.T) {
+	repo := &fakeRepository{}
+	d := NewDispatcher(repo, testLogger())
+	d.Retention = time.Hour
+
+	require.NoError(t, d.Cleanup(context.Background(), models.Job{Kind: KindCleanup}))
+	require.WithinDuration(t, time.Now().Add(-time.Hour), repo.deletedBefore, time.Minute)
+}
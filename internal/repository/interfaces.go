@@ -3,18 +3,31 @@ package repository
 import (
 	"avito-pr-service/internal/models"
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrLeaseLost — задачу или доставку вебхука после конца аренды забрал
+// другой воркер, и её состояние принадлежит уже ему
+var ErrLeaseLost = errors.New("lease lost")
+
 type TeamRepository interface {
 	CreateTeam(ctx context.Context, team models.Team) error
 	GetTeam(ctx context.Context, name string) (models.Team, error)
+	ListTeams(ctx context.Context) ([]models.Team, error)
+	// ImportTeams применяет строки импорта в одной транзакции; при dryRun
+	// транзакция откатывается, но diff считается так же
+	ImportTeams(ctx context.Context, rows []models.ImportRow, dryRun bool) (models.ImportDiff, error)
 }
 
 type UserRepository interface {
 	SetActive(ctx context.Context, userID string, isActive bool) error
 	GetUser(ctx context.Context, userID string) (models.User, error)
-	DeactivateTeam(ctx context.Context, teamName string) (int, error)
+	// reassignedPRs попадает в событие team.deactivated
+	DeactivateTeam(ctx context.Context, teamName string, reassignedPRs int) (int, error)
+	// ActivateTeam включает участников, активных до последней деактивации,
+	// и возвращает их id
+	ActivateTeam(ctx context.Context, teamName string) ([]string, error)
 }
 
 type PRRepository interface {
@@ -22,7 +35,122 @@ type PRRepository interface {
 	GetPR(ctx context.Context, prID string) (models.PullRequest, error)
 	MergePR(ctx context.Context, prID string) (*time.Time, error)
 	ReassignReviewer(ctx context.Context, prID, oldUID, newUID string) error
+	// MovePendingReviewer — ReassignReviewer, который возвращает
+	// ErrReviewSubmitted, если oldUID уже вынес вердикт
+	MovePendingReviewer(ctx context.Context, prID, oldUID, newUID string) error
+	// SetVerdict запоминает вердикт назначенного ревьювера открытого PR
+	SetVerdict(ctx context.Context, prID, userID, verdict string) error
 	GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequest, error)
+	// Each* не накапливают результат: fn вызывается для каждой строки,
+	// ошибка fn прерывает чтение
+	EachPRByReviewer(ctx context.Context, userID string, fn func(models.PullRequest) error) error
 	GetUserStats(ctx context.Context) ([]models.UserStats, error)
+	EachUserStats(ctx context.Context, fn func(models.UserStats) error) error
 	GetOpenPRsWithTeamReviewers(ctx context.Context, teamName string) ([]models.PullRequest, error)
+	// GetReviewedAssignments возвращает для открытых PR ревьюверов из
+	// команды teamName, уже вынесших вердикт: id PR -> id ревьюверов
+	GetReviewedAssignments(ctx context.Context, teamName string) (map[string][]string, error)
+	CountOpenPRsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+type StatsRepository interface {
+	// GetReviewStats возвращает нагрузку всех пользователей (или одной
+	// команды), включая тех, у кого в окне ничего не было
+	GetReviewStats(ctx context.Context, filter models.StatsFilter) ([]models.UserReviewStats, error)
+	EachReviewStats(ctx context.Context, filter models.StatsFilter, fn func(models.UserReviewStats) error) error
+	// GetCycleTimes возвращает перцентили по неделям и итог по каждой группе
+	GetCycleTimes(ctx context.Context, filter models.CycleTimeFilter) ([]models.CycleTimeRow, error)
+	// GetFairness учитывает PR, созданные в окне; Status фильтра не используется
+	GetFairness(ctx context.Context, filter models.StatsFilter) ([]models.FairnessRow, error)
+}
+
+type StaleRepository interface {
+	// FindStalePRs возвращает открытые PR, у которых ни активности, ни шагов
+	// не было с inactiveBefore; PR после эскалации не возвращаются
+	FindStalePRs(ctx context.Context, inactiveBefore time.Time) ([]models.StalePR, error)
+	// RecordStep пишет шаг и, если event не nil, событие в одной транзакции
+	RecordStep(ctx context.Context, step models.StaleStep, event *models.Event) error
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	// EnqueueDeliveries идемпотентен по eventID: возвращает число новых доставок
+	EnqueueDeliveries(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	// MarkDelivered и MarkFailed принимают доставку, как её вернул
+	// ClaimDueDeliveries, и возвращают ErrLeaseLost, если её уже обработал
+	// другой воркер
+	MarkDelivered(ctx context.Context, delivery models.WebhookDelivery) error
+	MarkFailed(ctx context.Context, delivery models.WebhookDelivery, attempts int, nextAttemptAt time.Time, lastErr string, dead bool) error
+	// DeleteDelivered удаляет доставленные до before доставки и возвращает их число
+	DeleteDelivered(ctx context.Context, before time.Time) (int, error)
+	ListDeadLetters(ctx context.Context, limit int) ([]models.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, id int64) error
+}
+
+type JobRepository interface {
+	// Enqueue возвращает false, если задача с таким UniqueKey уже есть
+	Enqueue(ctx context.Context, job models.Job) (models.Job, bool, error)
+	// ClaimDue забирает в аренду задачи перечисленных видов и увеличивает
+	// attempts; задачи RUNNING с истёкшей арендой (упавший воркер) забираются
+	// снова, а исчерпавшие попытки становятся FAILED
+	ClaimDue(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]models.Job, error)
+	// MarkDone и MarkFailed принимают задачу, как её вернул ClaimDue, и
+	// возвращают ErrLeaseLost, если её уже забрал другой воркер
+	MarkDone(ctx context.Context, job models.Job) error
+	MarkFailed(ctx context.Context, job models.Job, attempts int, runAt time.Time, lastErr string, dead bool) error
+	List(ctx context.Context, filter models.JobFilter) ([]models.Job, error)
+	Get(ctx context.Context, id int64) (models.Job, error)
+	Retry(ctx context.Context, id int64) error
+	DeleteFinished(ctx context.Context, before time.Time) (int, error)
+}
+
+type OutboxRepository interface {
+	ProcessBatch(ctx context.Context, limit int, handle func(ctx context.Context, event models.Event) error) (int, error)
+	// DeleteDispatched удаляет доставленные до before события и возвращает их число
+	DeleteDispatched(ctx context.Context, before time.Time) (int, error)
+}
+
+// EventLogRepository читает outbox как журнал событий, независимо от того,
+// доставлены ли они
+type EventLogRepository interface {
+	// ListEvents возвращает события по возрастанию id, начиная после afterID
+	ListEvents(ctx context.Context, afterID int64, limit int) ([]models.Event, error)
+	GetEvents(ctx context.Context, ids []int64) ([]models.Event, error)
+	// LastEventID — наибольший id в журнале, 0 для пустого
+	LastEventID(ctx context.Context) (int64, error)
+	// DeletedUpTo — наибольший id, удалённый из журнала по сроку хранения,
+	// 0 если ничего не удалялось
+	DeletedUpTo(ctx context.Context) (int64, error)
+}
+
+type VCSIdentityRepository interface {
+	SetIdentity(ctx context.Context, identity models.VCSIdentity) error
+	ResolveUser(ctx context.Context, provider, login string) (string, error)
+}
+
+type ArchiveRepository interface {
+	Dump(ctx context.Context) (models.Archive, error)
+	// Restore загружает архив в пустую базу одной транзакцией
+	Restore(ctx context.Context, archive models.Archive) error
+}
+
+// DeactivationRepository хранит асинхронные деактивации команд и их прогресс
+type DeactivationRepository interface {
+	// Create создаёт операцию и задачу для неё в одной транзакции; если у
+	// команды уже идёт деактивация, возвращает её и false
+	Create(ctx context.Context, teamName string, maxAttempts int) (models.TeamDeactivation, bool, error)
+	Get(ctx context.Context, id int64) (models.TeamDeactivation, error)
+	// InProgress сообщает, идёт ли у команды асинхронная деактивация
+	InProgress(ctx context.Context, teamName string) (bool, error)
+	// Start запоминает список PR и переводит операцию в RUNNING; для уже
+	// начатой операции ничего не делает
+	Start(ctx context.Context, id int64, prIDs []string) error
+	PendingPRs(ctx context.Context, id int64) ([]string, error)
+	MarkPRProcessed(ctx context.Context, id int64, prID string, reassigned, stuck int) error
+	// Finish деактивирует пользователей команды и завершает операцию
+	Finish(ctx context.Context, id int64) (models.TeamDeactivation, error)
+	Fail(ctx context.Context, id int64, reason string) error
 }
@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type archiveRepository struct {
+	db *pgxpool.Pool
+}
+
+func newArchiveRepository(db *pgxpool.Pool) repository.ArchiveRepository {
+	return &archiveRepository{db: db}
+}
+
+// поля структур архива перечислены в том же порядке, что и колонки в запросах
+func collect[T any](ctx context.Context, tx pgx.Tx, table, query string) ([]T, error) {
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", table, err)
+	}
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByPos[T])
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", table, err)
+	}
+	return items, nil
+}
+
+func (r *archiveRepository) Dump(ctx context.Context) (models.Archive, error) {
+	var a models.Archive
+
+	// один снимок на все таблицы, иначе архив может нарушить собственные ссылки
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return a, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if a.Teams, err = collect[models.ArchiveTeam](ctx, tx, "teams", `
+		SELECT name FROM teams ORDER BY name
+	`); err != nil {
+		return a, err
+	}
+	if a.Users, err = collect[models.User](ctx, tx, "users", `
+		SELECT user_id, username, team_name, is_active FROM users ORDER BY user_id
+	`); err != nil {
+		return a, err
+	}
+	if a.PullRequests, err = collect[models.ArchivePullRequest](ctx, tx, "pull_requests", `
+		SELECT id, name, author_id, status, created_at, merged_at FROM pull_requests ORDER BY created_at, id
+	`); err != nil {
+		return a, err
+	}
+	if a.Reviewers, err = collect[models.ArchiveReviewer](ctx, tx, "pr_reviewers", `
+		SELECT pr_id, user_id, COALESCE(verdict, ''), verdict_at FROM pr_reviewers ORDER BY pr_id, user_id
+	`); err != nil {
+		return a, err
+	}
+	if a.Reassignments, err = collect[models.ArchiveReassignment](ctx, tx, "reviewer_reassignments", `
+		SELECT pr_id, old_reviewer_id, new_reviewer_id, reassigned_at, COALESCE(verdict, ''), verdict_at
+		FROM reviewer_reassignments ORDER BY id
+	`); err != nil {
+		return a, err
+	}
+	if a.VCSIdentities, err = collect[models.VCSIdentity](ctx, tx, "vcs_identities", `
+		SELECT provider, login, user_id FROM vcs_identities ORDER BY provider, login
+	`); err != nil {
+		return a, err
+	}
+	if a.Webhooks, err = collect[models.ArchiveWebhook](ctx, tx, "webhook_subscriptions", `
+		SELECT id, url, secret, event_types, is_active, created_at FROM webhook_subscriptions ORDER BY id
+	`); err != nil {
+		return a, err
+	}
+	if a.Snapshots, err = collect[models.ArchiveMemberSnapshot](ctx, tx, "team_member_snapshots", `
+		SELECT team_name, user_id, was_active, created_at FROM team_member_snapshots ORDER BY team_name, user_id
+	`); err != nil {
+		return a, err
+	}
+	if a.StaleSteps, err = collect[models.ArchiveStaleStep](ctx, tx, "stale_pr_steps", `
+		SELECT pr_id, step, COALESCE(reviewer_id, ''), COALESCE(new_reviewer_id, ''), COALESCE(lead_id, ''), inactive_since, created_at
+		FROM stale_pr_steps ORDER BY id
+	`); err != nil {
+		return a, err
+	}
+
+	return a, tx.Commit(ctx)
+}
+
+func (r *archiveRepository) Restore(ctx context.Context, a models.Archive) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// блокировка не даёт двум восстановлениям или живому трафику
+	// проскочить между проверкой на пустоту и вставкой
+	_, err = tx.Exec(ctx, `
+		LOCK TABLE teams, users, pull_requests, pr_reviewers, reviewer_reassignments, vcs_identities, webhook_subscriptions,
+			team_member_snapshots, stale_pr_steps IN EXCLUSIVE MODE
+	`)
+	if err != nil {
+		return fmt.Errorf("lock tables: %w", err)
+	}
+
+	var notEmpty bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM teams)
+			OR EXISTS (SELECT 1 FROM users)
+			OR EXISTS (SELECT 1 FROM pull_requests)
+			OR EXISTS (SELECT 1 FROM webhook_subscriptions)
+	`).Scan(&notEmpty)
+	if err != nil {
+		return fmt.Errorf("check empty: %w", err)
+	}
+	if notEmpty {
+		return models.ErrDatabaseNotEmpty
+	}
+
+	copies := []struct {
+		table   string
+		columns []string
+		rows    [][]any
+	}{
+		{"teams", []string{"name"}, rowsOf(a.Teams, func(t models.ArchiveTeam) []any {
+			return []any{t.Name}
+		})},
+		{"users", []string{"user_id", "username", "team_name", "is_active"}, rowsOf(a.Users, func(u models.User) []any {
+			return []any{u.UserID, u.Username, u.TeamName, u.IsActive}
+		})},
+		{"pull_requests", []string{"id", "name", "author_id", "status", "created_at", "merged_at"}, rowsOf(a.PullRequests, func(pr models.ArchivePullRequest) []any {
+			return []any{pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt}
+		})},
+		{"pr_reviewers", []string{"pr_id", "user_id", "verdict", "verdict_at"}, rowsOf(a.Reviewers, func(r models.ArchiveReviewer) []any {
+			return []any{r.PRID, r.UserID, nullIfEmpty(r.Verdict), r.VerdictAt}
+		})},
+		{"reviewer_reassignments", []string{"pr_id", "old_reviewer_id", "new_reviewer_id", "reassigned_at", "verdict", "verdict_at"}, rowsOf(a.Reassignments, func(r models.ArchiveReassignment) []any {
+			return []any{r.PRID, r.OldReviewerID, r.NewReviewerID, r.ReassignedAt, nullIfEmpty(r.Verdict), r.VerdictAt}
+		})},
+		{"vcs_identities", []string{"provider", "login", "user_id"}, rowsOf(a.VCSIdentities, func(id models.VCSIdentity) []any {
+			return []any{id.Provider, id.Login, id.UserID}
+		})},
+		{"webhook_subscriptions", []string{"id", "url", "secret", "event_types", "is_active", "created_at"}, rowsOf(a.Webhooks, func(wh models.ArchiveWebhook) []any {
+			return []any{wh.ID, wh.URL, wh.Secret, wh.EventTypes, wh.IsActive, wh.CreatedAt}
+		})},
+		{"team_member_snapshots", []string{"team_name", "user_id", "was_active", "created_at"}, rowsOf(a.Snapshots, func(s models.ArchiveMemberSnapshot) []any {
+			return []any{s.TeamName, s.UserID, s.WasActive, s.CreatedAt}
+		})},
+		{"stale_pr_steps", []string{"pr_id", "step", "reviewer_id", "new_reviewer_id", "lead_id", "inactive_since", "created_at"}, rowsOf(a.StaleSteps, func(s models.ArchiveStaleStep) []any {
+			return []any{s.PRID, s.Step, nullIfEmpty(s.ReviewerID), nullIfEmpty(s.NewReviewerID), nullIfEmpty(s.LeadID), s.InactiveSince, s.CreatedAt}
+		})},
+	}
+	for _, c := range copies {
+		if len(c.rows) == 0 {
+			continue
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{c.table}, c.columns, pgx.CopyFromRows(c.rows)); err != nil {
+			return fmt.Errorf("copy %s: %w", c.table, err)
+		}
+	}
+
+	// id подписок восстановлены как есть, сдвигаем последовательность за них
+	_, err = tx.Exec(ctx, `
+		SELECT setval(pg_get_serial_sequence('webhook_subscriptions', 'id'), COALESCE(MAX(id), 1), MAX(id) IS NOT NULL)
+		FROM webhook_subscriptions
+	`)
+	if err != nil {
+		return fmt.Errorf("reset webhook sequence: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func rowsOf[T any](items []T, row func(T) []any) [][]any {
+	rows := make([][]any, len(items))
+	for i, item := range items {
+		rows[i] = row(item)
+	}
+	return rows
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestArchiveRepository_Integration_DumpRestore(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := newArchiveRepository(dbPool)
+	ctx := context.Background()
+
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	merged := created.Add(time.Hour)
+	want := models.Archive{
+		Teams: []models.ArchiveTeam{{Name: "backend"}},
+		Users: []models.User{
+			{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+			{UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: false},
+		},
+		PullRequests: []models.ArchivePullRequest{
+			{ID: "pr-1", Name: "Add search", AuthorID: "u1", Status: models.StatusMerged, CreatedAt: created, MergedAt: &merged},
+		},
+		Reviewers:     []models.ArchiveReviewer{{PRID: "pr-1", UserID: "u2", Verdict: models.VerdictApproved, VerdictAt: &merged}},
+		Reassignments: []models.ArchiveReassignment{{PRID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u2", ReassignedAt: created}},
+		VCSIdentities: []models.VCSIdentity{{Provider: models.VCSProviderGitHub, Login: "alice", UserID: "u1"}},
+		Webhooks: []models.ArchiveWebhook{
+			{ID: 7, URL: "https://bot.example.com/hook", Secret: "0123456789abcdef", EventTypes: []string{"pr.merged"}, IsActive: true, CreatedAt: created},
+		},
+		Snapshots:  []models.ArchiveMemberSnapshot{{TeamName: "backend", UserID: "u2", WasActive: true, CreatedAt: created}},
+		StaleSteps: []models.ArchiveStaleStep{{PRID: "pr-1", Step: models.StaleStepEscalate, LeadID: "u1", InactiveSince: created, CreatedAt: created}},
+	}
+
+	require.NoError(t, repo.Restore(ctx, want))
+
+	got, err := repo.Dump(ctx)
+	require.NoError(t, err)
+	require.Len(t, got.PullRequests, 1)
+	assert.True(t, got.PullRequests[0].CreatedAt.Equal(created))
+	assert.True(t, got.PullRequests[0].MergedAt.Equal(merged))
+	got.PullRequests = want.PullRequests
+	require.Len(t, got.Reassignments, 1)
+	assert.True(t, got.Reassignments[0].ReassignedAt.Equal(created))
+	got.Reassignments[0].ReassignedAt = created
+	require.Len(t, got.Webhooks, 1)
+	assert.True(t, got.Webhooks[0].CreatedAt.Equal(created))
+	got.Webhooks[0].CreatedAt = created
+	require.Len(t, got.Snapshots, 1)
+	assert.True(t, got.Snapshots[0].CreatedAt.Equal(created))
+	got.Snapshots[0].CreatedAt = created
+	require.Len(t, got.StaleSteps, 1)
+	assert.True(t, got.StaleSteps[0].InactiveSince.Equal(created))
+	assert.True(t, got.StaleSteps[0].CreatedAt.Equal(created))
+	got.StaleSteps[0].InactiveSince, got.StaleSteps[0].CreatedAt = created, created
+	assert.Equal(t, want, got)
+
+	// повторное восстановление в непустую базу запрещено
+	assert.ErrorIs(t, repo.Restore(ctx, want), models.ErrDatabaseNotEmpty)
+
+	// новые подписки получают id после восстановленных
+	sub, err := newWebhookRepository(dbPool).CreateSubscription(ctx, models.WebhookSubscription{
+		URL: "https://other.example.com", Secret: "fedcba9876543210", EventTypes: []string{"pr.created"}, IsActive: true,
+	})
+	require.NoError(t, err)
+	assert.Greater(t, sub.ID, int64(7))
+}
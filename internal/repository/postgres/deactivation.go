@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type deactivationRepository struct {
+	db *pgxpool.Pool
+}
+
+func newDeactivationRepository(db *pgxpool.Pool) repository.DeactivationRepository {
+	return &deactivationRepository{db: db}
+}
+
+const deactivationColumns = `id, team_name, status, total_prs, processed_prs, reassigned, stuck,
+               deactivated_users, COALESCE(error, ''), created_at, finished_at`
+
+func scanDeactivation(row pgx.Row) (models.TeamDeactivation, error) {
+	var (
+		d           models.TeamDeactivation
+		deactivated *int
+	)
+	err := row.Scan(&d.ID, &d.TeamName, &d.Status, &d.Progress.TotalPRs, &d.Progress.ProcessedPRs,
+		&d.Progress.Reassigned, &d.Progress.Stuck, &deactivated, &d.Error, &d.CreatedAt, &d.FinishedAt)
+	if err == nil && d.Status == models.DeactivationDone && deactivated != nil {
+		d.Result = &models.DeactivateTeamResponse{DeactivatedUsers: *deactivated, ReassignedPRs: d.Progress.Reassigned}
+	}
+	return d, err
+}
+
+func (r *deactivationRepository) Create(ctx context.Context, teamName string, maxAttempts int) (models.TeamDeactivation, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return models.TeamDeactivation{}, false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	d, err := scanDeactivation(tx.QueryRow(ctx, `
+        INSERT INTO team_deactivations (team_name)
+        VALUES ($1)
+        ON CONFLICT (team_name) WHERE status IN ('PENDING', 'RUNNING') DO NOTHING
+        RETURNING `+deactivationColumns, teamName))
+	if errors.Is(err, pgx.ErrNoRows) {
+		d, err = scanDeactivation(tx.QueryRow(ctx, `
+            SELECT `+deactivationColumns+`
+            FROM team_deactivations
+            WHERE team_name = $1 AND status IN ('PENDING', 'RUNNING')
+        `, teamName))
+		if err != nil {
+			return models.TeamDeactivation{}, false, fmt.Errorf("get active deactivation: %w", err)
+		}
+		return d, false, nil
+	}
+	if err != nil {
+		return models.TeamDeactivation{}, false, fmt.Errorf("insert deactivation: %w", err)
+	}
+
+	// задача ставится в той же транзакции: операция без задачи осталась бы PENDING навсегда
+	payload, err := json.Marshal(models.DeactivationJob{DeactivationID: d.ID})
+	if err != nil {
+		return models.TeamDeactivation{}, false, fmt.Errorf("encode job payload: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO jobs (kind, payload, max_attempts)
+        VALUES ($1, $2, $3)
+    `, models.JobTeamDeactivation, payload, maxAttempts)
+	if err != nil {
+		return models.TeamDeactivation{}, false, fmt.Errorf("enqueue deactivation job: %w", err)
+	}
+
+	return d, true, tx.Commit(ctx)
+}
+
+func (r *deactivationRepository) Get(ctx context.Context, id int64) (models.TeamDeactivation, error) {
+	d, err := scanDeactivation(r.db.QueryRow(ctx, `SELECT `+deactivationColumns+` FROM team_deactivations WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.TeamDeactivation{}, models.ErrDeactivationNotFound
+	}
+	if err != nil {
+		return models.TeamDeactivation{}, fmt.Errorf("get deactivation: %w", err)
+	}
+	return d, nil
+}
+
+func (r *deactivationRepository) Start(ctx context.Context, id int64, prIDs []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, `
+        UPDATE team_deactivations
+        SET status = 'RUNNING', total_prs = $2
+        WHERE id = $1 AND status = 'PENDING'
+    `, id, len(prIDs))
+	if err != nil {
+		return fmt.Errorf("start deactivation: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO team_deactivation_prs (deactivation_id, pr_id)
+        SELECT $1, unnest($2::text[])
+    `, id, prIDs)
+	if err != nil {
+		return fmt.Errorf("insert deactivation PRs: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *deactivationRepository) PendingPRs(ctx context.Context, id int64) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT pr_id
+        FROM team_deactivation_prs
+        WHERE deactivation_id = $1 AND NOT processed
+        ORDER BY pr_id
+    `, id)
+	if err != nil {
+		return nil, fmt.Errorf("query deactivation PRs: %w", err)
+	}
+	prIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("scan deactivation PRs: %w", err)
+	}
+	return prIDs, nil
+}
+
+// счётчики меняются только при первой отметке PR, повторная ничего не делает
+func (r *deactivationRepository) MarkPRProcessed(ctx context.Context, id int64, prID string, reassigned, stuck int) error {
+	_, err := r.db.Exec(ctx, `
+        WITH marked AS (
+            UPDATE team_deactivation_prs
+            SET processed = true
+            WHERE deactivation_id = $1 AND pr_id = $2 AND NOT processed
+            RETURNING deactivation_id
+        )
+        UPDATE team_deactivations d
+        SET processed_prs = d.processed_prs + 1, reassigned = d.reassigned + $3, stuck = d.stuck + $4
+        FROM marked
+        WHERE d.id = marked.deactivation_id
+    `, id, prID, reassigned, stuck)
+	if err != nil {
+		return fmt.Errorf("mark deactivation PR: %w", err)
+	}
+	return nil
+}
+
+func (r *deactivationRepository) InProgress(ctx context.Context, teamName string) (bool, error) {
+	var active bool
+	err := r.db.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM team_deactivations WHERE team_name = $1 AND status IN ('PENDING', 'RUNNING'))
+    `, teamName).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("check active deactivation: %w", err)
+	}
+	return active, nil
+}
+
+func (r *deactivationRepository) Finish(ctx context.Context, id int64) (models.TeamDeactivation, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return models.TeamDeactivation{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		teamName, status string
+		reassigned       int
+	)
+	err = tx.QueryRow(ctx, `SELECT team_name, status, reassigned FROM team_deactivations WHERE id = $1 FOR UPDATE`, id).Scan(&teamName, &status, &reassigned)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.TeamDeactivation{}, models.ErrDeactivationNotFound
+	}
+	if err != nil {
+		return models.TeamDeactivation{}, fmt.Errorf("lock deactivation: %w", err)
+	}
+
+	// пользователи выключаются вместе со сменой статуса, поэтому повторный
+	// Finish после падения не отправит второе событие team.deactivated
+	if status == models.DeactivationRunning {
+		deactivated, err := deactivateTeamUsers(ctx, tx, teamName, reassigned)
+		if err != nil {
+			return models.TeamDeactivation{}, fmt.Errorf("deactivate users: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+            UPDATE team_deactivations
+            SET status = 'DONE', deactivated_users = $2, finished_at = NOW()
+            WHERE id = $1
+        `, id, deactivated)
+		if err != nil {
+			return models.TeamDeactivation{}, fmt.Errorf("finish deactivation: %w", err)
+		}
+	}
+
+	d, err := scanDeactivation(tx.QueryRow(ctx, `SELECT `+deactivationColumns+` FROM team_deactivations WHERE id = $1`, id))
+	if err != nil {
+		return models.TeamDeactivation{}, fmt.Errorf("get deactivation: %w", err)
+	}
+	return d, tx.Commit(ctx)
+}
+
+func (r *deactivationRepository) Fail(ctx context.Context, id int64, reason string) error {
+	_, err := r.db.Exec(ctx, `
+        UPDATE team_deactivations
+        SET status = 'FAILED', error = $2, finished_at = NOW()
+        WHERE id = $1 AND status IN ('PENDING', 'RUNNING')
+    `, id, reason)
+	if err != nil {
+		return fmt.Errorf("fail deactivation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDeactivationRepository_Integration_Lifecycle(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	ctx := context.Background()
+	repo := newDeactivationRepository(dbPool)
+
+	d, created, err := repo.Create(ctx, "team1", 5)
+	require.NoError(t, err)
+	require.True(t, created)
+	assert.Equal(t, models.DeactivationPending, d.Status)
+
+	inProgress, err := repo.InProgress(ctx, "team1")
+	require.NoError(t, err)
+	assert.True(t, inProgress)
+	// синхронная деактивация не идёт параллельно асинхронной
+	_, err = newUserRepository(dbPool).DeactivateTeam(ctx, "team1", 0)
+	assert.Equal(t, models.ErrTeamDeactivating, err)
+
+	// повторный запрос возвращает ту же операцию и не ставит вторую задачу
+	again, created, err := repo.Create(ctx, "team1", 5)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, d.ID, again.ID)
+
+	jobs, err := newJobRepository(dbPool).List(ctx, models.JobFilter{Kind: models.JobTeamDeactivation, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.JSONEq(t, fmt.Sprintf(`{"deactivation_id":%d}`, d.ID), string(jobs[0].Payload))
+	assert.Equal(t, 5, jobs[0].MaxAttempts)
+
+	require.NoError(t, repo.Start(ctx, d.ID, []string{"pr-1", "pr-2"}))
+	// повторный Start после падения снимок не меняет
+	require.NoError(t, repo.Start(ctx, d.ID, []string{"pr-3"}))
+
+	pending, err := repo.PendingPRs(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pr-1", "pr-2"}, pending)
+
+	require.NoError(t, repo.MarkPRProcessed(ctx, d.ID, "pr-1", 2, 1))
+	require.NoError(t, repo.MarkPRProcessed(ctx, d.ID, "pr-1", 2, 1))
+
+	got, err := repo.Get(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeactivationRunning, got.Status)
+	assert.Equal(t, models.DeactivationProgress{TotalPRs: 2, ProcessedPRs: 1, Reassigned: 2, Stuck: 1}, got.Progress)
+	assert.Nil(t, got.Result)
+
+	pending, err = repo.PendingPRs(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pr-2"}, pending)
+
+	require.NoError(t, repo.MarkPRProcessed(ctx, d.ID, "pr-2", 0, 0))
+	done, err := repo.Finish(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeactivationDone, done.Status)
+	require.NotNil(t, done.Result)
+	assert.Equal(t, models.DeactivateTeamResponse{DeactivatedUsers: 4, ReassignedPRs: 2}, *done.Result)
+	assert.NotNil(t, done.FinishedAt)
+
+	// повторный Finish не шлёт второе событие
+	_, err = repo.Finish(ctx, d.ID)
+	require.NoError(t, err)
+	var events int
+	require.NoError(t, dbPool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_events WHERE event_type = $1`, models.EventTeamDeactivated).Scan(&events))
+	assert.Equal(t, 1, events)
+
+	// завершённая операция не мешает начать новую
+	next, created, err := repo.Create(ctx, "team1", 5)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.NotEqual(t, d.ID, next.ID)
+}
+
+func TestDeactivationRepository_Integration_Fail(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	ctx := context.Background()
+	repo := newDeactivationRepository(dbPool)
+
+	d, _, err := repo.Create(ctx, "team1", 5)
+	require.NoError(t, err)
+	require.NoError(t, repo.Fail(ctx, d.ID, "db is down"))
+
+	got, err := repo.Get(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeactivationFailed, got.Status)
+	assert.Equal(t, "db is down", got.Error)
+
+	_, err = repo.Get(ctx, 9999)
+	require.ErrorIs(t, err, models.ErrDeactivationNotFound)
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type jobRepository struct {
+	db *pgxpool.Pool
+}
+
+func newJobRepository(db *pgxpool.Pool) repository.JobRepository {
+	return &jobRepository{db: db}
+}
+
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, run_at,
+               COALESCE(unique_key, ''), COALESCE(last_error, ''), created_at, finished_at`
+
+func scanJob(row pgx.Row) (models.Job, error) {
+	var j models.Job
+	err := row.Scan(&j.ID, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.UniqueKey, &j.LastError, &j.CreatedAt, &j.FinishedAt)
+	return j, err
+}
+
+func (r *jobRepository) Enqueue(ctx context.Context, job models.Job) (models.Job, bool, error) {
+	payload := job.Payload
+	if payload == nil {
+		payload = []byte(`{}`)
+	}
+	created, err := scanJob(r.db.QueryRow(ctx, `
+        INSERT INTO jobs (kind, payload, max_attempts, run_at, unique_key)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+        ON CONFLICT (unique_key) DO NOTHING
+        RETURNING `+jobColumns,
+		job.Kind, payload, job.MaxAttempts, job.RunAt, job.UniqueKey))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Job{}, false, nil
+	}
+	if err != nil {
+		return models.Job{}, false, fmt.Errorf("enqueue job: %w", err)
+	}
+	return created, true, nil
+}
+
+// как и доставки вебхуков, задачи забираются с арендой: run_at сдвигается
+// на конец аренды, и если воркер упал, задачу заберёт другой. Виды задач,
+// которых реплика не знает (например, при выкатке новой версии), не трогаем
+func (r *jobRepository) ClaimDue(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]models.Job, error) {
+	// упавший воркер тоже тратит попытку: задача, на которой воркеры падают
+	// раз за разом, после max_attempts становится FAILED, а не крутится вечно
+	_, err := r.db.Exec(ctx, `
+        UPDATE jobs
+        SET status = 'FAILED', finished_at = NOW(),
+            last_error = COALESCE(last_error || '; ', '') || 'lease expired'
+        WHERE status = 'RUNNING' AND run_at <= NOW() AND attempts >= max_attempts AND kind = ANY($1)
+    `, kinds)
+	if err != nil {
+		return nil, fmt.Errorf("fail expired jobs: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+        UPDATE jobs j
+        SET status = 'RUNNING', attempts = j.attempts + 1, run_at = NOW() + make_interval(secs => $2)
+        FROM (
+            SELECT id FROM jobs
+            WHERE (status = 'PENDING' OR (status = 'RUNNING' AND attempts < max_attempts))
+              AND run_at <= NOW() AND kind = ANY($3)
+            ORDER BY run_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        ) due
+        WHERE j.id = due.id
+        RETURNING j.id, j.kind, j.payload, j.status, j.attempts, j.max_attempts, j.run_at,
+                  COALESCE(j.unique_key, ''), COALESCE(j.last_error, ''), j.created_at, j.finished_at
+    `, limit, lease.Seconds(), kinds)
+	if err != nil {
+		return nil, fmt.Errorf("claim jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// аренда задачи — пара (id, attempts) из ClaimDue: после повторного захвата
+// attempts уже другой, и запоздавший воркер ничего не перезапишет
+func (r *jobRepository) MarkDone(ctx context.Context, job models.Job) error {
+	res, err := r.db.Exec(ctx, `
+        UPDATE jobs
+        SET status = 'DONE', finished_at = NOW(), last_error = NULL
+        WHERE id = $1 AND status = 'RUNNING' AND attempts = $2
+    `, job.ID, job.Attempts)
+	if err != nil {
+		return fmt.Errorf("mark job done: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *jobRepository) MarkFailed(ctx context.Context, job models.Job, attempts int, runAt time.Time, lastErr string, dead bool) error {
+	status := models.JobPending
+	if dead {
+		status = models.JobFailed
+	}
+
+	res, err := r.db.Exec(ctx, `
+        UPDATE jobs
+        SET status = $3, attempts = $4, run_at = $5, last_error = $6,
+            finished_at = CASE WHEN $3 = 'FAILED' THEN NOW() END
+        WHERE id = $1 AND status = 'RUNNING' AND attempts = $2
+    `, job.ID, job.Attempts, status, attempts, runAt, lastErr)
+	if err != nil {
+		return fmt.Errorf("mark job failed: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *jobRepository) List(ctx context.Context, filter models.JobFilter) ([]models.Job, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT `+jobColumns+`
+        FROM jobs
+        WHERE ($1 = '' OR status = $1) AND ($2 = '' OR kind = $2)
+        ORDER BY id DESC
+        LIMIT $3
+    `, filter.Status, filter.Kind, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *jobRepository) Get(ctx context.Context, id int64) (models.Job, error) {
+	j, err := scanJob(r.db.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Job{}, models.ErrJobNotFound
+	}
+	if err != nil {
+		return models.Job{}, fmt.Errorf("get job: %w", err)
+	}
+	return j, nil
+}
+
+func (r *jobRepository) Retry(ctx context.Context, id int64) error {
+	res, err := r.db.Exec(ctx, `
+        UPDATE jobs
+        SET status = 'PENDING', attempts = 0, run_at = NOW(), finished_at = NULL
+        WHERE id = $1 AND status = 'FAILED'
+    `, id)
+	if err != nil {
+		return fmt.Errorf("retry job: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrJobNotFound
+	}
+	return nil
+}
+
+// упавшие разовые задачи не удаляются: их разбирают вручную через
+// /jobs/retry. Запуски по расписанию (у них есть unique_key) удаляются
+// и упавшими: повтором для них служит следующий запуск, а при частом
+// расписании и сломанной зависимости они копились бы без конца
+func (r *jobRepository) DeleteFinished(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.Exec(ctx, `
+        DELETE FROM jobs
+        WHERE finished_at < $1
+          AND (status = 'DONE' OR (status = 'FAILED' AND unique_key IS NOT NULL))
+    `, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete finished jobs: %w", err)
+	}
+	return int(res.RowsAffected()), nil
+}
@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// claimOne забирает ровно одну готовую задачу вида kind
+func claimOne(t *testing.T, repo repository.JobRepository, kind string) models.Job {
+	t.Helper()
+	claimed, err := repo.ClaimDue(context.Background(), []string{kind}, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	return claimed[0]
+}
+
+func TestJobRepository_Integration_EnqueueUnique(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := newJobRepository(dbPool)
+
+	job := models.Job{Kind: "report", MaxAttempts: 1, RunAt: time.Now(), UniqueKey: "report@2024-05-13T10:00:00Z"}
+	first, created, err := repo.Enqueue(ctx, job)
+	require.NoError(t, err)
+	require.True(t, created)
+	assert.Equal(t, models.JobPending, first.Status)
+	assert.JSONEq(t, `{}`, string(first.Payload))
+
+	_, created, err = repo.Enqueue(ctx, job)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// без ключа дубли разрешены
+	for range 2 {
+		_, created, err = repo.Enqueue(ctx, models.Job{Kind: "email", Payload: []byte(`{"to":"u1"}`), MaxAttempts: 3, RunAt: time.Now()})
+		require.NoError(t, err)
+		assert.True(t, created)
+	}
+
+	jobs, err := repo.List(ctx, models.JobFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, jobs, 3)
+
+	jobs, err = repo.List(ctx, models.JobFilter{Kind: "email", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.JSONEq(t, `{"to":"u1"}`, string(jobs[0].Payload))
+}
+
+func TestJobRepository_Integration_ClaimDue(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := newJobRepository(dbPool)
+
+	due, _, err := repo.Enqueue(ctx, models.Job{Kind: "email", MaxAttempts: 3, RunAt: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+	_, _, err = repo.Enqueue(ctx, models.Job{Kind: "email", MaxAttempts: 3, RunAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, _, err = repo.Enqueue(ctx, models.Job{Kind: "unknown", MaxAttempts: 3, RunAt: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+
+	claimed, err := repo.ClaimDue(ctx, []string{"email"}, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, due.ID, claimed[0].ID)
+	assert.Equal(t, models.JobRunning, claimed[0].Status)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), claimed[0].RunAt, 5*time.Second)
+
+	// пока аренда не истекла, задачу никто не заберёт
+	claimed, err = repo.ClaimDue(ctx, []string{"email"}, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// воркер завис: после конца аренды задача снова доступна
+	stale := claimed
+	_, err = dbPool.Exec(ctx, `UPDATE jobs SET run_at = NOW() - INTERVAL '1 second' WHERE id = $1`, due.ID)
+	require.NoError(t, err)
+	claimed, err = repo.ClaimDue(ctx, []string{"email"}, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 2, claimed[0].Attempts)
+
+	// проснувшийся первый воркер не перезаписывает состояние второго
+	require.ErrorIs(t, repo.MarkFailed(ctx, stale[0], 1, time.Now(), "late", true), repository.ErrLeaseLost)
+	require.ErrorIs(t, repo.MarkDone(ctx, stale[0]), repository.ErrLeaseLost)
+
+	require.NoError(t, repo.MarkDone(ctx, claimed[0]))
+	got, err := repo.Get(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobDone, got.Status)
+	assert.NotNil(t, got.FinishedAt)
+}
+
+func TestJobRepository_Integration_ExpiredLeaseUsesAttempt(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := newJobRepository(dbPool)
+
+	job, _, err := repo.Enqueue(ctx, models.Job{Kind: "email", MaxAttempts: 2, RunAt: time.Now()})
+	require.NoError(t, err)
+
+	// воркеры падают на задаче дважды, не успев ничего записать
+	for range 2 {
+		claimOne(t, repo, "email")
+		_, err = dbPool.Exec(ctx, `UPDATE jobs SET run_at = NOW() - INTERVAL '1 second' WHERE id = $1`, job.ID)
+		require.NoError(t, err)
+	}
+
+	claimed, err := repo.ClaimDue(ctx, []string{"email"}, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "a job out of attempts must not be claimed again")
+
+	got, err := repo.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobFailed, got.Status)
+	assert.Equal(t, 2, got.Attempts)
+	assert.Equal(t, "lease expired", got.LastError)
+	assert.NotNil(t, got.FinishedAt)
+}
+
+func TestJobRepository_Integration_FailAndRetry(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := newJobRepository(dbPool)
+
+	job, _, err := repo.Enqueue(ctx, models.Job{Kind: "email", MaxAttempts: 2, RunAt: time.Now()})
+	require.NoError(t, err)
+
+	// FAILED-задачу, которая ещё не упала окончательно, вернуть нельзя
+	require.NoError(t, repo.MarkFailed(ctx, claimOne(t, repo, "email"), 1, time.Now(), "smtp is down", false))
+	require.ErrorIs(t, repo.Retry(ctx, job.ID), models.ErrJobNotFound)
+
+	got, err := repo.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobPending, got.Status)
+	assert.Equal(t, "smtp is down", got.LastError)
+	assert.Nil(t, got.FinishedAt)
+
+	require.NoError(t, repo.MarkFailed(ctx, claimOne(t, repo, "email"), 2, time.Now(), "smtp is still down", true))
+	got, err = repo.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobFailed, got.Status)
+	assert.NotNil(t, got.FinishedAt)
+
+	failed, err := repo.List(ctx, models.JobFilter{Status: models.JobFailed, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+
+	require.NoError(t, repo.Retry(ctx, job.ID))
+	got, err = repo.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobPending, got.Status)
+	assert.Equal(t, 0, got.Attempts)
+	assert.Nil(t, got.FinishedAt)
+
+	_, err = repo.Get(ctx, 9999)
+	require.ErrorIs(t, err, models.ErrJobNotFound)
+}
+
+func TestJobRepository_Integration_DeleteFinished(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := newJobRepository(dbPool)
+
+	_, _, err := repo.Enqueue(ctx, models.Job{Kind: "email", MaxAttempts: 1, RunAt: time.Now()})
+	require.NoError(t, err)
+	failed, _, err := repo.Enqueue(ctx, models.Job{Kind: "sms", MaxAttempts: 1, RunAt: time.Now()})
+	require.NoError(t, err)
+	scheduled, _, err := repo.Enqueue(ctx, models.Job{Kind: "report", MaxAttempts: 1, RunAt: time.Now(), UniqueKey: "report@2024-05-13T10:00:00Z"})
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkDone(ctx, claimOne(t, repo, "email")))
+	require.NoError(t, repo.MarkFailed(ctx, claimOne(t, repo, "sms"), 1, time.Now(), "boom", true))
+	require.NoError(t, repo.MarkFailed(ctx, claimOne(t, repo, "report"), 1, time.Now(), "boom", true))
+
+	n, err := repo.DeleteFinished(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = repo.DeleteFinished(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "done jobs and failed scheduled runs are deleted")
+
+	_, err = repo.Get(ctx, failed.ID)
+	require.NoError(t, err, "a failed one-off job waits for /jobs/retry")
+	_, err = repo.Get(ctx, scheduled.ID)
+	require.ErrorIs(t, err, models.ErrJobNotFound)
+}
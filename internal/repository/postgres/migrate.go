@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"avito-pr-service/migrations"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// реплики, стартующие одновременно, ждут advisory lock, пока первая
+// накатывает миграции; таймаут должен покрывать самую долгую миграцию
+const migrateLockTimeout = 5 * time.Minute
+
+// Migrator работает с той же таблицей schema_migrations, что и CLI
+// golang-migrate, поэтому их можно использовать вперемешку
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+func NewMigrator(dsn string, log *slog.Logger) (*Migrator, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+
+	m, err := openMigrate(src, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("init migrator: %w", err)
+	}
+	m.LockTimeout = migrateLockTimeout
+	m.Log = migrateLogger{log: log.With("component", "migrate")}
+
+	return &Migrator{m: m}, nil
+}
+
+// Up накатывает все недостающие миграции и возвращает версии до и после
+func (m *Migrator) Up() (int64, int64, error) {
+	from, _, err := m.Version()
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return from, from, fmt.Errorf("apply migrations: %w", err)
+	}
+	to, _, err := m.Version()
+	return from, to, err
+}
+
+// Down откатывает одну последнюю миграцию
+func (m *Migrator) Down() error {
+	if err := m.m.Steps(-1); err != nil {
+		return fmt.Errorf("rollback migration: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) Version() (int64, bool, error) {
+	v, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get schema version: %w", err)
+	}
+	return int64(v), dirty, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// golang-migrate понимает только URL; key=value DSN разбирает pgx,
+// а драйвер получает уже открытое соединение
+func openMigrate(src source.Driver, dsn string) (*migrate.Migrate, error) {
+	if u, ok := migrateURL(dsn); ok {
+		return migrate.NewWithSourceInstance("iofs", src, u)
+	}
+
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse dsn: %w", err)
+	}
+	db := stdlib.OpenDB(*cfg)
+	driver, err := migratepgx.WithInstance(db, &migratepgx.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return migrate.NewWithInstance("iofs", src, "pgx5", driver)
+}
+
+// драйвер pgx/v5 регистрируется под схемой pgx5://
+func migrateURL(dsn string) (string, bool) {
+	for _, prefix := range []string{"postgres://", "postgresql://"} {
+		if strings.HasPrefix(dsn, prefix) {
+			return "pgx5://" + strings.TrimPrefix(dsn, prefix), true
+		}
+	}
+	return "", false
+}
+
+type migrateLogger struct {
+	log *slog.Logger
+}
+
+func (l migrateLogger) Printf(format string, v ...any) {
+	l.log.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l migrateLogger) Verbose() bool { return false }
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"avito-pr-service/migrations"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+func TestMigrator_Integration_ConcurrentUp(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	dsn := dbPool.Config().ConnString()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	latest, err := migrations.LatestVersion()
+	require.NoError(t, err)
+
+	m, err := NewMigrator(dsn, log)
+	require.NoError(t, err)
+	require.NoError(t, m.Down())
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Less(t, version, latest)
+	require.NoError(t, m.Close())
+
+	// несколько реплик стартуют одновременно: накатить должна ровно одна,
+	// остальные дождутся advisory lock и увидят актуальную схему
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := NewMigrator(dsn, log)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer m.Close()
+			_, _, err = m.Up()
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	m, err = NewMigrator(dsn, log)
+	require.NoError(t, err)
+	defer m.Close()
+	version, dirty, err = m.Version()
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, latest, version)
+}
+
+func TestMigrator_Integration_KeyValueDSN(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cfg := dbPool.Config().ConnConfig
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password='%s' dbname=%s sslmode=disable",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database)
+	latest, err := migrations.LatestVersion()
+	require.NoError(t, err)
+
+	m, err := NewMigrator(dsn, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	defer m.Close()
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, latest, version)
+}
@@ -0,0 +1,267 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+const maxOutboxBackoff = 5 * time.Minute
+
+type outboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func newOutboxRepository(db *pgxpool.Pool) repository.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func newEventLogRepository(db *pgxpool.Pool) repository.EventLogRepository {
+	return &outboxRepository{db: db}
+}
+
+// insertEvent пишет событие в outbox в той же транзакции, что и изменение
+// данных: событие появится тогда и только тогда, когда закоммитятся данные
+func insertEvent(ctx context.Context, tx pgx.Tx, event models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO outbox_events (aggregate_id, event_type, payload, created_at)
+        VALUES ($1, $2, $3, $4)
+    `, event.AggregateID(), event.Type, payload, event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
+
+type outboxRow struct {
+	id          int64
+	aggregateID string
+	attempts    int
+	event       models.Event
+}
+
+func (r *outboxRepository) ProcessBatch(ctx context.Context, limit int, handle func(ctx context.Context, event models.Event) error) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+        SELECT id, aggregate_id, attempts, payload
+        FROM outbox_events
+        WHERE dispatched_at IS NULL AND next_attempt_at <= NOW()
+        ORDER BY id
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    `, limit)
+	if err != nil {
+		return 0, fmt.Errorf("select outbox events: %w", err)
+	}
+
+	var batch []outboxRow
+	aggregates := map[string]int64{}
+	for rows.Next() {
+		var row outboxRow
+		var payload []byte
+		if err := rows.Scan(&row.id, &row.aggregateID, &row.attempts, &payload); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan outbox event: %w", err)
+		}
+		if err := json.Unmarshal(payload, &row.event); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("decode outbox event %d: %w", row.id, err)
+		}
+		row.event.ID = row.id
+		batch = append(batch, row)
+		if _, ok := aggregates[row.aggregateID]; !ok {
+			aggregates[row.aggregateID] = row.id
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("select outbox events: %w", err)
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	blocked, err := blockedAggregates(ctx, tx, aggregates)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, row := range batch {
+		if blocked[row.aggregateID] {
+			continue
+		}
+
+		if handleErr := handle(ctx, row.event); handleErr != nil {
+			// следующие события этого агрегата ждут, пока не уйдёт текущее
+			blocked[row.aggregateID] = true
+			next := time.Now().Add(outboxBackoff(row.attempts + 1))
+			_, err = tx.Exec(ctx, `
+                UPDATE outbox_events
+                SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+                WHERE id = $1
+            `, row.id, next, handleErr.Error())
+			if err != nil {
+				return processed, fmt.Errorf("mark outbox event failed: %w", err)
+			}
+			continue
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE outbox_events SET dispatched_at = NOW(), last_error = NULL WHERE id = $1`, row.id)
+		if err != nil {
+			return processed, fmt.Errorf("mark outbox event dispatched: %w", err)
+		}
+		processed++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return processed, nil
+}
+
+// последнее событие не удаляется никогда: по нему LastEventID отдаёт конец
+// журнала, иначе поток событий после рестарта начал бы с id 0. Наибольший
+// удалённый id запоминается в outbox_retention для DeletedUpTo
+func (r *outboxRepository) DeleteDispatched(ctx context.Context, before time.Time) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx, `
+        WITH deleted AS (
+            DELETE FROM outbox_events
+            WHERE dispatched_at < $1
+              AND id < (SELECT MAX(id) FROM outbox_events)
+            RETURNING id
+        ), mark AS (
+            UPDATE outbox_retention
+            SET deleted_up_to = GREATEST(deleted_up_to, (SELECT MAX(id) FROM deleted))
+            WHERE EXISTS (SELECT 1 FROM deleted)
+        )
+        SELECT COUNT(*) FROM deleted
+    `, before).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("delete dispatched outbox events: %w", err)
+	}
+	return n, nil
+}
+
+func (r *outboxRepository) ListEvents(ctx context.Context, afterID int64, limit int) ([]models.Event, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, payload
+        FROM outbox_events
+        WHERE id > $1
+        ORDER BY id
+        LIMIT $2
+    `, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list outbox events: %w", err)
+	}
+	return scanEvents(rows)
+}
+
+func (r *outboxRepository) GetEvents(ctx context.Context, ids []int64) ([]models.Event, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, payload
+        FROM outbox_events
+        WHERE id = ANY($1)
+        ORDER BY id
+    `, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get outbox events: %w", err)
+	}
+	return scanEvents(rows)
+}
+
+func (r *outboxRepository) LastEventID(ctx context.Context) (int64, error) {
+	var id int64
+	if err := r.db.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM outbox_events`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("last outbox event id: %w", err)
+	}
+	return id, nil
+}
+
+func (r *outboxRepository) DeletedUpTo(ctx context.Context) (int64, error) {
+	var id int64
+	if err := r.db.QueryRow(ctx, `SELECT COALESCE(MAX(deleted_up_to), 0) FROM outbox_retention`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("outbox retention boundary: %w", err)
+	}
+	return id, nil
+}
+
+func scanEvents(rows pgx.Rows) ([]models.Event, error) {
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var id int64
+		var payload []byte
+		if err := rows.Scan(&id, &payload); err != nil {
+			return nil, fmt.Errorf("scan outbox event: %w", err)
+		}
+		var event models.Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("decode outbox event %d: %w", id, err)
+		}
+		event.ID = id
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// агрегат заблокирован, если у него есть более раннее недоставленное событие,
+// которое не попало в пачку: его держит другая реплика или оно ждёт повтора
+func blockedAggregates(ctx context.Context, tx pgx.Tx, firstIDs map[string]int64) (map[string]bool, error) {
+	ids := make([]string, 0, len(firstIDs))
+	for agg := range firstIDs {
+		ids = append(ids, agg)
+	}
+
+	rows, err := tx.Query(ctx, `
+        SELECT aggregate_id, MIN(id)
+        FROM outbox_events
+        WHERE dispatched_at IS NULL AND aggregate_id = ANY($1)
+        GROUP BY aggregate_id
+    `, ids)
+	if err != nil {
+		return nil, fmt.Errorf("check outbox ordering: %w", err)
+	}
+	defer rows.Close()
+
+	blocked := map[string]bool{}
+	for rows.Next() {
+		var agg string
+		var minID int64
+		if err := rows.Scan(&agg, &minID); err != nil {
+			return nil, fmt.Errorf("scan outbox ordering: %w", err)
+		}
+		if minID < firstIDs[agg] {
+			blocked[agg] = true
+		}
+	}
+	return blocked, rows.Err()
+}
+
+func outboxBackoff(attempt int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxOutboxBackoff {
+			return maxOutboxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestOutboxRepository_Integration_EventsWrittenWithData(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	prRepo := newPrRepository(dbPool)
+	outbox := newOutboxRepository(dbPool)
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{
+		ID: "pr-1", Name: "Test PR", AuthorID: "u1", AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &now,
+	}))
+	require.NoError(t, prRepo.ReassignReviewer(ctx, "pr-1", "u2", "u4"))
+	_, err := prRepo.MergePR(ctx, "pr-1")
+	require.NoError(t, err)
+
+	var got []models.Event
+	n, err := outbox.ProcessBatch(ctx, 100, func(_ context.Context, ev models.Event) error {
+		got = append(got, ev)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	types := make([]string, 0, len(got))
+	for _, ev := range got {
+		types = append(types, ev.Type)
+		assert.Equal(t, "team1", ev.TeamName)
+		assert.NotZero(t, ev.ID)
+	}
+	assert.Equal(t, []string{
+		models.EventPRCreated, models.EventReviewerAssigned, models.EventReviewerAssigned,
+		models.EventReviewerReassigned, models.EventPRMerged,
+	}, types)
+
+	// pr.merged несёт PR целиком, как в первой версии вебхуков
+	merged := got[4].Data.(map[string]any)
+	assert.Equal(t, "pr-1", merged["pull_request_id"])
+	assert.Equal(t, "Test PR", merged["pull_request_name"])
+	assert.Equal(t, models.StatusMerged, merged["status"])
+	assert.ElementsMatch(t, []any{"u3", "u4"}, merged["assigned_reviewers"])
+	assert.NotEmpty(t, merged["mergedAt"])
+
+	n, err = outbox.ProcessBatch(ctx, 100, func(context.Context, models.Event) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "dispatched events must not be delivered again")
+}
+
+func TestOutboxRepository_Integration_FailureBlocksAggregate(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	prRepo := newPrRepository(dbPool)
+	outbox := newOutboxRepository(dbPool)
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-1", Name: "A", AuthorID: "u1", CreatedAt: &now}))
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-2", Name: "B", AuthorID: "u1", CreatedAt: &now}))
+	_, err := prRepo.MergePR(ctx, "pr-1")
+	require.NoError(t, err)
+
+	var delivered []string
+	n, err := outbox.ProcessBatch(ctx, 100, func(_ context.Context, ev models.Event) error {
+		if ev.PRID == "pr-1" && ev.Type == models.EventPRCreated {
+			return errors.New("sink down")
+		}
+		delivered = append(delivered, ev.PRID+"/"+ev.Type)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"pr-2/" + models.EventPRCreated}, delivered)
+
+	var attempts int
+	var lastErr string
+	err = dbPool.QueryRow(ctx, `
+		SELECT attempts, last_error FROM outbox_events
+		WHERE aggregate_id = 'pr:pr-1' AND event_type = $1
+	`, models.EventPRCreated).Scan(&attempts, &lastErr)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, "sink down", lastErr)
+}
+
+func TestOutboxRepository_Integration_DeactivateTeamEvent(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertUserTestData(t, dbPool)
+
+	userRepo := newUserRepository(dbPool)
+	outbox := newOutboxRepository(dbPool)
+	ctx := context.Background()
+
+	_, err := userRepo.DeactivateTeam(ctx, "team1", 2)
+	require.NoError(t, err)
+
+	var got []models.Event
+	_, err = outbox.ProcessBatch(ctx, 10, func(_ context.Context, ev models.Event) error {
+		got = append(got, ev)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, models.EventTeamDeactivated, got[0].Type)
+	assert.Equal(t, "team1", got[0].TeamName)
+	data := got[0].Data.(map[string]any)
+	assert.EqualValues(t, 2, data["reassigned_prs"])
+	assert.NotZero(t, data["deactivated_users"])
+}
+
+func TestOutboxRepository_Integration_EventLogIncludesDispatched(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	prRepo := newPrRepository(dbPool)
+	outbox := newOutboxRepository(dbPool)
+	eventLog := newEventLogRepository(dbPool)
+	ctx := context.Background()
+
+	last, err := eventLog.LastEventID(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, last)
+
+	now := time.Now()
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{
+		ID: "pr-1", Name: "Test PR", AuthorID: "u1", AssignedReviewers: []string{"u2"}, CreatedAt: &now,
+	}))
+	_, err = prRepo.MergePR(ctx, "pr-1")
+	require.NoError(t, err)
+	_, err = outbox.ProcessBatch(ctx, 1, func(context.Context, models.Event) error { return nil })
+	require.NoError(t, err)
+
+	all, err := eventLog.ListEvents(ctx, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, models.EventPRCreated, all[0].Type)
+	assert.Equal(t, "pr-1", all[0].PRID)
+
+	last, err = eventLog.LastEventID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, all[2].ID, last)
+
+	page, err := eventLog.ListEvents(ctx, all[0].ID, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, all[1].ID, page[0].ID)
+
+	picked, err := eventLog.GetEvents(ctx, []int64{all[2].ID, all[0].ID, last + 100})
+	require.NoError(t, err)
+	require.Len(t, picked, 2)
+	assert.Equal(t, []int64{all[0].ID, all[2].ID}, []int64{picked[0].ID, picked[1].ID})
+}
+
+func TestOutboxRepository_Integration_DeleteDispatched(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	prRepo := newPrRepository(dbPool)
+	outbox := newOutboxRepository(dbPool)
+	events := newEventLogRepository(dbPool)
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: id, Name: id, AuthorID: "u1", CreatedAt: &now}))
+	}
+	_, err := outbox.ProcessBatch(ctx, 100, func(_ context.Context, ev models.Event) error {
+		if ev.PRID == "pr-2" {
+			return errors.New("sink down")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = dbPool.Exec(ctx, `UPDATE outbox_events SET dispatched_at = NOW() - interval '2 hours' WHERE dispatched_at IS NOT NULL`)
+	require.NoError(t, err)
+	last, err := events.LastEventID(ctx)
+	require.NoError(t, err)
+
+	deleted, err := events.DeletedUpTo(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	// pr-2 не доставлено, pr-3 — последнее событие журнала
+	n, err := outbox.DeleteDispatched(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	left, err := events.ListEvents(ctx, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, left, 2)
+	assert.Equal(t, "pr-2", left[0].PRID)
+	assert.Equal(t, "pr-3", left[1].PRID)
+
+	// граница — удалённое событие pr-1, хотя более старое по id не осталось
+	deleted, err = events.DeletedUpTo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, left[0].ID-1, deleted)
+
+	// повторная очистка без удалений границу не сдвигает
+	n, err = outbox.DeleteDispatched(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	again, err := events.DeletedUpTo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, deleted, again)
+
+	after, err := events.LastEventID(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, last, after)
+}
@@ -48,6 +48,32 @@ func (r *prRepository) CreatePR(ctx context.Context, pr models.PullRequest) erro
 		}
 	}
 
+	var teamName string
+	err = tx.QueryRow(ctx, `SELECT team_name FROM users WHERE user_id = $1`, pr.AuthorID).Scan(&teamName)
+	if err != nil {
+		return fmt.Errorf("select author team: %w", err)
+	}
+
+	occurredAt := time.Now()
+	if pr.CreatedAt != nil {
+		occurredAt = *pr.CreatedAt
+	}
+	pr.Status = models.StatusOpen
+	events := []models.Event{{
+		Type: models.EventPRCreated, OccurredAt: occurredAt, PRID: pr.ID, TeamName: teamName, Data: pr,
+	}}
+	for _, uid := range pr.AssignedReviewers {
+		events = append(events, models.Event{
+			Type: models.EventReviewerAssigned, OccurredAt: occurredAt, PRID: pr.ID, TeamName: teamName,
+			Data: models.ReviewerAssignedData{PRID: pr.ID, ReviewerID: uid},
+		})
+	}
+	for _, ev := range events {
+		if err := insertEvent(ctx, tx, ev); err != nil {
+			return err
+		}
+	}
+
 	return tx.Commit(ctx)
 }
 
@@ -88,8 +114,14 @@ func (r *prRepository) MergePR(ctx context.Context, prID string) (*time.Time, er
 	}
 	defer tx.Rollback(ctx)
 
-	var status string
-	err = tx.QueryRow(ctx, `SELECT status FROM pull_requests WHERE id = $1 FOR UPDATE`, prID).Scan(&status)
+	var status, teamName string
+	err = tx.QueryRow(ctx, `
+        SELECT p.status, u.team_name
+        FROM pull_requests p
+        JOIN users u ON u.user_id = p.author_id
+        WHERE p.id = $1
+        FOR UPDATE OF p
+    `, prID).Scan(&status, &teamName)
 	if err != nil {
 		return nil, fmt.Errorf("scanning PR after merge: %w", err)
 	}
@@ -101,30 +133,64 @@ func (r *prRepository) MergePR(ctx context.Context, prID string) (*time.Time, er
 		return nil, models.ErrInvalidStatus
 	}
 
-	var mergedAt time.Time
+	// в данных pr.merged — PR целиком, как его отдаёт /pullRequest/merge
+	pr := models.PullRequest{ID: prID, Status: models.StatusMerged}
 	err = tx.QueryRow(ctx, `
         UPDATE pull_requests 
         SET status = 'MERGED', 
             merged_at = COALESCE(merged_at, NOW())
         WHERE id = $1
-        RETURNING merged_at
-    `, prID).Scan(&mergedAt)
+        RETURNING name, author_id, created_at, merged_at
+    `, prID).Scan(&pr.Name, &pr.AuthorID, &pr.CreatedAt, &pr.MergedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT user_id FROM pr_reviewers WHERE pr_id = $1`, prID)
+	if err != nil {
+		return nil, fmt.Errorf("select reviewers: %w", err)
+	}
+	pr.AssignedReviewers, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("select reviewers: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, models.Event{
+		Type: models.EventPRMerged, OccurredAt: *pr.MergedAt, PRID: prID, TeamName: teamName, Data: pr,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &mergedAt, tx.Commit(ctx)
+	return pr.MergedAt, tx.Commit(ctx)
 }
 
 func (r *prRepository) ReassignReviewer(ctx context.Context, prID, oldUID, newUID string) error {
+	return r.reassign(ctx, prID, oldUID, newUID, false)
+}
+
+func (r *prRepository) MovePendingReviewer(ctx context.Context, prID, oldUID, newUID string) error {
+	return r.reassign(ctx, prID, oldUID, newUID, true)
+}
+
+// reassign заменяет ревьювера; с pendingOnly вынесший вердикт ревьювер
+// не заменяется, без него вердикт сохраняется в reviewer_reassignments.
+// Проверка идёт под той же блокировкой, что и замена
+func (r *prRepository) reassign(ctx context.Context, prID, oldUID, newUID string, pendingOnly bool) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	var status string
-	err = tx.QueryRow(ctx, "SELECT status FROM pull_requests WHERE id = $1 FOR UPDATE", prID).Scan(&status)
+	var status, teamName string
+	err = tx.QueryRow(ctx, `
+        SELECT p.status, u.team_name
+        FROM pull_requests p
+        JOIN users u ON u.user_id = p.author_id
+        WHERE p.id = $1
+        FOR UPDATE OF p
+    `, prID).Scan(&status, &teamName)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.ErrNotFound
@@ -136,17 +202,22 @@ func (r *prRepository) ReassignReviewer(ctx context.Context, prID, oldUID, newUI
 	}
 
 	currentReviewers := []string{}
-	rows, err := tx.Query(ctx, "SELECT user_id FROM pr_reviewers WHERE pr_id = $1 FOR UPDATE", prID)
+	var reviewed []string
+	rows, err := tx.Query(ctx, "SELECT user_id, verdict IS NOT NULL FROM pr_reviewers WHERE pr_id = $1 FOR UPDATE", prID)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var uid string
-		if rowsErr := rows.Scan(&uid); rowsErr != nil {
+		var hasVerdict bool
+		if rowsErr := rows.Scan(&uid, &hasVerdict); rowsErr != nil {
 			return rowsErr
 		}
 		currentReviewers = append(currentReviewers, uid)
+		if hasVerdict {
+			reviewed = append(reviewed, uid)
+		}
 	}
 	if rowsErr := rows.Err(); rowsErr != nil {
 		return rowsErr
@@ -156,93 +227,190 @@ func (r *prRepository) ReassignReviewer(ctx context.Context, prID, oldUID, newUI
 		return models.ErrNotAssigned
 	}
 
+	if pendingOnly && slices.Contains(reviewed, oldUID) {
+		return models.ErrReviewSubmitted
+	}
+
 	if slices.Contains(currentReviewers, newUID) {
 		return models.ErrNoCandidate
 	}
 
-	res, err := tx.Exec(ctx, "DELETE FROM pr_reviewers WHERE pr_id = $1 AND user_id = $2", prID, oldUID)
+	var verdict *string
+	var verdictAt *time.Time
+	err = tx.QueryRow(ctx, `
+        DELETE FROM pr_reviewers WHERE pr_id = $1 AND user_id = $2
+        RETURNING verdict, verdict_at
+    `, prID, oldUID).Scan(&verdict, &verdictAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrNotAssigned
+	}
 	if err != nil {
 		return err
 	}
-	if res.RowsAffected() != 1 {
-		return models.ErrNotAssigned
-	}
 
 	_, err = tx.Exec(ctx, "INSERT INTO pr_reviewers (pr_id, user_id) VALUES ($1, $2)", prID, newUID)
 	if err != nil {
 		return err
 	}
 
+	reassignedAt := time.Now()
+	_, err = tx.Exec(ctx, `
+        INSERT INTO reviewer_reassignments (pr_id, old_reviewer_id, new_reviewer_id, reassigned_at, verdict, verdict_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, prID, oldUID, newUID, reassignedAt, verdict, verdictAt)
+	if err != nil {
+		return fmt.Errorf("insert reassignment: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, models.Event{
+		Type: models.EventReviewerReassigned, OccurredAt: reassignedAt, PRID: prID, TeamName: teamName,
+		Data: models.ReviewerReassignedData{PRID: prID, OldReviewerID: oldUID, NewReviewerID: newUID},
+	})
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
-func (r *prRepository) GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequest, error) {
-	prIDsQuery := `
-        SELECT DISTINCT pr.pr_id 
-        FROM pr_reviewers pr 
-        WHERE pr.user_id = $1
-    `
+func (r *prRepository) SetVerdict(ctx context.Context, prID, userID, verdict string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-	rows, err := r.db.Query(ctx, prIDsQuery, userID)
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM pull_requests WHERE id = $1 FOR UPDATE`, prID).Scan(&status)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrNotFound
+		}
+		return err
+	}
+	if status != models.StatusOpen {
+		return models.ErrPRMerged
+	}
+
+	res, err := tx.Exec(ctx, `
+        UPDATE pr_reviewers
+        SET verdict = $3, verdict_at = NOW()
+        WHERE pr_id = $1 AND user_id = $2
+    `, prID, userID, verdict)
+	if err != nil {
+		return fmt.Errorf("set verdict: %w", err)
+	}
+	if res.RowsAffected() != 1 {
+		return models.ErrNotAssigned
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *prRepository) GetReviewedAssignments(ctx context.Context, teamName string) (map[string][]string, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT pr.pr_id, pr.user_id
+        FROM pr_reviewers pr
+        JOIN pull_requests p ON p.id = pr.pr_id
+        JOIN users u ON u.user_id = pr.user_id
+        WHERE u.team_name = $1 AND p.status = 'OPEN' AND pr.verdict IS NOT NULL
+        ORDER BY pr.pr_id, pr.user_id
+    `, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("get reviewed assignments: %w", err)
 	}
 	defer rows.Close()
 
-	var prIDs []string
+	reviewed := make(map[string][]string)
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
+		var prID, userID string
+		if err := rows.Scan(&prID, &userID); err != nil {
 			return nil, err
 		}
-		prIDs = append(prIDs, id)
+		reviewed[prID] = append(reviewed[prID], userID)
 	}
+	return reviewed, rows.Err()
+}
 
-	if len(prIDs) == 0 {
-		return []models.PullRequest{}, nil
+func (r *prRepository) GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequest, error) {
+	prs := []models.PullRequest{}
+	err := r.EachPRByReviewer(ctx, userID, func(pr models.PullRequest) error {
+		prs = append(prs, pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return prs, nil
+}
 
-	prs := make([]models.PullRequest, 0, len(prIDs))
-	for _, prID := range prIDs {
-		pr, err := r.GetPR(ctx, prID)
-		if err != nil {
-			return nil, err
-		}
-		prs = append(prs, pr)
+// EachPRByReviewer отдаёт PR по одному по мере чтения из базы
+func (r *prRepository) EachPRByReviewer(ctx context.Context, userID string, fn func(models.PullRequest) error) error {
+	rows, err := r.db.Query(ctx, `
+        SELECT p.id, p.name, p.author_id, p.status, p.created_at, p.merged_at,
+               ARRAY(SELECT r2.user_id FROM pr_reviewers r2 WHERE r2.pr_id = p.id ORDER BY r2.user_id)
+        FROM pr_reviewers r
+        JOIN pull_requests p ON p.id = r.pr_id
+        WHERE r.user_id = $1
+        ORDER BY p.created_at, p.id
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("query prs by reviewer: %w", err)
 	}
+	defer rows.Close()
 
-	return prs, nil
+	for rows.Next() {
+		var pr models.PullRequest
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.AssignedReviewers); err != nil {
+			return fmt.Errorf("scan pr: %w", err)
+		}
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
 func (r *prRepository) GetUserStats(ctx context.Context) ([]models.UserStats, error) {
+	var stats []models.UserStats
+	err := r.EachUserStats(ctx, func(s models.UserStats) error {
+		stats = append(stats, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *prRepository) EachUserStats(ctx context.Context, fn func(models.UserStats) error) error {
 	rows, err := r.db.Query(ctx, `
         SELECT 
             u.user_id, 
             u.team_name, 
             u.username, 
             COUNT(pr.pr_id) as count,
-            COALESCE(array_agg(pr.pr_id) FILTER (WHERE pr.pr_id IS NOT NULL), '{}') as pr_ids
+            COALESCE(array_agg(pr.pr_id ORDER BY pr.pr_id) FILTER (WHERE pr.pr_id IS NOT NULL), '{}') as pr_ids
         FROM users u
         LEFT JOIN pr_reviewers pr ON u.user_id = pr.user_id
         GROUP BY u.user_id
-        ORDER BY count DESC
+        ORDER BY count DESC, u.user_id
     `)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var stats []models.UserStats
 	for rows.Next() {
 		var s models.UserStats
-		var prIDs []string
-		if err := rows.Scan(&s.UserID, &s.TeamName, &s.Username, &s.AssignmentCount, &prIDs); err != nil {
-			return nil, err
+		if err := rows.Scan(&s.UserID, &s.TeamName, &s.Username, &s.AssignmentCount, &s.AssignedPRs); err != nil {
+			return err
+		}
+		if err := fn(s); err != nil {
+			return err
 		}
-		s.AssignedPRs = prIDs
-		stats = append(stats, s)
 	}
-	return stats, nil
+	return rows.Err()
 }
 
 func (r *prRepository) GetOpenPRsWithTeamReviewers(ctx context.Context, teamName string) ([]models.PullRequest, error) {
@@ -278,3 +446,28 @@ func (r *prRepository) GetOpenPRsWithTeamReviewers(ctx context.Context, teamName
 
 	return prs, nil
 }
+
+func (r *prRepository) CountOpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT u.team_name, COUNT(*)
+        FROM pull_requests p
+        JOIN users u ON u.user_id = p.author_id
+        WHERE p.status = 'OPEN'
+        GROUP BY u.team_name
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("count open PRs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var team string
+		var n int
+		if err := rows.Scan(&team, &n); err != nil {
+			return nil, fmt.Errorf("scan open PR count: %w", err)
+		}
+		counts[team] = n
+	}
+	return counts, rows.Err()
+}
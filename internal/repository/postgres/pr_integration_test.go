@@ -142,6 +142,53 @@ func TestPRRepository_Integration_ReassignReviewer(t *testing.T) {
 	assert.Equal(t, models.ErrPRMerged, err)
 }
 
+func TestPRRepository_Integration_Verdicts(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	repo := newPrRepository(dbPool)
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, id := range []string{"pr-1", "pr-2"} {
+		err := repo.CreatePR(ctx, models.PullRequest{
+			ID: id, Name: "Test PR", AuthorID: "u1", AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &now,
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, repo.SetVerdict(ctx, "pr-1", "u2", models.VerdictApproved))
+	assert.Equal(t, models.ErrNotAssigned, repo.SetVerdict(ctx, "pr-1", "u4", models.VerdictApproved))
+	assert.Equal(t, models.ErrNotFound, repo.SetVerdict(ctx, "pr-9", "u2", models.VerdictApproved))
+
+	reviewed, err := repo.GetReviewedAssignments(ctx, "team1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"pr-1": {"u2"}}, reviewed)
+
+	// ручное переназначение ревьювера с вердиктом снимает, а вердикт
+	// уносит в историю; перенос при выравнивании такого не снимает
+	assert.Equal(t, models.ErrReviewSubmitted, repo.MovePendingReviewer(ctx, "pr-1", "u2", "u4"))
+	require.NoError(t, repo.MovePendingReviewer(ctx, "pr-1", "u3", "u4"))
+	require.NoError(t, repo.ReassignReviewer(ctx, "pr-1", "u2", "u3"))
+
+	gotPR, err := repo.GetPR(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u3", "u4"}, gotPR.AssignedReviewers)
+
+	var verdict string
+	var verdictAt *time.Time
+	require.NoError(t, dbPool.QueryRow(ctx, `
+		SELECT verdict, verdict_at FROM reviewer_reassignments WHERE pr_id = 'pr-1' AND old_reviewer_id = 'u2'
+	`).Scan(&verdict, &verdictAt))
+	assert.Equal(t, models.VerdictApproved, verdict)
+	assert.NotNil(t, verdictAt)
+
+	_, err = repo.MergePR(ctx, "pr-2")
+	require.NoError(t, err)
+	assert.Equal(t, models.ErrPRMerged, repo.SetVerdict(ctx, "pr-2", "u2", models.VerdictChangesRequested))
+}
+
 func TestPRRepository_Integration_GetPRsByReviewer(t *testing.T) {
 	dbPool, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -247,3 +294,25 @@ func TestPRRepository_Integration_GetOpenPRsWithTeamReviewers(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, prsAfter, 0)
 }
+
+func TestPRRepository_Integration_CountOpenPRsByTeam(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	repo := newPrRepository(dbPool)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		require.NoError(t, repo.CreatePR(ctx, models.PullRequest{
+			ID: id, Name: "PR " + id, AuthorID: "u1", AssignedReviewers: []string{"u2"}, CreatedAt: &now,
+		}))
+	}
+	_, err := repo.MergePR(ctx, "pr-3")
+	require.NoError(t, err)
+
+	counts, err := repo.CountOpenPRsByTeam(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"team1": 2}, counts)
+}
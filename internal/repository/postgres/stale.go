@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type staleRepository struct {
+	db *pgxpool.Pool
+}
+
+func newStaleRepository(db *pgxpool.Pool) repository.StaleRepository {
+	return &staleRepository{db: db}
+}
+
+// активность PR — создание, переназначения и вердикты ревьюверов; шаги, сделанные до последней
+// активности, не считаются, так что ручное переназначение начинает цепочку
+// заново. Своё переназначение job записывает шагом позже, поэтому цепочку
+// оно не сбрасывает
+func (r *staleRepository) FindStalePRs(ctx context.Context, inactiveBefore time.Time) ([]models.StalePR, error) {
+	rows, err := r.db.Query(ctx, `
+        WITH activity AS (
+            SELECT p.id, p.name, p.author_id, u.team_name,
+                   GREATEST(p.created_at, (
+                       SELECT MAX(rr.reassigned_at) FROM reviewer_reassignments rr WHERE rr.pr_id = p.id
+                   ), (
+                       SELECT MAX(r.verdict_at) FROM pr_reviewers r WHERE r.pr_id = p.id
+                   )) AS last_activity
+            FROM pull_requests p
+            JOIN users u ON u.user_id = p.author_id
+            WHERE p.status = 'OPEN'
+        )
+        SELECT a.id, a.name, a.author_id, a.team_name, a.last_activity,
+               ARRAY(
+                   SELECT r.user_id
+                   FROM pr_reviewers r
+                   WHERE r.pr_id = a.id
+                   ORDER BY (
+                       SELECT MAX(rr.reassigned_at) FROM reviewer_reassignments rr
+                       WHERE rr.pr_id = r.pr_id AND rr.new_reviewer_id = r.user_id
+                   ) NULLS FIRST, r.user_id
+               ),
+               ARRAY(SELECT r.user_id FROM pr_reviewers r WHERE r.pr_id = a.id AND r.verdict IS NOT NULL ORDER BY r.user_id),
+               COALESCE(s.step, ''), s.created_at
+        FROM activity a
+        LEFT JOIN LATERAL (
+            SELECT step, created_at
+            FROM stale_pr_steps
+            WHERE pr_id = a.id AND created_at >= a.last_activity
+            ORDER BY id DESC
+            LIMIT 1
+        ) s ON TRUE
+        WHERE COALESCE(s.created_at, a.last_activity) < $1
+          AND s.step IS DISTINCT FROM 'escalate'
+        ORDER BY a.last_activity, a.id
+    `, inactiveBefore)
+	if err != nil {
+		return nil, fmt.Errorf("query stale PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []models.StalePR
+	for rows.Next() {
+		var pr models.StalePR
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.TeamName, &pr.LastActivity, &pr.Reviewers, &pr.Reviewed, &pr.LastStep, &pr.LastStepAt); err != nil {
+			return nil, fmt.Errorf("scan stale PR: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+	return prs, rows.Err()
+}
+
+func (r *staleRepository) RecordStep(ctx context.Context, step models.StaleStep, event *models.Event) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO stale_pr_steps (pr_id, step, reviewer_id, new_reviewer_id, lead_id, inactive_since, created_at)
+        VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7)
+    `, step.PRID, step.Step, step.ReviewerID, step.NewReviewerID, step.LeadID, step.InactiveSince, step.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert stale step: %w", err)
+	}
+
+	if event != nil {
+		if err := insertEvent(ctx, tx, *event); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStaleRepository_Integration_FindStalePRs(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	ctx := context.Background()
+	prRepo := newPrRepository(dbPool)
+	repo := newStaleRepository(dbPool)
+
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	fresh := time.Now().Add(-time.Hour)
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-old", Name: "Old", AuthorID: "u1", AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &old}))
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-fresh", Name: "Fresh", AuthorID: "u1", AssignedReviewers: []string{"u2"}, CreatedAt: &fresh}))
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-merged", Name: "Merged", AuthorID: "u1", AssignedReviewers: []string{"u2"}, CreatedAt: &old}))
+	_, err := prRepo.MergePR(ctx, "pr-merged")
+	require.NoError(t, err)
+
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	prs, err := repo.FindStalePRs(ctx, dayAgo)
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "pr-old", prs[0].ID)
+	assert.Equal(t, "team1", prs[0].TeamName)
+	assert.Equal(t, []string{"u2", "u3"}, prs[0].Reviewers)
+	assert.Empty(t, prs[0].LastStep)
+	assert.WithinDuration(t, old, prs[0].LastActivity, time.Millisecond)
+
+	remindAt := time.Now().Add(-2 * 24 * time.Hour)
+	require.NoError(t, repo.RecordStep(ctx, models.StaleStep{
+		PRID: "pr-old", Step: models.StaleStepRemind, InactiveSince: old, CreatedAt: remindAt,
+	}, &models.Event{Type: models.EventPRStaleReminder, OccurredAt: remindAt, PRID: "pr-old", TeamName: "team1"}))
+
+	prs, err = repo.FindStalePRs(ctx, dayAgo)
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, models.StaleStepRemind, prs[0].LastStep)
+	require.NotNil(t, prs[0].LastStepAt)
+	assert.WithinDuration(t, remindAt, *prs[0].LastStepAt, time.Millisecond)
+
+	var events int
+	require.NoError(t, dbPool.QueryRow(ctx, `SELECT COUNT(*) FROM outbox_events WHERE event_type = $1`, models.EventPRStaleReminder).Scan(&events))
+	assert.Equal(t, 1, events)
+
+	// ручное переназначение — новая активность, цепочка начинается заново
+	require.NoError(t, prRepo.ReassignReviewer(ctx, "pr-old", "u3", "u4"))
+	prs, err = repo.FindStalePRs(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, prs, 2)
+	assert.Equal(t, "pr-fresh", prs[0].ID)
+	assert.Equal(t, "pr-old", prs[1].ID)
+	assert.Empty(t, prs[1].LastStep)
+	// переназначенный только что ревьювер идёт последним
+	assert.Equal(t, []string{"u2", "u4"}, prs[1].Reviewers)
+
+	// после эскалации PR больше не выбирается
+	require.NoError(t, repo.RecordStep(ctx, models.StaleStep{
+		PRID: "pr-old", Step: models.StaleStepEscalate, LeadID: "lead", InactiveSince: old, CreatedAt: time.Now(),
+	}, nil))
+	prs, err = repo.FindStalePRs(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "pr-fresh", prs[0].ID)
+}
+
+func TestStaleRepository_Integration_VerdictIsActivity(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	ctx := context.Background()
+	prRepo := newPrRepository(dbPool)
+	repo := newStaleRepository(dbPool)
+
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-old", Name: "Old", AuthorID: "u1", AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &old}))
+	require.NoError(t, prRepo.SetVerdict(ctx, "pr-old", "u2", models.VerdictApproved))
+
+	prs, err := repo.FindStalePRs(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, prs, "a fresh verdict must count as activity")
+
+	prs, err = repo.FindStalePRs(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, []string{"u2", "u3"}, prs[0].Reviewers)
+	assert.Equal(t, []string{"u2"}, prs[0].Reviewed)
+}
+
+func TestStore_Integration_TryLock(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	store := &Store{db: dbPool}
+	ctx := context.Background()
+
+	var innerLocked bool
+	locked, err := store.TryLock(ctx, "test", func(ctx context.Context) error {
+		var err error
+		innerLocked, err = store.TryLock(ctx, "test", func(context.Context) error {
+			t.Fatal("lock taken twice")
+			return nil
+		})
+		return err
+	})
+	require.NoError(t, err)
+	assert.True(t, locked)
+	assert.False(t, innerLocked)
+
+	// блокировка снята
+	locked, err = store.TryLock(ctx, "test", func(context.Context) error { return nil })
+	require.NoError(t, err)
+	assert.True(t, locked)
+}
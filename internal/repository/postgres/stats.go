@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type statsRepository struct {
+	db *pgxpool.Pool
+}
+
+func newStatsRepository(db *pgxpool.Pool) repository.StatsRepository {
+	return &statsRepository{db: db}
+}
+
+func (r *statsRepository) GetReviewStats(ctx context.Context, filter models.StatsFilter) ([]models.UserReviewStats, error) {
+	var stats []models.UserReviewStats
+	err := r.EachReviewStats(ctx, filter, func(s models.UserReviewStats) error {
+		stats = append(stats, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *statsRepository) EachReviewStats(ctx context.Context, filter models.StatsFilter, fn func(models.UserReviewStats) error) error {
+	rows, err := r.db.Query(ctx, `
+        WITH prs AS (
+            SELECT id, author_id, status
+            FROM pull_requests
+            WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+              AND ($2::timestamptz IS NULL OR created_at < $2)
+              AND ($3 = '' OR status = $3)
+        ),
+        reviews AS (
+            SELECT r.user_id,
+                   COUNT(*) FILTER (WHERE p.status = 'OPEN') AS open_reviews,
+                   COUNT(*) FILTER (WHERE p.status = 'MERGED') AS merged_reviews
+            FROM pr_reviewers r
+            JOIN prs p ON p.id = r.pr_id
+            GROUP BY r.user_id
+        ),
+        authored AS (
+            SELECT author_id AS user_id, COUNT(*) AS authored_prs
+            FROM prs
+            GROUP BY author_id
+        ),
+        moves AS (
+            SELECT rr.old_reviewer_id, rr.new_reviewer_id
+            FROM reviewer_reassignments rr
+            JOIN pull_requests p ON p.id = rr.pr_id
+            WHERE ($1::timestamptz IS NULL OR rr.reassigned_at >= $1)
+              AND ($2::timestamptz IS NULL OR rr.reassigned_at < $2)
+              AND ($3 = '' OR p.status = $3)
+        ),
+        moved_in AS (
+            SELECT new_reviewer_id AS user_id, COUNT(*) AS n FROM moves GROUP BY new_reviewer_id
+        ),
+        moved_out AS (
+            SELECT old_reviewer_id AS user_id, COUNT(*) AS n FROM moves GROUP BY old_reviewer_id
+        )
+        SELECT u.user_id, u.username, u.team_name, u.is_active,
+               COALESCE(rv.open_reviews, 0),
+               COALESCE(rv.merged_reviews, 0),
+               COALESCE(a.authored_prs, 0),
+               COALESCE(mi.n, 0),
+               COALESCE(mo.n, 0)
+        FROM users u
+        LEFT JOIN reviews rv ON rv.user_id = u.user_id
+        LEFT JOIN authored a ON a.user_id = u.user_id
+        LEFT JOIN moved_in mi ON mi.user_id = u.user_id
+        LEFT JOIN moved_out mo ON mo.user_id = u.user_id
+        WHERE $4 = '' OR u.team_name = $4
+        ORDER BY COALESCE(rv.open_reviews, 0) + COALESCE(rv.merged_reviews, 0) DESC, u.user_id
+    `, filter.From, filter.To, filter.Status, filter.TeamName)
+	if err != nil {
+		return fmt.Errorf("query review stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.UserReviewStats
+		err := rows.Scan(&s.UserID, &s.Username, &s.TeamName, &s.IsActive,
+			&s.OpenReviews, &s.MergedReviews, &s.AuthoredPRs, &s.ReassignedIn, &s.ReassignedOut)
+		if err != nil {
+			return fmt.Errorf("scan review stats: %w", err)
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// выражение ключа группы и нужный join; group_by проверяет usecase,
+// сюда попадают только значения из этой таблицы
+var cycleTimeGroups = map[string]struct{ key, join string }{
+	models.CycleTimeByTeam:     {key: "a.team_name"},
+	models.CycleTimeByAuthor:   {key: "p.author_id"},
+	models.CycleTimeByReviewer: {key: "r.user_id", join: "JOIN pr_reviewers r ON r.pr_id = p.id"},
+}
+
+func (r *statsRepository) GetCycleTimes(ctx context.Context, filter models.CycleTimeFilter) ([]models.CycleTimeRow, error) {
+	group, ok := cycleTimeGroups[filter.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown cycle time grouping %q", filter.GroupBy)
+	}
+
+	// недели начинаются в понедельник по UTC; first_review NULL у PR без
+	// вердиктов, агрегаты по нему такие PR пропускают. Вердикты снятых
+	// ревьюверов лежат в истории переназначений
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+        WITH merged AS (
+            SELECT %s AS key,
+                   date_trunc('week', p.merged_at AT TIME ZONE 'UTC') AS week_start,
+                   EXTRACT(EPOCH FROM p.merged_at - p.created_at)::float8 AS seconds,
+                   EXTRACT(EPOCH FROM (
+                       SELECT MIN(v.verdict_at) FROM (
+                           SELECT verdict_at FROM pr_reviewers WHERE pr_id = p.id
+                           UNION ALL
+                           SELECT verdict_at FROM reviewer_reassignments WHERE pr_id = p.id
+                       ) v
+                   ) - p.created_at)::float8 AS first_review
+            FROM pull_requests p
+            JOIN users a ON a.user_id = p.author_id
+            %s
+            WHERE p.status = 'MERGED' AND p.merged_at IS NOT NULL
+              AND ($1::timestamptz IS NULL OR p.merged_at >= $1)
+              AND ($2::timestamptz IS NULL OR p.merged_at < $2)
+              AND ($3 = '' OR a.team_name = $3)
+        )
+        SELECT key, week_start, COUNT(*),
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds),
+               percentile_cont(0.9) WITHIN GROUP (ORDER BY seconds),
+               percentile_cont(0.99) WITHIN GROUP (ORDER BY seconds),
+               COUNT(first_review),
+               COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY first_review), 0),
+               COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY first_review), 0),
+               COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY first_review), 0)
+        FROM merged
+        GROUP BY GROUPING SETS ((key, week_start), (key))
+        ORDER BY key, week_start NULLS FIRST
+    `, group.key, group.join), filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("query cycle times: %w", err)
+	}
+
+	result, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.CycleTimeRow, error) {
+		var c models.CycleTimeRow
+		err := row.Scan(&c.Key, &c.WeekStart, &c.Count, &c.MedianSeconds, &c.P90Seconds, &c.P99Seconds,
+			&c.FirstReview.Count, &c.FirstReview.MedianSeconds, &c.FirstReview.P90Seconds, &c.FirstReview.P99Seconds)
+		return c, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan cycle times: %w", err)
+	}
+	return result, nil
+}
+
+func (r *statsRepository) GetFairness(ctx context.Context, filter models.StatsFilter) ([]models.FairnessRow, error) {
+	// назначение — текущий ревьювер PR или снятый с него при переназначении.
+	// Истории активности нет: кандидаты PR — активные сейчас участники
+	// команды и все, кто был на нём ревьювером
+	rows, err := r.db.Query(ctx, `
+        WITH prs AS (
+            SELECT p.id, p.author_id, a.team_name
+            FROM pull_requests p
+            JOIN users a ON a.user_id = p.author_id
+            WHERE ($1::timestamptz IS NULL OR p.created_at >= $1)
+              AND ($2::timestamptz IS NULL OR p.created_at < $2)
+              AND ($3 = '' OR a.team_name = $3)
+        ),
+        assigned AS (
+            SELECT r.pr_id, r.user_id
+            FROM pr_reviewers r
+            JOIN prs p ON p.id = r.pr_id
+            UNION ALL
+            SELECT rr.pr_id, rr.old_reviewer_id
+            FROM reviewer_reassignments rr
+            JOIN prs p ON p.id = rr.pr_id
+        ),
+        eligible AS (
+            SELECT p.id AS pr_id, u.user_id
+            FROM prs p
+            JOIN users u ON u.team_name = p.team_name AND u.is_active AND u.user_id <> p.author_id
+            UNION
+            SELECT a.pr_id, a.user_id
+            FROM assigned a
+            JOIN prs p ON p.id = a.pr_id
+            JOIN users u ON u.user_id = a.user_id AND u.team_name = p.team_name
+        ),
+        per_pr AS (
+            SELECT p.id AS pr_id,
+                   (SELECT COUNT(*) FROM assigned a WHERE a.pr_id = p.id) AS reviewers,
+                   (SELECT COUNT(*) FROM eligible e WHERE e.pr_id = p.id) AS candidates
+            FROM prs p
+        ),
+        expected AS (
+            SELECT e.user_id, SUM(pp.reviewers::float8 / pp.candidates) AS expected
+            FROM eligible e
+            JOIN per_pr pp ON pp.pr_id = e.pr_id
+            GROUP BY e.user_id
+        ),
+        actual AS (
+            SELECT a.user_id, COUNT(*) AS assignments
+            FROM assigned a
+            GROUP BY a.user_id
+        )
+        SELECT u.team_name, u.user_id, u.username, u.is_active,
+               COALESCE(a.assignments, 0), COALESCE(e.expected, 0)
+        FROM users u
+        LEFT JOIN actual a ON a.user_id = u.user_id
+        LEFT JOIN expected e ON e.user_id = u.user_id
+        WHERE $3 = '' OR u.team_name = $3
+        ORDER BY u.team_name, u.user_id
+    `, filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("query fairness: %w", err)
+	}
+
+	result, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.FairnessRow, error) {
+		var f models.FairnessRow
+		err := row.Scan(&f.TeamName, &f.UserID, &f.Username, &f.IsActive, &f.Assignments, &f.Expected)
+		return f, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan fairness: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStatsRepository_Integration_GetReviewStats(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	ctx := context.Background()
+	prRepo := newPrRepository(dbPool)
+	repo := newStatsRepository(dbPool)
+
+	old := time.Now().AddDate(0, -1, 0)
+	recent := time.Now().Add(-time.Hour)
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-old", Name: "Old", AuthorID: "u1", AssignedReviewers: []string{"u2"}, CreatedAt: &old}))
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-1", Name: "One", AuthorID: "u1", AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &recent}))
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-2", Name: "Two", AuthorID: "u2", AssignedReviewers: []string{"u3"}, CreatedAt: &recent}))
+	_, err := prRepo.MergePR(ctx, "pr-2")
+	require.NoError(t, err)
+	require.NoError(t, prRepo.ReassignReviewer(ctx, "pr-1", "u2", "u4"))
+
+	byUser := func(stats []models.UserReviewStats) map[string]models.ReviewLoad {
+		m := make(map[string]models.ReviewLoad, len(stats))
+		for _, s := range stats {
+			m[s.UserID] = s.ReviewLoad
+		}
+		return m
+	}
+
+	all, err := repo.GetReviewStats(ctx, models.StatsFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, models.ReviewLoad{AuthoredPRs: 2}, byUser(all)["u1"])
+	assert.Equal(t, models.ReviewLoad{OpenReviews: 1, AuthoredPRs: 1, ReassignedOut: 1}, byUser(all)["u2"])
+	assert.Equal(t, models.ReviewLoad{OpenReviews: 1, MergedReviews: 1}, byUser(all)["u3"])
+	assert.Equal(t, models.ReviewLoad{OpenReviews: 1, ReassignedIn: 1}, byUser(all)["u4"])
+
+	from := time.Now().AddDate(0, 0, -7)
+	window, err := repo.GetReviewStats(ctx, models.StatsFilter{From: &from})
+	require.NoError(t, err)
+	assert.Equal(t, models.ReviewLoad{AuthoredPRs: 1, ReassignedOut: 1}, byUser(window)["u2"])
+
+	merged, err := repo.GetReviewStats(ctx, models.StatsFilter{Status: models.StatusMerged, TeamName: "team1"})
+	require.NoError(t, err)
+	assert.Equal(t, models.ReviewLoad{MergedReviews: 1}, byUser(merged)["u3"])
+	assert.Equal(t, models.ReviewLoad{}, byUser(merged)["u4"])
+
+	none, err := repo.GetReviewStats(ctx, models.StatsFilter{TeamName: "other"})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestStatsRepository_Integration_GetCycleTimes(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	ctx := context.Background()
+	prRepo := newPrRepository(dbPool)
+	repo := newStatsRepository(dbPool)
+
+	// понедельник, неделя 2025-03-03
+	monday := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	merges := []struct {
+		id, author string
+		created    time.Time
+		took       time.Duration
+	}{
+		{"pr-1", "u1", monday, time.Hour},
+		{"pr-2", "u1", monday, 3 * time.Hour},
+		{"pr-3", "u2", monday.AddDate(0, 0, 7), 24 * time.Hour},
+	}
+	for _, m := range merges {
+		created := m.created
+		require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: m.id, Name: m.id, AuthorID: m.author, AssignedReviewers: []string{"u3"}, CreatedAt: &created}))
+		_, err := dbPool.Exec(ctx, `UPDATE pull_requests SET status = 'MERGED', merged_at = $2 WHERE id = $1`, m.id, created.Add(m.took))
+		require.NoError(t, err)
+	}
+	open := monday
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-open", Name: "open", AuthorID: "u1", CreatedAt: &open}))
+	// первый вердикт только у pr-1, через полчаса после создания
+	_, err := dbPool.Exec(ctx, `UPDATE pr_reviewers SET verdict = 'APPROVED', verdict_at = $2 WHERE pr_id = $1`, "pr-1", monday.Add(30*time.Minute))
+	require.NoError(t, err)
+
+	rows, err := repo.GetCycleTimes(ctx, models.CycleTimeFilter{GroupBy: models.CycleTimeByAuthor})
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, "u1", rows[0].Key)
+	assert.Nil(t, rows[0].WeekStart)
+	assert.Equal(t, 2, rows[0].Count)
+	assert.InDelta(t, 2*3600, rows[0].MedianSeconds, 1)
+	assert.Equal(t, 1, rows[0].FirstReview.Count, "PRs without a verdict are skipped")
+	assert.InDelta(t, 1800, rows[0].FirstReview.MedianSeconds, 1)
+
+	assert.Equal(t, "u1", rows[1].Key)
+	require.NotNil(t, rows[1].WeekStart)
+	assert.True(t, rows[1].WeekStart.Equal(time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)))
+
+	assert.Equal(t, "u2", rows[2].Key)
+	assert.InDelta(t, 24*3600, rows[2].P99Seconds, 1)
+	assert.Equal(t, models.CycleTime{}, rows[2].FirstReview)
+
+	to := monday.AddDate(0, 0, 7)
+	rows, err = repo.GetCycleTimes(ctx, models.CycleTimeFilter{GroupBy: models.CycleTimeByReviewer, To: &to, TeamName: "team1"})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "u3", rows[0].Key)
+	assert.Equal(t, 2, rows[0].Count)
+}
+
+func TestStatsRepository_Integration_GetFairness(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertPRTestData(t, dbPool)
+
+	ctx := context.Background()
+	prRepo := newPrRepository(dbPool)
+	repo := newStatsRepository(dbPool)
+
+	// у u1 два кандидата (u2, u3), у u2 — тоже два (u1, u3); pr-4 достался
+	// u4, его сняли и деактивировали, поэтому там кандидатов трое
+	now := time.Now()
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-1", Name: "1", AuthorID: "u1", AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &now}))
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-2", Name: "2", AuthorID: "u1", AssignedReviewers: []string{"u2"}, CreatedAt: &now}))
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-3", Name: "3", AuthorID: "u2", AssignedReviewers: []string{"u3"}, CreatedAt: &now}))
+	require.NoError(t, prRepo.CreatePR(ctx, models.PullRequest{ID: "pr-4", Name: "4", AuthorID: "u1", AssignedReviewers: []string{"u4"}, CreatedAt: &now}))
+	require.NoError(t, prRepo.ReassignReviewer(ctx, "pr-4", "u4", "u3"))
+	_, err := dbPool.Exec(ctx, `UPDATE users SET is_active = false WHERE user_id = 'u4'`)
+	require.NoError(t, err)
+
+	rows, err := repo.GetFairness(ctx, models.StatsFilter{TeamName: "team1"})
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	got := make(map[string]models.FairnessRow, len(rows))
+	for _, r := range rows {
+		got[r.UserID] = r
+	}
+	assert.Equal(t, 0, got["u1"].Assignments)
+	assert.InDelta(t, 0.5, got["u1"].Expected, 1e-9)
+	assert.Equal(t, 2, got["u2"].Assignments)
+	assert.InDelta(t, 1.5+2.0/3, got["u2"].Expected, 1e-9)
+	assert.Equal(t, 3, got["u3"].Assignments)
+	assert.InDelta(t, 2.0+2.0/3, got["u3"].Expected, 1e-9)
+	assert.False(t, got["u4"].IsActive)
+	assert.Equal(t, 1, got["u4"].Assignments, "a reassigned-away review still counts")
+	assert.InDelta(t, 2.0/3, got["u4"].Expected, 1e-9)
+}
@@ -1,29 +1,34 @@
 package postgres
 
 import (
+	"avito-pr-service/config"
 	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"time"
 )
 
 type Store struct {
 	db *pgxpool.Pool
 }
 
-func NewStore(ctx context.Context, dsn string) (*Store, error) {
-	config, err := pgxpool.ParseConfig(dsn)
+func NewStore(ctx context.Context, cfg config.DBConfig) (*Store, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN)
 	if err != nil {
 		return nil, fmt.Errorf("parse dsn: %w", err)
 	}
 
-	config.MaxConns = 10
-	config.MinConns = 2
-	config.MaxConnLifetime = time.Hour
-	config.MaxConnIdleTime = 30 * time.Minute
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolConfig.ConnConfig.Tracer = tracing.NewQueryTracer()
 
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("create pool: %w", err)
 	}
@@ -43,8 +48,79 @@ func (s *Store) User() repository.UserRepository { return newUserRepository(s.db
 
 func (s *Store) PR() repository.PRRepository { return newPrRepository(s.db) }
 
+func (s *Store) Stats() repository.StatsRepository { return newStatsRepository(s.db) }
+
+func (s *Store) Webhook() repository.WebhookRepository { return newWebhookRepository(s.db) }
+
+func (s *Store) Outbox() repository.OutboxRepository { return newOutboxRepository(s.db) }
+
+func (s *Store) EventLog() repository.EventLogRepository { return newEventLogRepository(s.db) }
+
+func (s *Store) Jobs() repository.JobRepository { return newJobRepository(s.db) }
+
+func (s *Store) VCSIdentity() repository.VCSIdentityRepository { return newVCSIdentityRepository(s.db) }
+
+func (s *Store) Archive() repository.ArchiveRepository { return newArchiveRepository(s.db) }
+
+func (s *Store) Stale() repository.StaleRepository { return newStaleRepository(s.db) }
+
+func (s *Store) Deactivation() repository.DeactivationRepository {
+	return newDeactivationRepository(s.db)
+}
+
+func (s *Store) Stat() *pgxpool.Stat { return s.db.Stat() }
+
+func (s *Store) Ping(ctx context.Context) error { return s.db.Ping(ctx) }
+
+// таблицу schema_migrations ведёт golang-migrate
+func (s *Store) SchemaVersion(ctx context.Context) (int64, bool, error) {
+	var version int64
+	var dirty bool
+	err := s.db.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
 func (s *Store) Close() {
 	if s.db != nil {
 		s.db.Close()
 	}
 }
+
+// TryLock выполняет fn под сессионной advisory-блокировкой name и сразу
+// возвращает false, если блокировку держит другой процесс. Блокировка живёт
+// на отдельном соединении, fn работает через общий пул
+func (s *Store) TryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("acquire lock connection: %w", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, name).Scan(&locked); err != nil {
+		return false, fmt.Errorf("try advisory lock: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+
+	defer func() {
+		_, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext($1))`, name)
+		if err != nil {
+			// соединение с неснятой блокировкой нельзя возвращать в пул:
+			// закрываем его, и Postgres снимет блокировку сам
+			_ = conn.Hijack().Close(context.WithoutCancel(ctx))
+		}
+	}()
+	return true, fn(ctx)
+}
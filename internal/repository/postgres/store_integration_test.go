@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"avito-pr-service/migrations"
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStore_Integration_SchemaVersion(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	store := &Store{db: dbPool}
+	ctx := context.Background()
+
+	require.NoError(t, store.Ping(ctx))
+
+	version, dirty, err := store.SchemaVersion(ctx)
+	require.NoError(t, err)
+	require.False(t, dirty)
+	latest, err := migrations.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, latest, version)
+
+	_, err = dbPool.Exec(ctx, `DROP TABLE schema_migrations`)
+	require.NoError(t, err)
+
+	version, _, err = store.SchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Zero(t, version)
+}
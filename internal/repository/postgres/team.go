@@ -85,3 +85,170 @@ func (r *teamRepository) GetTeam(ctx context.Context, name string) (models.Team,
 
 	return team, nil
 }
+
+func (r *teamRepository) ListTeams(ctx context.Context) ([]models.Team, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT t.name, u.user_id, u.username, u.is_active
+		FROM teams t
+		LEFT JOIN users u ON u.team_name = t.name
+		ORDER BY t.name, u.user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []models.Team{}
+	for rows.Next() {
+		var name string
+		var userID, username *string
+		var isActive *bool
+		if err := rows.Scan(&name, &userID, &username, &isActive); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		if len(teams) == 0 || teams[len(teams)-1].Name != name {
+			teams = append(teams, models.Team{Name: name, Members: []models.TeamMember{}})
+		}
+		if userID != nil {
+			last := &teams[len(teams)-1]
+			last.Members = append(last.Members, models.TeamMember{UserID: *userID, Username: *username, IsActive: *isActive})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	return teams, nil
+}
+
+func (r *teamRepository) ImportTeams(ctx context.Context, rows []models.ImportRow, dryRun bool) (models.ImportDiff, error) {
+	diff := models.ImportDiff{
+		TeamsCreated: []string{},
+		UsersCreated: []models.ImportUserChange{},
+		UsersUpdated: []models.ImportUserChange{},
+	}
+
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return diff, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	teamNames := make([]string, 0)
+	userIDs := make([]string, 0, len(rows))
+	seenTeams := make(map[string]bool)
+	for _, row := range rows {
+		if !seenTeams[row.TeamName] {
+			seenTeams[row.TeamName] = true
+			teamNames = append(teamNames, row.TeamName)
+		}
+		userIDs = append(userIDs, row.UserID)
+	}
+
+	existingTeams := make(map[string]bool)
+	teamRows, err := tx.Query(ctx, `SELECT name FROM teams WHERE name = ANY($1)`, teamNames)
+	if err != nil {
+		return diff, fmt.Errorf("query teams: %w", err)
+	}
+	for teamRows.Next() {
+		var name string
+		if err := teamRows.Scan(&name); err != nil {
+			teamRows.Close()
+			return diff, fmt.Errorf("scan team: %w", err)
+		}
+		existingTeams[name] = true
+	}
+	teamRows.Close()
+	if err := teamRows.Err(); err != nil {
+		return diff, fmt.Errorf("rows: %w", err)
+	}
+
+	// блокируем существующих пользователей, чтобы diff совпал с тем, что будет записано
+	existingUsers := make(map[string]models.User)
+	userRows, err := tx.Query(ctx, `
+		SELECT user_id, username, team_name, is_active
+		FROM users
+		WHERE user_id = ANY($1)
+		FOR UPDATE
+	`, userIDs)
+	if err != nil {
+		return diff, fmt.Errorf("query users: %w", err)
+	}
+	for userRows.Next() {
+		var u models.User
+		if err := userRows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			userRows.Close()
+			return diff, fmt.Errorf("scan user: %w", err)
+		}
+		existingUsers[u.UserID] = u
+	}
+	userRows.Close()
+	if err := userRows.Err(); err != nil {
+		return diff, fmt.Errorf("rows: %w", err)
+	}
+
+	for _, name := range teamNames {
+		if !existingTeams[name] {
+			diff.TeamsCreated = append(diff.TeamsCreated, name)
+		}
+	}
+
+	var changed []models.ImportRow
+	for _, row := range rows {
+		existing, ok := existingUsers[row.UserID]
+		if !ok {
+			diff.UsersCreated = append(diff.UsersCreated, models.ImportUserChange{UserID: row.UserID, TeamName: row.TeamName})
+			changed = append(changed, row)
+			continue
+		}
+
+		var changes []models.ImportFieldChange
+		if existing.Username != row.Username {
+			changes = append(changes, models.ImportFieldChange{Field: "username", Old: existing.Username, New: row.Username})
+		}
+		if existing.TeamName != row.TeamName {
+			changes = append(changes, models.ImportFieldChange{Field: "team_name", Old: existing.TeamName, New: row.TeamName})
+		}
+		if existing.IsActive != row.IsActive {
+			changes = append(changes, models.ImportFieldChange{Field: "is_active", Old: existing.IsActive, New: row.IsActive})
+		}
+		if len(changes) == 0 {
+			diff.UsersUnchanged++
+			continue
+		}
+		diff.UsersUpdated = append(diff.UsersUpdated, models.ImportUserChange{UserID: row.UserID, TeamName: row.TeamName, Changes: changes})
+		changed = append(changed, row)
+	}
+
+	if dryRun {
+		return diff, nil
+	}
+
+	if len(diff.TeamsCreated) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO teams (name) SELECT unnest($1::text[]) ON CONFLICT DO NOTHING
+		`, diff.TeamsCreated)
+		if err != nil {
+			return diff, fmt.Errorf("insert teams: %w", err)
+		}
+	}
+
+	for _, row := range changed {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO users (user_id, username, team_name, is_active)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id) DO UPDATE SET
+				username = EXCLUDED.username,
+				team_name = EXCLUDED.team_name,
+				is_active = EXCLUDED.is_active
+		`, row.UserID, row.Username, row.TeamName, row.IsActive)
+		if err != nil {
+			return diff, fmt.Errorf("upsert user %s: %w", row.UserID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return diff, fmt.Errorf("commit transaction: %w", err)
+	}
+	return diff, nil
+}
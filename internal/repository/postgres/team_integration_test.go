@@ -64,3 +64,89 @@ func TestTeamRepository_Integration_GetTeam(t *testing.T) {
 	_, err = repo.GetTeam(ctx, "nonexistent")
 	assert.Equal(t, models.ErrTeamNotFound, err)
 }
+
+func TestTeamRepository_Integration_ListTeams(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := newTeamRepository(dbPool)
+
+	ctx := context.Background()
+	teams, err := repo.ListTeams(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, teams)
+
+	require.NoError(t, repo.CreateTeam(ctx, models.Team{
+		Name: "frontend",
+		Members: []models.TeamMember{
+			{UserID: "u3", Username: "User3", IsActive: true},
+		},
+	}))
+	require.NoError(t, repo.CreateTeam(ctx, models.Team{
+		Name: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u2", Username: "User2", IsActive: false},
+			{UserID: "u1", Username: "User1", IsActive: true},
+		},
+	}))
+
+	teams, err = repo.ListTeams(ctx)
+	require.NoError(t, err)
+	require.Len(t, teams, 2)
+	assert.Equal(t, "backend", teams[0].Name)
+	require.Len(t, teams[0].Members, 2)
+	assert.Equal(t, "u1", teams[0].Members[0].UserID)
+	assert.Equal(t, "frontend", teams[1].Name)
+	assert.Len(t, teams[1].Members, 1)
+}
+
+func TestTeamRepository_Integration_ImportTeams(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := newTeamRepository(dbPool)
+
+	ctx := context.Background()
+	require.NoError(t, repo.CreateTeam(ctx, models.Team{
+		Name: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "User1", IsActive: true},
+			{UserID: "u2", Username: "User2", IsActive: true},
+		},
+	}))
+
+	rows := []models.ImportRow{
+		{TeamName: "backend", UserID: "u1", Username: "User1", IsActive: true},
+		{TeamName: "backend", UserID: "u2", Username: "User2", IsActive: false},
+		{TeamName: "frontend", UserID: "u3", Username: "User3", IsActive: true},
+	}
+
+	diff, err := repo.ImportTeams(ctx, rows, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend"}, diff.TeamsCreated)
+	assert.Equal(t, []models.ImportUserChange{{UserID: "u3", TeamName: "frontend"}}, diff.UsersCreated)
+	require.Len(t, diff.UsersUpdated, 1)
+	assert.Equal(t, "u2", diff.UsersUpdated[0].UserID)
+	assert.Equal(t, []models.ImportFieldChange{{Field: "is_active", Old: true, New: false}}, diff.UsersUpdated[0].Changes)
+	assert.Equal(t, 1, diff.UsersUnchanged)
+
+	// dry run ничего не записывает
+	_, err = repo.GetTeam(ctx, "frontend")
+	assert.ErrorIs(t, err, models.ErrTeamNotFound)
+
+	applied, err := repo.ImportTeams(ctx, rows, false)
+	require.NoError(t, err)
+	assert.Equal(t, diff, applied)
+
+	team, err := repo.GetTeam(ctx, "frontend")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 1)
+
+	// повторный импорт того же файла ничего не меняет
+	again, err := repo.ImportTeams(ctx, rows, false)
+	require.NoError(t, err)
+	assert.Empty(t, again.TeamsCreated)
+	assert.Empty(t, again.UsersCreated)
+	assert.Empty(t, again.UsersUpdated)
+	assert.Equal(t, 3, again.UsersUnchanged)
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"slices"
+	"time"
 )
 
 type userRepository struct {
@@ -49,8 +51,87 @@ func (r *userRepository) GetUser(ctx context.Context, userID string) (models.Use
 	return u, nil
 }
 
-func (r *userRepository) DeactivateTeam(ctx context.Context, teamName string) (int, error) {
-	cmd, err := r.db.Exec(ctx, `
+func (r *userRepository) DeactivateTeam(ctx context.Context, teamName string, reassignedPRs int) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// синхронная деактивация не пишет снимок поверх асинхронной; проверка
+	// в транзакции ловит асинхронную, начатую после проверки в usecase
+	var deactivating bool
+	err = tx.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM team_deactivations WHERE team_name = $1 AND status IN ('PENDING', 'RUNNING'))
+    `, teamName).Scan(&deactivating)
+	if err != nil {
+		return 0, fmt.Errorf("check active deactivation: %w", err)
+	}
+	if deactivating {
+		return 0, models.ErrTeamDeactivating
+	}
+
+	deactivated, err := deactivateTeamUsers(ctx, tx, teamName, reassignedPRs)
+	if err != nil {
+		return 0, err
+	}
+	return deactivated, tx.Commit(ctx)
+}
+
+// deactivateTeamUsers общий для синхронной и асинхронной деактивации:
+// снимок состояния и событие team.deactivated пишутся в той же транзакции;
+// reassignedPRs — сколько PR деактивация уже переназначила, для события
+func deactivateTeamUsers(ctx context.Context, tx pgx.Tx, teamName string, reassignedPRs int) (int, error) {
+	var (
+		userIDs   []string
+		wasActive []bool
+		anyActive bool
+	)
+	rows, err := tx.Query(ctx, `
+        SELECT user_id, is_active
+        FROM users
+        WHERE team_name = $1
+        FOR UPDATE
+    `, teamName)
+	if err != nil {
+		return 0, fmt.Errorf("lock team users: %w", err)
+	}
+	for rows.Next() {
+		var (
+			userID   string
+			isActive bool
+		)
+		if err := rows.Scan(&userID, &isActive); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan team user: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+		wasActive = append(wasActive, isActive)
+		anyActive = anyActive || isActive
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("lock team users: %w", err)
+	}
+	// выключать некого: снимок предыдущей деактивации не затираем,
+	// иначе /team/activate после повторного вызова никого бы не вернул
+	if !anyActive {
+		return 0, nil
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM team_member_snapshots WHERE team_name = $1`, teamName)
+	if err != nil {
+		return 0, fmt.Errorf("delete team snapshot: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO team_member_snapshots (team_name, user_id, was_active)
+        SELECT $1, unnest($2::text[]), unnest($3::bool[])
+    `, teamName, userIDs, wasActive)
+	if err != nil {
+		return 0, fmt.Errorf("insert team snapshot: %w", err)
+	}
+
+	cmd, err := tx.Exec(ctx, `
         UPDATE users 
         SET is_active = false 
         WHERE team_name = $1 AND is_active = true
@@ -58,5 +139,74 @@ func (r *userRepository) DeactivateTeam(ctx context.Context, teamName string) (i
 	if err != nil {
 		return 0, err
 	}
-	return int(cmd.RowsAffected()), nil
+	deactivated := int(cmd.RowsAffected())
+
+	if deactivated > 0 {
+		err = insertEvent(ctx, tx, models.Event{
+			Type: models.EventTeamDeactivated, OccurredAt: time.Now(), TeamName: teamName,
+			Data: models.TeamDeactivatedData{TeamName: teamName, DeactivatedUsers: deactivated, ReassignedPRs: reassignedPRs},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return deactivated, nil
+}
+
+func (r *userRepository) ActivateTeam(ctx context.Context, teamName string) ([]string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var deactivating, hasSnapshot bool
+	err = tx.QueryRow(ctx, `
+        SELECT
+            EXISTS (SELECT 1 FROM team_deactivations WHERE team_name = $1 AND status IN ('PENDING', 'RUNNING')),
+            EXISTS (SELECT 1 FROM team_member_snapshots WHERE team_name = $1)
+    `, teamName).Scan(&deactivating, &hasSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("check team snapshot: %w", err)
+	}
+	// асинхронная деактивация ещё выключит пользователей в конце
+	if deactivating {
+		return nil, models.ErrTeamDeactivating
+	}
+	if !hasSnapshot {
+		return nil, models.ErrTeamNotDeactivated
+	}
+
+	// перешедшие с тех пор в другую команду не трогаем
+	rows, err := tx.Query(ctx, `
+        UPDATE users u
+        SET is_active = true
+        FROM team_member_snapshots s
+        WHERE s.team_name = $1 AND s.was_active
+          AND u.user_id = s.user_id AND u.team_name = s.team_name AND NOT u.is_active
+        RETURNING u.user_id
+    `, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("activate team users: %w", err)
+	}
+	activated, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("activate team users: %w", err)
+	}
+	slices.Sort(activated)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM team_member_snapshots WHERE team_name = $1`, teamName); err != nil {
+		return nil, fmt.Errorf("delete team snapshot: %w", err)
+	}
+
+	if len(activated) > 0 {
+		err = insertEvent(ctx, tx, models.Event{
+			Type: models.EventTeamActivated, OccurredAt: time.Now(), TeamName: teamName,
+			Data: models.TeamActivatedData{TeamName: teamName, ActivatedUsers: activated},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return activated, tx.Commit(ctx)
 }
@@ -65,7 +65,7 @@ func TestUserRepository_Integration_DeactivateTeam(t *testing.T) {
 	repo := newUserRepository(dbPool)
 
 	ctx := context.Background()
-	count, err := repo.DeactivateTeam(ctx, "team1")
+	count, err := repo.DeactivateTeam(ctx, "team1", 0)
 	require.NoError(t, err)
 	assert.Equal(t, 1, count)
 
@@ -77,7 +77,46 @@ func TestUserRepository_Integration_DeactivateTeam(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, u2.IsActive)
 
-	count, err = repo.DeactivateTeam(ctx, "nonexistent")
+	count, err = repo.DeactivateTeam(ctx, "nonexistent", 0)
 	require.NoError(t, err)
 	assert.Equal(t, 0, count)
 }
+
+func TestUserRepository_Integration_ActivateTeam(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertUserTestData(t, dbPool)
+
+	repo := newUserRepository(dbPool)
+
+	ctx := context.Background()
+	_, err := repo.ActivateTeam(ctx, "team1")
+	assert.Equal(t, models.ErrTeamNotDeactivated, err)
+
+	_, err = repo.DeactivateTeam(ctx, "team1", 0)
+	require.NoError(t, err)
+	// повторная деактивация не затирает снимок
+	count, err := repo.DeactivateTeam(ctx, "team1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	activated, err := repo.ActivateTeam(ctx, "team1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, activated)
+
+	u1, err := repo.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.True(t, u1.IsActive)
+
+	u2, err := repo.GetUser(ctx, "u2")
+	require.NoError(t, err)
+	assert.False(t, u2.IsActive)
+
+	var events int
+	err = dbPool.QueryRow(ctx, `SELECT count(*) FROM outbox_events WHERE event_type = $1`, models.EventTeamActivated).Scan(&events)
+	require.NoError(t, err)
+	assert.Equal(t, 1, events)
+
+	_, err = repo.ActivateTeam(ctx, "team1")
+	assert.Equal(t, models.ErrTeamNotDeactivated, err)
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type vcsIdentityRepository struct {
+	db *pgxpool.Pool
+}
+
+func newVCSIdentityRepository(db *pgxpool.Pool) repository.VCSIdentityRepository {
+	return &vcsIdentityRepository{db: db}
+}
+
+func (r *vcsIdentityRepository) SetIdentity(ctx context.Context, identity models.VCSIdentity) error {
+	_, err := r.db.Exec(ctx, `
+        INSERT INTO vcs_identities (provider, login, user_id)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (provider, login) DO UPDATE SET user_id = EXCLUDED.user_id
+    `, identity.Provider, identity.Login, identity.UserID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return models.ErrUserNotFound
+		}
+		return fmt.Errorf("upsert identity: %w", err)
+	}
+	return nil
+}
+
+func (r *vcsIdentityRepository) ResolveUser(ctx context.Context, provider, login string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(ctx, `
+        SELECT user_id FROM vcs_identities WHERE provider = $1 AND login = $2
+    `, provider, login).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrIdentityNotFound
+		}
+		return "", fmt.Errorf("query identity: %w", err)
+	}
+	return userID, nil
+}
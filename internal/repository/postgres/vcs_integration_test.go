@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestVCSIdentityRepository_Integration(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+	insertUserTestData(t, dbPool)
+
+	repo := newVCSIdentityRepository(dbPool)
+	ctx := context.Background()
+
+	_, err := repo.ResolveUser(ctx, models.VCSProviderGitHub, "octo-alice")
+	assert.Equal(t, models.ErrIdentityNotFound, err)
+
+	require.NoError(t, repo.SetIdentity(ctx, models.VCSIdentity{Provider: models.VCSProviderGitHub, Login: "octo-alice", UserID: "u1"}))
+	userID, err := repo.ResolveUser(ctx, models.VCSProviderGitHub, "octo-alice")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", userID)
+
+	require.NoError(t, repo.SetIdentity(ctx, models.VCSIdentity{Provider: models.VCSProviderGitHub, Login: "octo-alice", UserID: "u2"}))
+	userID, err = repo.ResolveUser(ctx, models.VCSProviderGitHub, "octo-alice")
+	require.NoError(t, err)
+	assert.Equal(t, "u2", userID)
+
+	_, err = repo.ResolveUser(ctx, models.VCSProviderGitLab, "octo-alice")
+	assert.Equal(t, models.ErrIdentityNotFound, err)
+
+	err = repo.SetIdentity(ctx, models.VCSIdentity{Provider: models.VCSProviderGitHub, Login: "ghost", UserID: "nobody"})
+	assert.Equal(t, models.ErrUserNotFound, err)
+}
@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type webhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func newWebhookRepository(db *pgxpool.Pool) repository.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	err := r.db.QueryRow(ctx, `
+        INSERT INTO webhook_subscriptions (url, secret, event_types)
+        VALUES ($1, $2, $3)
+        RETURNING id, is_active, created_at
+    `, sub.URL, sub.Secret, sub.EventTypes).Scan(&sub.ID, &sub.IsActive, &sub.CreatedAt)
+	if err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("insert subscription: %w", err)
+	}
+	return sub, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, url, event_types, is_active, created_at
+        FROM webhook_subscriptions
+        ORDER BY id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		var s models.WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.URL, &s.EventTypes, &s.IsActive, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan subscription: %w", err)
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete subscription: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// доставки уже переданного события не создаются заново: outbox может
+// повторить событие, если упал между вызовом sink и отметкой об отправке
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error) {
+	res, err := r.db.Exec(ctx, `
+        INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+        SELECT id, $1, $2, $3
+        FROM webhook_subscriptions
+        WHERE is_active AND $2 = ANY(event_types)
+        ON CONFLICT (subscription_id, event_id) DO NOTHING
+    `, eventID, eventType, payload)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue deliveries: %w", err)
+	}
+	return int(res.RowsAffected()), nil
+}
+
+// доставки забираются с арендой: next_attempt_at сдвигается вперёд, чтобы
+// другая реплика не взяла ту же доставку, пока эта её отправляет
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.Query(ctx, `
+        UPDATE webhook_deliveries d
+        SET next_attempt_at = NOW() + make_interval(secs => $2)
+        FROM (
+            SELECT id FROM webhook_deliveries
+            WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+            ORDER BY next_attempt_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        ) due, webhook_subscriptions s
+        WHERE d.id = due.id AND s.id = d.subscription_id
+        RETURNING d.id, d.subscription_id, d.event_type, d.payload, d.status, d.attempts,
+                  d.next_attempt_at, d.created_at, s.url, s.secret
+    `, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			return nil, fmt.Errorf("scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// аренда доставки — пара (id, attempts) из ClaimDueDeliveries: каждая
+// записанная попытка увеличивает attempts, и воркер, переживший аренду,
+// не перезапишет результат более новой попытки
+func (r *webhookRepository) MarkDelivered(ctx context.Context, delivery models.WebhookDelivery) error {
+	res, err := r.db.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'DELIVERED', attempts = attempts + 1, delivered_at = NOW(), last_error = NULL
+        WHERE id = $1 AND status = 'PENDING' AND attempts = $2
+    `, delivery.ID, delivery.Attempts)
+	if err != nil {
+		return fmt.Errorf("mark delivered: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *webhookRepository) MarkFailed(ctx context.Context, delivery models.WebhookDelivery, attempts int, nextAttemptAt time.Time, lastErr string, dead bool) error {
+	status := models.DeliveryPending
+	if dead {
+		status = models.DeliveryDead
+	}
+
+	res, err := r.db.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = $3, attempts = $4, next_attempt_at = $5, last_error = $6
+        WHERE id = $1 AND status = 'PENDING' AND attempts = $2
+    `, delivery.ID, delivery.Attempts, status, attempts, nextAttemptAt, lastErr)
+	if err != nil {
+		return fmt.Errorf("mark failed: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *webhookRepository) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.Exec(ctx, `
+        DELETE FROM webhook_deliveries
+        WHERE status = 'DELIVERED' AND delivered_at < $1
+    `, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete delivered webhook deliveries: %w", err)
+	}
+	return int(res.RowsAffected()), nil
+}
+
+func (r *webhookRepository) ListDeadLetters(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.Query(ctx, `
+        SELECT id, subscription_id, event_type, payload, status, attempts,
+               next_attempt_at, COALESCE(last_error, ''), created_at
+        FROM webhook_deliveries
+        WHERE status = 'DEAD'
+        ORDER BY created_at DESC
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan dead letter: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *webhookRepository) RetryDelivery(ctx context.Context, id int64) error {
+	res, err := r.db.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'PENDING', attempts = 0, next_attempt_at = NOW()
+        WHERE id = $1 AND status = 'DEAD'
+    `, id)
+	if err != nil {
+		return fmt.Errorf("retry delivery: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return models.ErrDeliveryNotFound
+	}
+	return nil
+}
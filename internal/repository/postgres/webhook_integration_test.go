@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWebhookRepository_Integration_DeliveryLifecycle(t *testing.T) {
+	dbPool, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := newWebhookRepository(dbPool)
+	ctx := context.Background()
+
+	sub, err := repo.CreateSubscription(ctx, models.WebhookSubscription{
+		URL:        "https://hooks.example.com/pr",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{models.EventPRMerged},
+	})
+	require.NoError(t, err)
+	assert.True(t, sub.IsActive)
+
+	n, err := repo.EnqueueDeliveries(ctx, 1, models.EventPRCreated, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = repo.EnqueueDeliveries(ctx, 2, models.EventPRMerged, []byte(`{"pull_request_id":"pr-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// outbox повторил событие после падения: вторая доставка не создаётся
+	n, err = repo.EnqueueDeliveries(ctx, 2, models.EventPRMerged, []byte(`{"pull_request_id":"pr-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	claimed, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, sub.URL, claimed[0].URL)
+	assert.Equal(t, "0123456789abcdef", claimed[0].Secret)
+
+	again, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again, "leased delivery must not be claimed twice")
+
+	require.NoError(t, repo.MarkFailed(ctx, claimed[0], 8, time.Now(), "timeout", true))
+	// тот же результат от воркера, пережившего аренду, не записывается
+	require.ErrorIs(t, repo.MarkFailed(ctx, claimed[0], 1, time.Now(), "late", false), repository.ErrLeaseLost)
+
+	dead, err := repo.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "timeout", dead[0].LastError)
+
+	require.NoError(t, repo.RetryDelivery(ctx, claimed[0].ID))
+	assert.Equal(t, models.ErrDeliveryNotFound, repo.RetryDelivery(ctx, claimed[0].ID))
+
+	claimed, err = repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.NoError(t, repo.MarkDelivered(ctx, claimed[0]))
+	require.ErrorIs(t, repo.MarkDelivered(ctx, claimed[0]), repository.ErrLeaseLost)
+
+	n, err = repo.DeleteDelivered(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "fresh deliveries are kept")
+	n, err = repo.DeleteDelivered(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.NoError(t, repo.DeleteSubscription(ctx, sub.ID))
+	assert.Equal(t, models.ErrWebhookNotFound, repo.DeleteSubscription(ctx, sub.ID))
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+	"time"
+)
+
+const RoleAdmin = "admin"
+
+type Principal struct {
+	UserID string
+	Roles  []string
+}
+
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Principal, error)
+}
+
+type JWTValidator struct {
+	keys        *KeySet
+	parser      *jwt.Parser
+	userIDClaim string
+	rolesClaim  string
+}
+
+func NewJWTValidator(keys *KeySet, issuer, audience, userIDClaim, rolesClaim string) *JWTValidator {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+		// пустые значения отсекает config.Validate: без них подошёл бы любой
+		// токен, подписанный ключом из JWKS, в том числе выданный другому сервису
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+	}
+
+	return &JWTValidator{
+		keys:        keys,
+		parser:      jwt.NewParser(opts...),
+		userIDClaim: userIDClaim,
+		rolesClaim:  rolesClaim,
+	}
+}
+
+func (v *JWTValidator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return Principal{}, fmt.Errorf("parse token: %w", err)
+	}
+
+	userID, _ := claims[v.userIDClaim].(string)
+	if userID == "" {
+		return Principal{}, fmt.Errorf("claim %q is missing", v.userIDClaim)
+	}
+
+	roles, err := parseRoles(claims[v.rolesClaim])
+	if err != nil {
+		return Principal{}, fmt.Errorf("claim %q: %w", v.rolesClaim, err)
+	}
+
+	return Principal{UserID: userID, Roles: roles}, nil
+}
+
+// SSO отдаёт роли либо массивом, либо строкой через пробел (как scope)
+func parseRoles(raw any) ([]string, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.Fields(v), nil
+	case []any:
+		roles := make([]string, 0, len(v))
+		for _, r := range v {
+			s, ok := r.(string)
+			if !ok {
+				return nil, errors.New("roles must be strings")
+			}
+			roles = append(roles, s)
+		}
+		return roles, nil
+	default:
+		return nil, errors.New("unsupported roles format")
+	}
+}
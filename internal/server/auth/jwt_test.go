@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "pr-service"
+)
+
+func b64(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "RSA", Kid: kid, Use: "sig", Alg: "RS256",
+		N: b64(key.N), E: b64(big.NewInt(int64(key.E))),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) jwk {
+	return jwk{
+		Kty: "EC", Kid: kid, Use: "sig", Alg: "ES256", Crv: "P-256",
+		X: b64(key.X), Y: b64(key.Y),
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...jwk) string {
+	data, err := json.Marshal(jwkSet{Keys: keys})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "u1",
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin", "reviewer"},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	tok := jwt.NewWithClaims(method, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func newRSAValidator(t *testing.T) (*JWTValidator, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ks, err := LoadKeySetFile(writeJWKS(t, rsaJWK("k1", key)), testLogger())
+	require.NoError(t, err)
+	return NewJWTValidator(ks, testIssuer, testAudience, "sub", "roles"), key
+}
+
+func TestJWTValidator_ValidToken(t *testing.T) {
+	v, key := newRSAValidator(t)
+
+	p, err := v.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", key, validClaims()))
+	require.NoError(t, err)
+	require.Equal(t, "u1", p.UserID)
+	require.ElementsMatch(t, []string{"admin", "reviewer"}, p.Roles)
+	require.True(t, p.HasRole(RoleAdmin))
+}
+
+func TestJWTValidator_Rejects(t *testing.T) {
+	v, key := newRSAValidator(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		token  func() string
+		modify func(jwt.MapClaims)
+	}{
+		{name: "expired", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "no expiry", modify: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "wrong issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "no issuer", modify: func(c jwt.MapClaims) { delete(c, "iss") }},
+		{name: "wrong audience", modify: func(c jwt.MapClaims) { c["aud"] = "other-service" }},
+		{name: "audience list without ours", modify: func(c jwt.MapClaims) { c["aud"] = []string{"other-service", "billing"} }},
+		{name: "no audience", modify: func(c jwt.MapClaims) { delete(c, "aud") }},
+		{name: "no subject", modify: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "foreign signature", token: func() string {
+			return sign(t, jwt.SigningMethodRS256, "k1", otherKey, validClaims())
+		}},
+		{name: "unknown kid", token: func() string {
+			return sign(t, jwt.SigningMethodRS256, "k2", key, validClaims())
+		}},
+		{name: "hmac algorithm", token: func() string {
+			return sign(t, jwt.SigningMethodHS256, "k1", []byte("secret"), validClaims())
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var token string
+			if tt.token != nil {
+				token = tt.token()
+			} else {
+				claims := validClaims()
+				tt.modify(claims)
+				token = sign(t, jwt.SigningMethodRS256, "k1", key, claims)
+			}
+			_, err := v.Authenticate(context.Background(), token)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestJWTValidator_ECKeyFromURL(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwkSet{Keys: []jwk{ecJWK("ec1", key)}})
+	}))
+	defer srv.Close()
+
+	ks, err := LoadKeySetURL(context.Background(), srv.URL, srv.Client(), testLogger())
+	require.NoError(t, err)
+	v := NewJWTValidator(ks, testIssuer, testAudience, "sub", "roles")
+
+	claims := validClaims()
+	claims["roles"] = "reviewer lead"
+	p, err := v.Authenticate(context.Background(), sign(t, jwt.SigningMethodES256, "ec1", key, claims))
+	require.NoError(t, err)
+	require.Equal(t, "u1", p.UserID)
+	require.Equal(t, []string{"reviewer", "lead"}, p.Roles)
+}
+
+func TestKeySet_FailingURLFetchedOncePerInterval(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// первая загрузка при старте успешна, потом SSO недоступен
+		if hits.Add(1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(jwkSet{Keys: []jwk{rsaJWK("k1", key)}})
+	}))
+	defer srv.Close()
+
+	ks, err := LoadKeySetURL(context.Background(), srv.URL, srv.Client(), testLogger())
+	require.NoError(t, err)
+
+	// в пределах интервала неизвестный kid не идёт в SSO
+	_, err = ks.Key(context.Background(), "k2")
+	require.Error(t, err)
+	require.EqualValues(t, 1, hits.Load())
+
+	ks.lastRefresh = time.Now().Add(-2 * jwksRefreshInterval)
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ks.Key(context.Background(), "k2")
+			require.Error(t, err)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 2, hits.Load())
+
+	// неудачная загрузка тоже откладывает следующую на интервал
+	_, err = ks.Key(context.Background(), "k3")
+	require.Error(t, err)
+	require.EqualValues(t, 2, hits.Load())
+
+	_, err = ks.Key(context.Background(), "k1")
+	require.NoError(t, err)
+}
+
+func TestLoadKeySetFile_SkipsUnsupportedKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ed := jwk{Kty: "OKP", Kid: "ed1", Use: "sig", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	secp := jwk{Kty: "EC", Kid: "k256", Use: "sig", Crv: "secp256k1", X: "AA", Y: "AA"}
+
+	ks, err := LoadKeySetFile(writeJWKS(t, ed, rsaJWK("k1", key), secp), testLogger())
+	require.NoError(t, err)
+	_, err = ks.Key(context.Background(), "k1")
+	require.NoError(t, err)
+	_, err = ks.Key(context.Background(), "ed1")
+	require.Error(t, err)
+
+	_, err = LoadKeySetFile(writeJWKS(t, ed, secp), testLogger())
+	require.ErrorContains(t, err, "jwks contains no usable signing keys")
+}
+
+func TestJWTValidator_CustomUserIDClaim(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ks, err := LoadKeySetFile(writeJWKS(t, rsaJWK("k1", key)), testLogger())
+	require.NoError(t, err)
+	v := NewJWTValidator(ks, testIssuer, testAudience, "preferred_username", "groups")
+
+	claims := validClaims()
+	claims["preferred_username"] = "u42"
+	claims["groups"] = []string{"admin"}
+	p, err := v.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", key, claims))
+	require.NoError(t, err)
+	require.Equal(t, "u42", p.UserID)
+	require.Equal(t, []string{"admin"}, p.Roles)
+}
+
+func TestMiddleware(t *testing.T) {
+	v, key := newRSAValidator(t)
+	log := testLogger()
+
+	var got Principal
+	h := Middleware(v, log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.Header.Set("Authorization", "Bearer not-a-jwt")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.Header.Set("Authorization", "Bearer "+sign(t, jwt.SigningMethodRS256, "k1", key, validClaims()))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "u1", got.UserID)
+}
+
+func TestRequireRole(t *testing.T) {
+	h := RequireRole(RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(WithPrincipal(req.Context(), Principal{UserID: "u1", Roles: []string{"reviewer"}}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(WithPrincipal(req.Context(), Principal{UserID: "u1", Roles: []string{RoleAdmin}}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"avito-pr-service/internal/logging"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/server/response"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
+
+func Middleware(a Authenticator, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				response.Error(w, r, models.ErrUnauthorized, http.StatusUnauthorized)
+				return
+			}
+
+			p, err := a.Authenticate(r.Context(), token)
+			if err != nil {
+				log.WarnContext(r.Context(), "token rejected", "error", err, "path", r.URL.Path)
+				response.Error(w, r, models.ErrUnauthorized, http.StatusUnauthorized)
+				return
+			}
+
+			logging.SetActor(r.Context(), p.UserID)
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if !ok || !p.HasRole(role) {
+				response.Error(w, r, models.ErrForbidden, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
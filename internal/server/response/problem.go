@@ -0,0 +1,92 @@
+package response
+
+import (
+	"avito-pr-service/internal/models"
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const ContentTypeProblem = "application/problem+json"
+
+// базовый путь type URI; к нему добавляется код ошибки в kebab-case,
+// правило описано в README («Формат ошибок»)
+const problemTypeBase = "/problems/"
+
+// Problem — ответ об ошибке по RFC 7807; code дублирует старый
+// формат, чтобы клиенты могли переходить постепенно
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	Errors   any    `json:"errors,omitempty"`
+}
+
+// FieldError описывает одно нарушенное правило validator
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProblemType возвращает type URI для кода ошибки: TEAM_EXISTS -> /problems/team-exists
+func ProblemType(code models.ErrorCode) string {
+	return problemTypeBase + strings.ReplaceAll(strings.ToLower(string(code)), "_", "-")
+}
+
+// WantsProblem сообщает, просит ли клиент application/problem+json;
+// формат включается только явным упоминанием в Accept с q не ниже,
+// чем у application/json
+func WantsProblem(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+
+	problemQ, jsonQ := 0.0, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case ContentTypeProblem:
+			problemQ = max(problemQ, q)
+		case "application/json", "application/*", "*/*":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return problemQ > 0 && problemQ >= jsonQ
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code models.ErrorCode, message string, details any) {
+	p := Problem{
+		Type:     ProblemType(code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   message,
+		Instance: requestInstance(r),
+		Code:     string(code),
+		Errors:   details,
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Error("failed to encode problem", "error", err)
+	}
+}
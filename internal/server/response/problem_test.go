@@ -0,0 +1,158 @@
+package response
+
+import (
+	"avito-pr-service/internal/logging"
+	"avito-pr-service/internal/models"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/json, application/problem+json", true},
+		{"application/problem+json;q=0.5, application/json", false},
+		{"application/json;q=0.9, application/problem+json", true},
+		{"application/problem+json;q=0", false},
+		{"text/html, */*;q=0.1, application/problem+json;q=0.2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+			assert.Equal(t, tt.want, WantsProblem(r))
+		})
+	}
+}
+
+func TestProblemType(t *testing.T) {
+	assert.Equal(t, "/problems/team-exists", ProblemType(models.ErrorTeamExists))
+	assert.Equal(t, "/problems/not-found", ProblemType(models.ErrorNotFound))
+}
+
+func TestError_Problem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/team/add", nil)
+	r = r.WithContext(logging.WithRequestID(r.Context(), "req-1"))
+	r.Header.Set("Accept", ContentTypeProblem)
+	w := httptest.NewRecorder()
+
+	Error(w, r, models.ErrTeamExists, http.StatusBadRequest)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, ContentTypeProblem, w.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+
+	var p Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, "/problems/team-exists", p.Type)
+	assert.Equal(t, "Conflict", p.Title)
+	assert.Equal(t, http.StatusConflict, p.Status)
+	assert.Equal(t, "team already exists", p.Detail)
+	assert.Equal(t, "urn:request-id:req-1", p.Instance)
+	assert.Equal(t, "TEAM_EXISTS", p.Code)
+	assert.Nil(t, p.Errors)
+}
+
+func TestValidationError_Problem(t *testing.T) {
+	err := models.Validate(&models.Team{
+		Name:    "backend",
+		Members: []models.TeamMember{{UserID: "u1"}},
+	})
+	require.Error(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/team/add", nil)
+	r.Header.Set("Accept", ContentTypeProblem)
+	w := httptest.NewRecorder()
+
+	ValidationError(w, r, err)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var body struct {
+		Type   string       `json:"type"`
+		Code   string       `json:"code"`
+		Errors []FieldError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "/problems/validation-error", body.Type)
+	assert.Equal(t, "VALIDATION_ERROR", body.Code)
+	require.Len(t, body.Errors, 1)
+	assert.Equal(t, FieldError{
+		Field:   "members[0].username",
+		Tag:     "required",
+		Message: "username is required",
+	}, body.Errors[0])
+}
+
+func TestValidationError_MinMessageByKind(t *testing.T) {
+	tests := []struct {
+		name string
+		req  any
+		want FieldError
+	}{
+		{
+			name: "number",
+			req:  &models.RebalanceTeamRequest{TeamName: "backend", MinGap: 1},
+			want: FieldError{Field: "min_gap", Tag: "min", Param: "2", Message: "min_gap must be at least 2"},
+		},
+		{
+			name: "string",
+			req:  &models.CreateWebhookRequest{URL: "https://hooks.example.com", Secret: "short", EventTypes: []string{models.EventPRMerged}},
+			want: FieldError{Field: "secret", Tag: "min", Param: "16", Message: "secret must be at least 16 characters"},
+		},
+		{
+			name: "slice",
+			req:  &models.CreateWebhookRequest{URL: "https://hooks.example.com", Secret: "0123456789abcdef", EventTypes: []string{}},
+			want: FieldError{Field: "event_types", Tag: "min", Param: "1", Message: "event_types must contain at least 1 items"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.Validate(tt.req)
+			require.Error(t, err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("Accept", ContentTypeProblem)
+			w := httptest.NewRecorder()
+			ValidationError(w, r, err)
+
+			var body struct {
+				Errors []FieldError `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Equal(t, []FieldError{tt.want}, body.Errors)
+		})
+	}
+}
+
+func TestValidationError_LegacyFormat(t *testing.T) {
+	err := models.Validate(&models.Team{})
+	require.Error(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/team/add", nil)
+	w := httptest.NewRecorder()
+
+	ValidationError(w, r, err)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.NotContains(t, body, "errors")
+	errMap := body["error"].(map[string]any)
+	assert.Equal(t, "VALIDATION_ERROR", errMap["code"])
+	assert.Equal(t, "team_name is required, members is required", errMap["message"])
+}
@@ -1,13 +1,15 @@
 package response
 
 import (
+	"avito-pr-service/internal/logging"
 	"avito-pr-service/internal/models"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
-	"log"
+	"log/slog"
 	"net/http"
+	"reflect"
 	"strings"
 )
 
@@ -15,70 +17,118 @@ func JSON(w http.ResponseWriter, data any, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("failed to encode JSON: %v", err)
+		slog.Error("failed to encode JSON", "error", err)
 	}
 }
 
-func Error(w http.ResponseWriter, err error, defaultStatus int) {
+func Error(w http.ResponseWriter, r *http.Request, err error, defaultStatus int) {
 	var appErr models.AppError
 	if errors.As(err, &appErr) {
 		status := defaultStatus
 		switch appErr.Code {
-		case models.ErrorTeamExists, models.ErrorPRExists, models.ErrorPRMerged, models.ErrorNoCandidate, models.ErrorNotAssigned:
+		case models.ErrorTeamExists, models.ErrorPRExists, models.ErrorPRMerged, models.ErrorNoCandidate, models.ErrorNotAssigned,
+			models.ErrorNotDeactivated, models.ErrorDeactivating, models.ErrorReviewSubmitted:
 			status = http.StatusConflict
 		case models.ErrorNotFound:
 			status = http.StatusNotFound
+		case models.ErrorUnauthorized:
+			status = http.StatusUnauthorized
+		case models.ErrorForbidden:
+			status = http.StatusForbidden
 		}
-		JSON(w, map[string]any{
-			"error": map[string]string{
-				"code":    string(appErr.Code),
-				"message": appErr.Message,
-			},
-		}, status)
+		Fail(w, r, status, appErr.Code, appErr.Message, nil)
 		return
 	}
 
-	JSON(w, map[string]any{
-		"error": map[string]string{
-			"code":    "INTERNAL",
-			"message": "server error",
-		},
-	}, http.StatusInternalServerError)
+	Fail(w, r, http.StatusInternalServerError, models.ErrorInternal, "server error", nil)
 }
 
-func ValidationError(w http.ResponseWriter, err error) {
+func ValidationError(w http.ResponseWriter, r *http.Request, err error) {
 	var validationErrors validator.ValidationErrors
 	if !errors.As(err, &validationErrors) {
-		BadRequest(w, "invalid request")
+		BadRequest(w, r, "invalid request")
 		return
 	}
 
 	messages := make([]string, 0, len(validationErrors))
+	fields := make([]FieldError, 0, len(validationErrors))
 	for _, e := range validationErrors {
 		field := e.Field()
+		var message string
 		switch e.Tag() {
 		case "required":
-			messages = append(messages, fmt.Sprintf("%s is required", field))
+			message = fmt.Sprintf("%s is required", field)
 		case "min":
-			messages = append(messages, fmt.Sprintf("%s must be at least %s characters", field, e.Param()))
+			message = minMessage(field, e)
 		default:
-			messages = append(messages, fmt.Sprintf("%s is invalid", field))
+			message = fmt.Sprintf("%s is invalid", field)
 		}
+		messages = append(messages, message)
+		fields = append(fields, FieldError{Field: fieldPath(e), Tag: e.Tag(), Param: e.Param(), Message: message})
 	}
 
-	JSON(w, map[string]any{
-		"error": map[string]string{
-			"code":    "VALIDATION_ERROR",
-			"message": strings.Join(messages, ", "),
-		},
-	}, http.StatusBadRequest)
+	message := strings.Join(messages, ", ")
+	if WantsProblem(r) {
+		writeProblem(w, r, http.StatusBadRequest, models.ErrorValidation, message, fields)
+		return
+	}
+	writeError(w, http.StatusBadRequest, models.ErrorValidation, message, nil)
+}
+
+func BadRequest(w http.ResponseWriter, r *http.Request, message string) {
+	Fail(w, r, http.StatusBadRequest, models.ErrorValidation, message, nil)
+}
+
+// Fail отвечает ошибкой с произвольным статусом; details попадают в
+// поле errors в обоих форматах
+func Fail(w http.ResponseWriter, r *http.Request, status int, code models.ErrorCode, message string, details any) {
+	if WantsProblem(r) {
+		writeProblem(w, r, status, code, message, details)
+		return
+	}
+	writeError(w, status, code, message, details)
 }
 
-func BadRequest(w http.ResponseWriter, message string) {
-	JSON(w, map[string]any{
+func writeError(w http.ResponseWriter, status int, code models.ErrorCode, message string, details any) {
+	body := map[string]any{
 		"error": map[string]string{
-			"code":    "VALIDATION_ERROR",
+			"code":    string(code),
 			"message": message,
 		},
-	}, http.StatusBadRequest)
+	}
+	if details != nil {
+		body["errors"] = details
+	}
+	w.Header().Add("Vary", "Accept")
+	JSON(w, body, status)
+}
+
+// min у validator означает длину строки, число элементов коллекции
+// или само значение для чисел
+func minMessage(field string, e validator.FieldError) string {
+	switch e.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%s must be at least %s characters", field, e.Param())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("%s must contain at least %s items", field, e.Param())
+	default:
+		return fmt.Sprintf("%s must be at least %s", field, e.Param())
+	}
+}
+
+// полный путь без имени корневой структуры: members[0].user_id
+func fieldPath(e validator.FieldError) string {
+	ns := e.Namespace()
+	if i := strings.IndexByte(ns, '.'); i >= 0 {
+		return ns[i+1:]
+	}
+	return ns
+}
+
+func requestInstance(r *http.Request) string {
+	id := logging.RequestID(r.Context())
+	if id == "" {
+		return ""
+	}
+	return "urn:request-id:" + id
 }
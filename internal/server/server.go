@@ -3,93 +3,360 @@ package server
 import (
 	"avito-pr-service/config"
 	"avito-pr-service/internal/handler"
+	"avito-pr-service/internal/health"
+	"avito-pr-service/internal/jobs"
+	"avito-pr-service/internal/logging"
+	"avito-pr-service/internal/metrics"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/outbox"
 	"avito-pr-service/internal/repository/postgres"
+	"avito-pr-service/internal/server/auth"
+	"avito-pr-service/internal/stale"
+	"avito-pr-service/internal/stream"
+	"avito-pr-service/internal/tracing"
 	"avito-pr-service/internal/usecase"
+	"avito-pr-service/internal/webhook"
+	"avito-pr-service/migrations"
 	"context"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"log/slog"
 	"net/http"
 	"os"
+	"sync"
+	"time"
 )
 
 type Server struct {
-	http  *http.Server
-	store *postgres.Store
-	log   *slog.Logger
+	http     *http.Server
+	store    *postgres.Store
+	webhooks *webhook.Dispatcher
+	outbox   *outbox.Dispatcher
+	jobs     *jobs.Runner
+	stream   *stream.Broker
+	health   *health.Checker
+	log      *slog.Logger
+
+	drainDelay time.Duration
+
+	shutdownTracing func(context.Context) error
+
+	runCtx context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 func New(cfg config.Config) (*Server, error) {
 	ctx := context.Background()
 
-	store, err := postgres.NewStore(ctx, cfg.DSN)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	log := logging.New(cfg.Log, os.Stdout).With("service", "avito-pr-service")
+	// через slog.Default пишут пакеты без своего логгера, например response
+	slog.SetDefault(log)
+
+	if cfg.MigrateOnStart {
+		if err := migrate(cfg.DB.DSN, log); err != nil {
+			_ = shutdownTracing(ctx)
+			return nil, err
+		}
+	}
+
+	store, err := postgres.NewStore(ctx, cfg.DB)
+	if err != nil {
+		_ = shutdownTracing(ctx)
+		return nil, err
+	}
+
+	authenticator, err := newAuthenticator(ctx, cfg, log)
+	if err != nil {
+		store.Close()
+		_ = shutdownTracing(ctx)
+		return nil, err
+	}
+
+	expectedVersion, err := migrations.LatestVersion()
 	if err != nil {
+		store.Close()
+		_ = shutdownTracing(ctx)
 		return nil, err
 	}
+	checker := health.NewChecker(store, expectedVersion, log)
 
-	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	})).With("service", "avito-pr-service")
+	for _, c := range []prometheus.Collector{
+		metrics.NewPoolCollector(store.Stat),
+		metrics.NewOpenPRCollector(store.PR(), log),
+	} {
+		if err := prometheus.Register(c); err != nil {
+			store.Close()
+			_ = shutdownTracing(ctx)
+			return nil, fmt.Errorf("register metrics: %w", err)
+		}
+	}
 
 	teamRepository := store.Team()
 	userRepository := store.User()
 	prRepository := store.PR()
+	webhookRepository := store.Webhook()
+
+	webhooks := webhook.NewDispatcher(webhookRepository, log)
+	webhooks.Retention = cfg.Outbox.Retention
+	events := outbox.NewDispatcher(store.Outbox(), log, webhooks)
+	events.Retention = cfg.Outbox.Retention
+	broker := stream.NewBroker(store.EventLog(), log)
+	broker.PollInterval = cfg.Stream.PollInterval
 
 	userUC := usecase.NewUserUsecase(userRepository, log)
 	prUC := usecase.NewPRUsecase(prRepository, userRepository, teamRepository, log)
-	teamUC := usecase.NewTeamUsecase(teamRepository, userRepository, prRepository, prUC, log)
+	teamUC := usecase.NewTeamUsecase(teamRepository, userRepository, prRepository, store.Deactivation(), prUC, log)
+	webhookUC := usecase.NewWebhookUsecase(webhookRepository, log)
+	integrationUC := usecase.NewIntegrationUsecase(store.VCSIdentity(), prUC, log)
+	statsUC := usecase.NewStatsUsecase(store.Stats(), teamRepository, log)
+	rebalanceUC := usecase.NewRebalanceUsecase(teamRepository, prRepository, models.RebalanceLimits{
+		MaxMoves: cfg.Rebalance.MaxMoves,
+		MinGap:   cfg.Rebalance.MinGap,
+	}, log)
+
+	jobUC := usecase.NewJobUsecase(store.Jobs(), log)
+
+	runner := jobs.NewRunner(store.Jobs(), log)
+	runner.Workers = cfg.Jobs.Workers
+	runner.PollInterval = cfg.Jobs.PollInterval
+	runner.Retention = cfg.Jobs.Retention
+	runner.Register(models.JobTeamDeactivation, teamUC.RunDeactivation)
+	runner.Register(models.JobTeamRebalance, rebalanceUC.RunScheduled)
+	runner.Register(outbox.KindCleanup, events.Cleanup)
+	if err := runner.Schedule(outbox.KindCleanup, "@hourly"); err != nil {
+		store.Close()
+		_ = shutdownTracing(ctx)
+		return nil, err
+	}
+	runner.Register(webhook.KindCleanup, webhooks.Cleanup)
+	if err := runner.Schedule(webhook.KindCleanup, "@hourly"); err != nil {
+		store.Close()
+		_ = shutdownTracing(ctx)
+		return nil, err
+	}
+	if cfg.Rebalance.Schedule != "" {
+		if err := runner.Schedule(models.JobTeamRebalance, cfg.Rebalance.Schedule); err != nil {
+			store.Close()
+			_ = shutdownTracing(ctx)
+			return nil, err
+		}
+	}
+	if cfg.StalePRs.Enabled {
+		staleUC := usecase.NewStaleUsecase(store.Stale(), prUC, stalePolicy(cfg.StalePRs), log)
+		runner.Register(stale.KindCheck, stale.NewChecker(staleUC, store, log).Handle)
+		if err := runner.Schedule(stale.KindCheck, "@every "+cfg.StalePRs.Interval.String()); err != nil {
+			store.Close()
+			_ = shutdownTracing(ctx)
+			return nil, err
+		}
+	}
 
 	teamHandler := handler.NewTeamHandler(teamUC, log)
 	userHandler := handler.NewUserHandler(userUC, log)
 	prHandler := handler.NewPRHandler(prUC, log)
+	statsHandler := handler.NewStatsHandler(statsUC, log)
+	rebalanceHandler := handler.NewRebalanceHandler(rebalanceUC, log)
+	webhookHandler := handler.NewWebhookHandler(webhookUC, log)
+	integrationHandler := handler.NewIntegrationHandler(integrationUC, log)
+	importHandler := handler.NewImportHandler(teamUC, log)
+	jobHandler := handler.NewJobHandler(jobUC, log)
+	streamHandler := handler.NewStreamHandler(broker, cfg.Stream.Heartbeat, log)
+	configHandler, err := handler.NewConfigHandler(cfg)
+	if err != nil {
+		store.Close()
+		_ = shutdownTracing(ctx)
+		return nil, err
+	}
 
 	r := chi.NewRouter()
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "traceparent", "tracestate", logging.RequestIDHeader},
+		ExposedHeaders:   []string{logging.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
 	r.Use(c.Handler)
-	r.Use(middleware.Logger)
+	r.Use(tracing.Middleware)
+	r.Use(logging.Middleware(log))
 	r.Use(middleware.Recoverer)
+	r.Use(metrics.Middleware)
 
 	// редирект на сваггер
-	r.Get("/docs", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "http://localhost:8081", http.StatusFound)
-	})
+	if cfg.DocsURL != "" {
+		r.Get("/docs", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, cfg.DocsURL, http.StatusFound)
+		})
+	}
+
+	r.Handle("/metrics", promhttp.Handler())
+	checker.Register(r)
+
+	// входящие вебхуки VCS аутентифицируются своей подписью, а не bearer-токеном
+	if cfg.GitHubWebhookSecret != "" {
+		handler.NewGitHubHandler(integrationUC, cfg.GitHubWebhookSecret, log).Register(r)
+	}
+	if cfg.GitLabWebhookToken != "" {
+		handler.NewGitLabHandler(integrationUC, cfg.GitLabWebhookToken, log).Register(r)
+	}
+
+	r.Group(func(r chi.Router) {
+		if authenticator != nil {
+			r.Use(auth.Middleware(authenticator, log))
+		}
+		teamHandler.Register(r)
+		userHandler.Register(r)
+		prHandler.Register(r)
+		statsHandler.Register(r)
+		rebalanceHandler.Register(r)
+		streamHandler.Register(r)
 
-	teamHandler.Register(r)
-	userHandler.Register(r)
-	prHandler.Register(r)
+		r.Group(func(r chi.Router) {
+			if authenticator != nil {
+				r.Use(auth.RequireRole(auth.RoleAdmin))
+			}
+			webhookHandler.Register(r)
+			integrationHandler.Register(r)
+			importHandler.Register(r)
+			configHandler.Register(r)
+			jobHandler.Register(r)
+		})
+	})
 
 	httpSrv := &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: r,
 	}
+	// открытые потоки событий не завершатся сами, и Shutdown ждал бы их до таймаута
+	httpSrv.RegisterOnShutdown(broker.Close)
+
+	runCtx, cancel := context.WithCancel(context.Background())
 
 	return &Server{
-		http:  httpSrv,
-		store: store,
-		log:   log,
+		http:     httpSrv,
+		store:    store,
+		webhooks: webhooks,
+		outbox:   events,
+		jobs:     runner,
+		stream:   broker,
+		health:   checker,
+		log:      log,
+
+		drainDelay: cfg.ShutdownDrainDelay,
+
+		shutdownTracing: shutdownTracing,
+
+		runCtx: runCtx,
+		cancel: cancel,
 	}, nil
 }
 
+func migrate(dsn string, log *slog.Logger) error {
+	m, err := postgres.NewMigrator(dsn, log)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	from, to, err := m.Up()
+	if err != nil {
+		return err
+	}
+	log.Info("schema is up to date", "from", from, "to", to)
+	return nil
+}
+
+func stalePolicy(cfg config.StalePRsConfig) models.StalePolicy {
+	policy := models.StalePolicy{Threshold: cfg.Threshold, Teams: make(map[string]models.StaleTeamPolicy, len(cfg.Teams))}
+	for name, team := range cfg.Teams {
+		policy.Teams[name] = models.StaleTeamPolicy{Threshold: team.Threshold, LeadID: team.Lead}
+	}
+	return policy
+}
+
+func newAuthenticator(ctx context.Context, cfg config.Config, log *slog.Logger) (auth.Authenticator, error) {
+	switch cfg.AuthMode {
+	case "", config.AuthModeNone:
+		return nil, nil
+	case config.AuthModeJWT:
+		var (
+			keys *auth.KeySet
+			err  error
+		)
+		switch {
+		case cfg.JWT.JWKSFile != "":
+			keys, err = auth.LoadKeySetFile(cfg.JWT.JWKSFile, log)
+		case cfg.JWT.JWKSURL != "":
+			keys, err = auth.LoadKeySetURL(ctx, cfg.JWT.JWKSURL, nil, log)
+		default:
+			return nil, fmt.Errorf("auth mode %q requires JWT_JWKS_FILE or JWT_JWKS_URL", cfg.AuthMode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load jwks: %w", err)
+		}
+		return auth.NewJWTValidator(keys, cfg.JWT.Issuer, cfg.JWT.Audience, cfg.JWT.UserIDClaim, cfg.JWT.RolesClaim), nil
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", cfg.AuthMode)
+	}
+}
+
 func (s *Server) Start() error {
+	s.wg.Add(4)
+	go func() {
+		defer s.wg.Done()
+		s.outbox.Run(s.runCtx)
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.webhooks.Run(s.runCtx)
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.jobs.Run(s.runCtx)
+	}()
+	go func() {
+		defer s.wg.Done()
+		s.stream.Run(s.runCtx)
+	}()
+
 	s.log.Info("server starting", "addr", s.http.Addr)
 	return s.http.ListenAndServe()
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
-	s.log.Info("shutting down server...")
+	s.log.Info("shutting down server")
+
+	s.health.SetDraining()
+	select {
+	case <-time.After(s.drainDelay):
+	case <-ctx.Done():
+	}
 
 	if err := s.http.Shutdown(ctx); err != nil {
 		s.log.Error("http shutdown error", "err", err)
 	}
 
+	s.cancel()
+	s.wg.Wait()
+
 	s.store.Close()
 
+	// после остановки воркеров, чтобы их последние спаны успели уйти
+	if err := s.shutdownTracing(ctx); err != nil {
+		s.log.Error("tracing shutdown error", "err", err)
+	}
+
+	s.log.Info("server stopped")
 	return nil
 }
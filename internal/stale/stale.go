@@ -0,0 +1,51 @@
+package stale
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/usecase"
+	"context"
+	"log/slog"
+)
+
+// KindCheck — повторяющаяся задача очереди jobs, которая делает проход
+const KindCheck = "stale_prs.check"
+
+// имя advisory-блокировки: проход делает только одна реплика, даже если
+// задача по какой-то причине запустилась дважды
+const lockName = "stale_prs"
+
+// Locker выполняет fn, только если удалось взять блокировку; false — её
+// держит кто-то другой
+type Locker interface {
+	TryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
+
+type Checker struct {
+	uc     usecase.StaleUsecase
+	locker Locker
+	log    *slog.Logger
+}
+
+func NewChecker(uc usecase.StaleUsecase, locker Locker, log *slog.Logger) *Checker {
+	return &Checker{
+		uc:     uc,
+		locker: locker,
+		log:    log.With("component", "stale"),
+	}
+}
+
+// Handle — обработчик задачи KindCheck; занятая блокировка ошибкой не считается
+func (c *Checker) Handle(ctx context.Context, _ models.Job) error {
+	locked, err := c.locker.TryLock(ctx, lockName, func(ctx context.Context) error {
+		result, err := c.uc.ProcessStalePRs(ctx)
+		if result.Reminded+result.Reassigned+result.Escalated > 0 {
+			c.log.InfoContext(ctx, "stale PRs processed",
+				"reminded", result.Reminded, "reassigned", result.Reassigned, "escalated", result.Escalated)
+		}
+		return err
+	})
+	if err == nil && !locked {
+		c.log.DebugContext(ctx, "stale PR check is running on another replica")
+	}
+	return err
+}
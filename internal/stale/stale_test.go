@@ -0,0 +1,54 @@
+package stale
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+type fakeLocker struct {
+	held bool
+	name string
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	l.name = name
+	if l.held {
+		return false, nil
+	}
+	return true, fn(ctx)
+}
+
+type fakeStaleUsecase struct {
+	calls int
+	err   error
+}
+
+func (u *fakeStaleUsecase) ProcessStalePRs(ctx context.Context) (models.StaleRunResult, error) {
+	u.calls++
+	return models.StaleRunResult{Reminded: 1}, u.err
+}
+
+func TestChecker_Handle(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	uc := &fakeStaleUsecase{}
+	locker := &fakeLocker{}
+	c := NewChecker(uc, locker, log)
+
+	require.NoError(t, c.Handle(context.Background(), models.Job{Kind: KindCheck}))
+	assert.Equal(t, 1, uc.calls)
+	assert.Equal(t, lockName, locker.name)
+
+	uc.err = errors.New("pr pr-1: db is down")
+	assert.ErrorIs(t, c.Handle(context.Background(), models.Job{Kind: KindCheck}), uc.err)
+
+	// блокировку держит другая реплика
+	locker.held = true
+	require.NoError(t, c.Handle(context.Background(), models.Job{Kind: KindCheck}))
+	assert.Equal(t, 2, uc.calls)
+}
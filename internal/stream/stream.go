@@ -0,0 +1,361 @@
+package stream
+
+import (
+	"avito-pr-service/internal/metrics"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrClosed   = errors.New("event stream is closed")
+	ErrNotReady = errors.New("event stream is not ready")
+	// ErrResumeExpired — события после Last-Event-ID уже удалены по сроку
+	// хранения, продолжить поток без пропусков нельзя
+	ErrResumeExpired = errors.New("events after Last-Event-ID are past retention")
+)
+
+// Filter пустыми полями ничего не ограничивает
+type Filter struct {
+	UserID   string
+	TeamName string
+	PRID     string
+	Types    []string
+}
+
+// userFields — поля Data, в которых события упоминают пользователей
+var userFields = []string{"author_id", "reviewer_id", "old_reviewer_id", "new_reviewer_id", "lead_id"}
+
+var userListFields = []string{"assigned_reviewers", "reviewer_ids", "activated_users"}
+
+func (f Filter) Match(event models.Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+	if f.TeamName != "" && event.TeamName != f.TeamName {
+		return false
+	}
+	if f.PRID != "" && event.PRID != f.PRID {
+		return false
+	}
+	if f.UserID != "" && !mentionsUser(event.Data, f.UserID) {
+		return false
+	}
+	return true
+}
+
+func mentionsUser(data any, userID string) bool {
+	fields, ok := data.(map[string]any)
+	if !ok {
+		// события из журнала всегда приходят декодированными в map, типизированные
+		// данные встречаются только у событий, собранных в коде
+		raw, err := json.Marshal(data)
+		if err != nil || json.Unmarshal(raw, &fields) != nil {
+			return false
+		}
+	}
+	for _, key := range userFields {
+		if id, _ := fields[key].(string); id == userID {
+			return true
+		}
+	}
+	for _, key := range userListFields {
+		ids, _ := fields[key].([]any)
+		for _, id := range ids {
+			if id == userID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Subscription получает события с id > Cursor. Более ранние события уже
+// окончательны и читаются из журнала через Broker.Replay; Pending — события
+// с id > Cursor, разосланные до подписки
+type Subscription struct {
+	Cursor  int64
+	Pending []models.Event
+
+	filter Filter
+	events chan models.Event
+	done   chan struct{}
+}
+
+func (s *Subscription) Events() <-chan models.Event { return s.events }
+
+// Done закрывается, когда брокер останавливается или подписчик не успевает
+// читать события; клиент переподключается с Last-Event-ID
+func (s *Subscription) Done() <-chan struct{} { return s.done }
+
+// Broker читает журнал событий outbox и раздаёт новые события подписчикам.
+// Журнал читает каждая реплика сама по себе: диспетчер outbox отдаёт событие
+// только одной из них, а подписчики есть на всех.
+//
+// id из BIGSERIAL выдаются до коммита, поэтому транзакции коммитятся не по
+// порядку id: событие с меньшим id может появиться позже. Брокер держит
+// cursor — все события с id <= cursor уже окончательны — и пропускает
+// дыру в последовательности только спустя GapTimeout, так как откаченные
+// транзакции оставляют дыры навсегда
+type Broker struct {
+	repo repository.EventLogRepository
+	log  *slog.Logger
+
+	PollInterval time.Duration
+	BatchSize    int
+	GapTimeout   time.Duration
+	Buffer       int
+
+	mu        sync.Mutex
+	ready     bool
+	closed    bool
+	cursor    int64
+	maxSeen   int64
+	published map[int64]models.Event
+	gaps      map[int64]time.Time
+	subs      map[*Subscription]struct{}
+}
+
+func NewBroker(repo repository.EventLogRepository, log *slog.Logger) *Broker {
+	return &Broker{
+		repo:         repo,
+		log:          log.With("component", "stream"),
+		PollInterval: 500 * time.Millisecond,
+		BatchSize:    100,
+		GapTimeout:   30 * time.Second,
+		Buffer:       256,
+		published:    map[int64]models.Event{},
+		gaps:         map[int64]time.Time{},
+		subs:         map[*Subscription]struct{}{},
+	}
+}
+
+func (b *Broker) Run(ctx context.Context) {
+	// до первого чтения журнала подписки отклоняются, не ждём тикера
+	if _, err := b.Poll(ctx); err != nil {
+		b.log.Error("event stream poll failed", "error", err)
+	}
+
+	ticker := time.NewTicker(b.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// пока пачки полные, читаем журнал без ожидания тикера
+			for ctx.Err() == nil {
+				n, err := b.Poll(ctx)
+				if err != nil {
+					b.log.Error("event stream poll failed", "error", err)
+					break
+				}
+				if n < b.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Poll раздаёт подписчикам новые события и возвращает число прочитанных из
+// журнала. Первый вызов только запоминает конец журнала: история отдаётся
+// через Replay
+func (b *Broker) Poll(ctx context.Context) (int, error) {
+	b.mu.Lock()
+	ready, maxSeen := b.ready, b.maxSeen
+	gaps := make([]int64, 0, len(b.gaps))
+	for id := range b.gaps {
+		gaps = append(gaps, id)
+	}
+	b.mu.Unlock()
+
+	if !ready {
+		last, err := b.repo.LastEventID(ctx)
+		if err != nil {
+			return 0, err
+		}
+		b.mu.Lock()
+		b.cursor, b.maxSeen, b.ready = last, last, true
+		b.mu.Unlock()
+		return 0, nil
+	}
+
+	var late []models.Event
+	if len(gaps) > 0 {
+		var err error
+		if late, err = b.repo.GetEvents(ctx, gaps); err != nil {
+			return 0, err
+		}
+	}
+	events, err := b.repo.ListEvents(ctx, maxSeen, b.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, event := range append(late, events...) {
+		if event.ID <= b.cursor {
+			continue
+		}
+		if _, ok := b.published[event.ID]; ok {
+			continue
+		}
+		b.published[event.ID] = event
+		delete(b.gaps, event.ID)
+		b.maxSeen = max(b.maxSeen, event.ID)
+		b.publish(event)
+	}
+	b.advance(time.Now())
+	return len(events), nil
+}
+
+// advance сдвигает cursor по разосланным событиям и просроченным дырам
+func (b *Broker) advance(now time.Time) {
+	for id := b.cursor + 1; id <= b.maxSeen; id++ {
+		if _, ok := b.published[id]; ok {
+			delete(b.published, id)
+			b.cursor = id
+			continue
+		}
+		since, ok := b.gaps[id]
+		if !ok {
+			b.gaps[id] = now
+			since = now
+		}
+		if now.Sub(since) < b.GapTimeout {
+			// запоминаем и все следующие дыры: ListEvents читает только после
+			// maxSeen, а опоздавшие события дочитываются по id через GetEvents
+			for next := id + 1; next <= b.maxSeen; next++ {
+				if _, ok := b.published[next]; !ok {
+					if _, ok := b.gaps[next]; !ok {
+						b.gaps[next] = now
+					}
+				}
+			}
+			return
+		}
+		delete(b.gaps, id)
+		b.cursor = id
+	}
+}
+
+func (b *Broker) publish(event models.Event) {
+	for sub := range b.subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.log.Warn("event stream subscriber fell behind", "event_id", event.ID)
+			metrics.StreamDropped.Inc()
+			b.remove(sub)
+		}
+	}
+}
+
+func (b *Broker) Subscribe(filter Filter) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if !b.ready {
+		return nil, ErrNotReady
+	}
+
+	sub := &Subscription{
+		Cursor:  b.cursor,
+		Pending: []models.Event{},
+		filter:  filter,
+		events:  make(chan models.Event, b.Buffer),
+		done:    make(chan struct{}),
+	}
+	for _, event := range b.published {
+		if filter.Match(event) {
+			sub.Pending = append(sub.Pending, event)
+		}
+	}
+	slices.SortFunc(sub.Pending, func(a, c models.Event) int { return cmp.Compare(a.ID, c.ID) })
+
+	b.subs[sub] = struct{}{}
+	metrics.StreamSubscribers.Inc()
+	return sub, nil
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(sub)
+}
+
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	close(sub.done)
+	metrics.StreamSubscribers.Dec()
+}
+
+// Close завершает все подписки и запрещает новые. Вызывается при остановке
+// HTTP-сервера: иначе открытые потоки не дадут ему завершиться
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		b.remove(sub)
+	}
+}
+
+// CheckResume проверяет, что в журнале остались все события после after:
+// иначе Replay молча пропустил бы удалённые, и клиент не узнал бы о них
+func (b *Broker) CheckResume(ctx context.Context, after int64) error {
+	deleted, err := b.repo.DeletedUpTo(ctx)
+	if err != nil {
+		return err
+	}
+	if after < deleted {
+		return ErrResumeExpired
+	}
+	return nil
+}
+
+// Replay отдаёт из журнала события с after < id <= upTo, подходящие под фильтр
+func (b *Broker) Replay(ctx context.Context, after, upTo int64, filter Filter, fn func(models.Event) error) error {
+	for after < upTo {
+		events, err := b.repo.ListEvents(ctx, after, b.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if event.ID > upTo {
+				return nil
+			}
+			if filter.Match(event) {
+				if err := fn(event); err != nil {
+					return err
+				}
+			}
+			after = event.ID
+		}
+		if len(events) < b.BatchSize {
+			return nil
+		}
+	}
+	return nil
+}
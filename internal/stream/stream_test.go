@@ -0,0 +1,276 @@
+package stream
+
+import (
+	"avito-pr-service/internal/models"
+	"cmp"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeLog — журнал событий, в котором id выдаются заранее, а события
+// появляются по commit: так моделируются транзакции, коммитящиеся не по порядку
+type fakeLog struct {
+	events  []models.Event
+	deleted int64
+}
+
+func (l *fakeLog) commit(events ...models.Event) {
+	l.events = append(l.events, events...)
+	slices.SortFunc(l.events, func(a, b models.Event) int { return cmp.Compare(a.ID, b.ID) })
+}
+
+func (l *fakeLog) ListEvents(_ context.Context, afterID int64, limit int) ([]models.Event, error) {
+	out := []models.Event{}
+	for _, ev := range l.events {
+		if ev.ID > afterID && len(out) < limit {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (l *fakeLog) GetEvents(_ context.Context, ids []int64) ([]models.Event, error) {
+	out := []models.Event{}
+	for _, ev := range l.events {
+		if slices.Contains(ids, ev.ID) {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func (l *fakeLog) LastEventID(context.Context) (int64, error) {
+	if len(l.events) == 0 {
+		return 0, nil
+	}
+	return l.events[len(l.events)-1].ID, nil
+}
+
+func (l *fakeLog) DeletedUpTo(context.Context) (int64, error) {
+	return l.deleted, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func event(id int64, typ, pr, team string, data any) models.Event {
+	return models.Event{ID: id, Type: typ, PRID: pr, TeamName: team, Data: data}
+}
+
+func newStartedBroker(t *testing.T, log *fakeLog) *Broker {
+	t.Helper()
+	b := NewBroker(log, testLogger())
+	_, err := b.Poll(context.Background())
+	require.NoError(t, err)
+	return b
+}
+
+func received(sub *Subscription) []int64 {
+	var ids []int64
+	for {
+		select {
+		case ev := <-sub.Events():
+			ids = append(ids, ev.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	created := event(1, models.EventPRCreated, "pr-1", "backend", map[string]any{
+		"author_id": "u1", "assigned_reviewers": []any{"u2", "u3"},
+	})
+	reassigned := event(2, models.EventReviewerReassigned, "pr-1", "backend", models.ReviewerReassignedData{
+		PRID: "pr-1", OldReviewerID: "u2", NewReviewerID: "u4",
+	})
+	deactivated := event(3, models.EventTeamDeactivated, "", "frontend", map[string]any{"deactivated_users": 2})
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int64
+	}{
+		{name: "empty filter", filter: Filter{}, want: []int64{1, 2, 3}},
+		{name: "author", filter: Filter{UserID: "u1"}, want: []int64{1}},
+		{name: "assigned reviewer", filter: Filter{UserID: "u3"}, want: []int64{1}},
+		{name: "typed data", filter: Filter{UserID: "u4"}, want: []int64{2}},
+		{name: "old reviewer", filter: Filter{UserID: "u2"}, want: []int64{1, 2}},
+		{name: "team", filter: Filter{TeamName: "frontend"}, want: []int64{3}},
+		{name: "pull request", filter: Filter{PRID: "pr-1"}, want: []int64{1, 2}},
+		{name: "types", filter: Filter{Types: []string{models.EventReviewerReassigned, models.EventTeamDeactivated}}, want: []int64{2, 3}},
+		{name: "combined", filter: Filter{TeamName: "backend", UserID: "u2", Types: []string{models.EventPRCreated}}, want: []int64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, ev := range []models.Event{created, reassigned, deactivated} {
+				if tt.filter.Match(ev) {
+					got = append(got, ev.ID)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBroker_SubscribeBeforeFirstPoll(t *testing.T) {
+	b := NewBroker(&fakeLog{}, testLogger())
+
+	_, err := b.Subscribe(Filter{})
+	require.ErrorIs(t, err, ErrNotReady)
+}
+
+func TestBroker_PublishesOnlyNewMatchingEvents(t *testing.T) {
+	log := &fakeLog{}
+	log.commit(event(1, models.EventPRCreated, "pr-1", "backend", nil))
+	b := newStartedBroker(t, log)
+
+	sub, err := b.Subscribe(Filter{TeamName: "backend"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), sub.Cursor)
+	assert.Empty(t, sub.Pending)
+
+	log.commit(
+		event(2, models.EventPRMerged, "pr-1", "backend", nil),
+		event(3, models.EventPRCreated, "pr-2", "frontend", nil),
+	)
+	n, err := b.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.Equal(t, []int64{2}, received(sub))
+}
+
+func TestBroker_WaitsForOutOfOrderCommits(t *testing.T) {
+	log := &fakeLog{}
+	b := newStartedBroker(t, log)
+	sub, err := b.Subscribe(Filter{})
+	require.NoError(t, err)
+
+	// транзакция с id 1 ещё не закоммичена, id 2 уже виден
+	log.commit(event(2, models.EventPRCreated, "pr-2", "backend", nil))
+	_, err = b.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, received(sub))
+
+	late, err := b.Subscribe(Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), late.Cursor, "cursor must not pass an uncommitted id")
+	require.Len(t, late.Pending, 1)
+	assert.Equal(t, int64(2), late.Pending[0].ID)
+
+	log.commit(event(1, models.EventPRCreated, "pr-1", "backend", nil))
+	_, err = b.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, received(sub))
+
+	after, err := b.Subscribe(Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), after.Cursor)
+	assert.Empty(t, after.Pending)
+}
+
+func TestBroker_SkipsGapAfterTimeout(t *testing.T) {
+	log := &fakeLog{}
+	b := newStartedBroker(t, log)
+	b.GapTimeout = 0
+
+	// id 1 и 2 откатились и не появятся никогда
+	log.commit(event(3, models.EventPRCreated, "pr-3", "backend", nil))
+	_, err := b.Poll(context.Background())
+	require.NoError(t, err)
+
+	sub, err := b.Subscribe(Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), sub.Cursor)
+	assert.Empty(t, sub.Pending)
+}
+
+func TestBroker_ReplayBetweenLastEventIDAndCursor(t *testing.T) {
+	log := &fakeLog{}
+	for id := int64(1); id <= 5; id++ {
+		team := "backend"
+		if id%2 == 0 {
+			team = "frontend"
+		}
+		log.commit(event(id, models.EventPRCreated, "pr", team, nil))
+	}
+	b := newStartedBroker(t, log)
+	b.BatchSize = 2
+
+	var got []int64
+	err := b.Replay(context.Background(), 1, 4, Filter{}, func(ev models.Event) error {
+		got = append(got, ev.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3, 4}, got)
+
+	got = nil
+	err = b.Replay(context.Background(), 0, 5, Filter{TeamName: "backend"}, func(ev models.Event) error {
+		got = append(got, ev.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3, 5}, got)
+}
+
+func TestBroker_CheckResume(t *testing.T) {
+	log := &fakeLog{deleted: 3}
+	log.commit(event(4, models.EventPRCreated, "pr", "backend", nil))
+	b := newStartedBroker(t, log)
+
+	// события 1..3 удалены: продолжение с 2 пропустило бы 3
+	require.ErrorIs(t, b.CheckResume(context.Background(), 2), ErrResumeExpired)
+	require.ErrorIs(t, b.CheckResume(context.Background(), 0), ErrResumeExpired)
+	require.NoError(t, b.CheckResume(context.Background(), 3))
+	require.NoError(t, b.CheckResume(context.Background(), 4))
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	log := &fakeLog{}
+	b := newStartedBroker(t, log)
+	b.Buffer = 1
+
+	slow, err := b.Subscribe(Filter{})
+	require.NoError(t, err)
+
+	log.commit(
+		event(1, models.EventPRCreated, "pr-1", "backend", nil),
+		event(2, models.EventPRMerged, "pr-1", "backend", nil),
+	)
+	_, err = b.Poll(context.Background())
+	require.NoError(t, err)
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("slow subscriber must be dropped")
+	}
+	b.Unsubscribe(slow)
+}
+
+func TestBroker_CloseEndsSubscriptions(t *testing.T) {
+	b := newStartedBroker(t, &fakeLog{})
+	sub, err := b.Subscribe(Filter{})
+	require.NoError(t, err)
+
+	b.Close()
+
+	select {
+	case <-sub.Done():
+	case <-time.After(time.Second):
+		t.Fatal("subscription must end on close")
+	}
+	_, err = b.Subscribe(Filter{})
+	require.ErrorIs(t, err, ErrClosed)
+	b.Unsubscribe(sub)
+}
@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestExporter(t *testing.T) *tracetest.InMemoryExporter {
+	exp := tracetest.NewInMemoryExporter()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return exp
+}
+
+func TestMiddleware_ContinuesIncomingTrace(t *testing.T) {
+	exp := newTestExporter(t)
+
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/team/get", func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "TeamUsecase.GetTeam")
+		span.End()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 2)
+
+	child, server := spans[0], spans[1]
+	require.Equal(t, "GET /team/get", server.Name)
+	require.Equal(t, trace.SpanKindServer, server.SpanKind)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+
+	require.Equal(t, "TeamUsecase.GetTeam", child.Name)
+	require.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+}
+
+func TestQueryTracer(t *testing.T) {
+	exp := newTestExporter(t)
+	tracer := NewQueryTracer()
+
+	ctx, parent := Start(context.Background(), "PRUsecase.MergePR")
+	qctx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+		SQL: "\n        UPDATE pull_requests SET status = 'MERGED' WHERE id = $1", Args: []any{"pr-1"},
+	})
+	tracer.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 1")})
+
+	qctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+	parent.End()
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 3)
+
+	require.Equal(t, "db.query UPDATE", spans[0].Name)
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+
+	require.Equal(t, "db.query SELECT", spans[1].Name)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+}
+
+func TestLogHandler_AddsTraceIDs(t *testing.T) {
+	newTestExporter(t)
+
+	var buf bytes.Buffer
+	log := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil))).With("service", "test")
+
+	ctx, span := Start(context.Background(), "op")
+	log.InfoContext(ctx, "inside span")
+	span.End()
+	require.Contains(t, buf.String(), "trace_id="+span.SpanContext().TraceID().String())
+	require.Contains(t, buf.String(), "span_id="+span.SpanContext().SpanID().String())
+
+	buf.Reset()
+	log.Info("outside span")
+	require.NotContains(t, buf.String(), "trace_id")
+}
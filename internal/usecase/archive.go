@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/archive"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+type ArchiveUsecase interface {
+	Export(ctx context.Context, w io.Writer) (models.ArchiveSummary, error)
+	Restore(ctx context.Context, r io.Reader) (models.ArchiveSummary, error)
+}
+
+type archiveUsecase struct {
+	repo repository.ArchiveRepository
+	log  *slog.Logger
+	now  func() time.Time
+}
+
+func NewArchiveUsecase(repo repository.ArchiveRepository, log *slog.Logger) ArchiveUsecase {
+	return &archiveUsecase{
+		repo: repo,
+		log:  log.With("layer", "usecase", "entity", "archive"),
+		now:  time.Now,
+	}
+}
+
+func (u *archiveUsecase) Export(ctx context.Context, w io.Writer) (models.ArchiveSummary, error) {
+	ctx, span := tracing.Start(ctx, "ArchiveUsecase.Export")
+	defer span.End()
+
+	a, err := u.repo.Dump(ctx)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to dump data", "error", err)
+		return models.ArchiveSummary{}, err
+	}
+	a.Header = models.ArchiveHeader{
+		Format:    models.ArchiveFormat,
+		Version:   models.ArchiveVersion,
+		CreatedAt: u.now().UTC(),
+	}
+
+	if err := archive.Write(w, a); err != nil {
+		return models.ArchiveSummary{}, err
+	}
+
+	summary := a.Summary()
+	u.log.InfoContext(ctx, "archive exported", "summary", summary)
+	return summary, nil
+}
+
+func (u *archiveUsecase) Restore(ctx context.Context, r io.Reader) (models.ArchiveSummary, error) {
+	ctx, span := tracing.Start(ctx, "ArchiveUsecase.Restore")
+	defer span.End()
+
+	a, err := archive.Read(r)
+	if err == nil {
+		err = archive.Validate(a)
+	}
+	if err != nil {
+		u.log.WarnContext(ctx, "archive rejected", "error", err)
+		return models.ArchiveSummary{}, models.AppError{Code: models.ErrorInvalidArchive, Message: err.Error()}
+	}
+
+	if err := u.repo.Restore(ctx, a); err != nil {
+		u.log.ErrorContext(ctx, "failed to restore archive", "error", err)
+		return models.ArchiveSummary{}, err
+	}
+
+	summary := a.Summary()
+	u.log.InfoContext(ctx, "archive restored", "version", a.Header.Version, "created_at", a.Header.CreatedAt, "summary", summary)
+	return summary, nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockArchiveRepository struct{ mock.Mock }
+
+func (m *mockArchiveRepository) Dump(ctx context.Context) (models.Archive, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.Archive), args.Error(1)
+}
+
+func (m *mockArchiveRepository) Restore(ctx context.Context, archive models.Archive) error {
+	return m.Called(ctx, archive).Error(0)
+}
+
+func TestArchiveUsecase_ExportRestore(t *testing.T) {
+	data := models.Archive{
+		Teams: []models.ArchiveTeam{{Name: "backend"}},
+		Users: []models.User{{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}},
+	}
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	repo := new(mockArchiveRepository)
+	repo.On("Dump", mock.Anything).Return(data, nil)
+	uc := NewArchiveUsecase(repo, testLogger()).(*archiveUsecase)
+	uc.now = func() time.Time { return now }
+
+	var buf bytes.Buffer
+	summary, err := uc.Export(context.Background(), &buf)
+	require.NoError(t, err)
+	require.Equal(t, models.ArchiveSummary{Teams: 1, Users: 1}, summary)
+
+	want := data
+	want.Header = models.ArchiveHeader{Format: models.ArchiveFormat, Version: models.ArchiveVersion, CreatedAt: now}
+	repo.On("Restore", mock.Anything, want).Return(nil)
+
+	summary, err = uc.Restore(context.Background(), &buf)
+	require.NoError(t, err)
+	require.Equal(t, models.ArchiveSummary{Teams: 1, Users: 1}, summary)
+	repo.AssertExpectations(t)
+}
+
+func TestArchiveUsecase_Restore_InvalidArchive(t *testing.T) {
+	repo := new(mockArchiveRepository)
+	uc := NewArchiveUsecase(repo, testLogger())
+
+	archive := `{"type":"header","data":{"format":"avito-pr-service","version":1}}
+{"type":"user","data":{"user_id":"u1","username":"Alice","team_name":"ghosts"}}`
+
+	_, err := uc.Restore(context.Background(), strings.NewReader(archive))
+
+	var appErr models.AppError
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, models.ErrorInvalidArchive, appErr.Code)
+	require.Contains(t, appErr.Message, `user "u1" references unknown team "ghosts"`)
+	repo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+}
+
+func TestArchiveUsecase_Restore_NotEmpty(t *testing.T) {
+	repo := new(mockArchiveRepository)
+	repo.On("Restore", mock.Anything, mock.Anything).Return(models.ErrDatabaseNotEmpty)
+	uc := NewArchiveUsecase(repo, testLogger())
+
+	_, err := uc.Restore(context.Background(), strings.NewReader(`{"type":"header","data":{"format":"avito-pr-service","version":1}}`))
+	require.ErrorIs(t, err, models.ErrDatabaseNotEmpty)
+}
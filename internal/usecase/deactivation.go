@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// столько раз задача деактивации начинается заново; каждая попытка
+// продолжает с необработанных PR
+const deactivationJobAttempts = 5
+
+// StartDeactivation ставит деактивацию в очередь; повторный запрос, пока
+// предыдущая не закончилась, возвращает её же
+func (u *teamUsecase) StartDeactivation(ctx context.Context, req models.DeactivateTeamRequest) (models.TeamDeactivation, error) {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.StartDeactivation")
+	defer span.End()
+
+	if _, err := u.repo.GetTeam(ctx, req.TeamName); err != nil {
+		return models.TeamDeactivation{}, err
+	}
+
+	d, created, err := u.deactivations.Create(ctx, req.TeamName, deactivationJobAttempts)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to start deactivation", "team", req.TeamName, "error", err)
+		return models.TeamDeactivation{}, err
+	}
+	if created {
+		u.log.InfoContext(ctx, "team deactivation queued", "team", req.TeamName, "id", d.ID)
+	}
+	return d, nil
+}
+
+func (u *teamUsecase) GetDeactivation(ctx context.Context, id int64) (models.TeamDeactivation, error) {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.GetDeactivation")
+	defer span.End()
+
+	return u.deactivations.Get(ctx, id)
+}
+
+func (u *teamUsecase) RunDeactivation(ctx context.Context, job models.Job) error {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.RunDeactivation")
+	defer span.End()
+
+	var payload models.DeactivationJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode job payload: %w", err)
+	}
+
+	err := u.runDeactivation(ctx, payload.DeactivationID)
+	// последняя попытка не удалась: операция не должна навсегда остаться
+	// RUNNING. Остановка сервера попыткой не считается
+	if err != nil && job.Attempts >= job.MaxAttempts && !errors.Is(ctx.Err(), context.Canceled) {
+		if failErr := u.deactivations.Fail(context.WithoutCancel(ctx), payload.DeactivationID, err.Error()); failErr != nil {
+			u.log.ErrorContext(ctx, "failed to mark deactivation failed", "id", payload.DeactivationID, "error", failErr)
+		}
+	}
+	return err
+}
+
+func (u *teamUsecase) runDeactivation(ctx context.Context, id int64) error {
+	d, err := u.deactivations.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	switch d.Status {
+	case models.DeactivationDone, models.DeactivationFailed:
+		return nil
+	case models.DeactivationPending:
+		prs, err := u.prRepo.GetOpenPRsWithTeamReviewers(ctx, d.TeamName)
+		if err != nil {
+			return err
+		}
+		prIDs := make([]string, len(prs))
+		for i, pr := range prs {
+			prIDs[i] = pr.ID
+		}
+		if err := u.deactivations.Start(ctx, id, prIDs); err != nil {
+			return err
+		}
+	}
+
+	prIDs, err := u.deactivations.PendingPRs(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, prID := range prIDs {
+		// PR перечитывается: после падения часть его ревьюверов уже заменена,
+		// а сам он мог быть смёржен или удалён
+		var reassigned, stuck int
+		pr, err := u.prRepo.GetPR(ctx, prID)
+		switch {
+		case errors.Is(err, models.ErrPRNotFound):
+		case err != nil:
+			return err
+		case pr.Status == models.StatusOpen:
+			if reassigned, stuck, err = u.reassignTeamReviewers(ctx, pr, d.TeamName); err != nil {
+				return err
+			}
+		}
+		if err := u.deactivations.MarkPRProcessed(ctx, id, prID, reassigned, stuck); err != nil {
+			return err
+		}
+	}
+
+	d, err = u.deactivations.Finish(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.log.InfoContext(ctx, "team deactivated", "team", d.TeamName, "id", id,
+		"prs", d.Progress.TotalPRs, "reassigned", d.Progress.Reassigned, "stuck", d.Progress.Stuck)
+	return nil
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type mockDeactivationRepository struct{ mock.Mock }
+
+func (m *mockDeactivationRepository) Create(ctx context.Context, teamName string, maxAttempts int) (models.TeamDeactivation, bool, error) {
+	args := m.Called(ctx, teamName, maxAttempts)
+	return args.Get(0).(models.TeamDeactivation), args.Bool(1), args.Error(2)
+}
+
+func (m *mockDeactivationRepository) Get(ctx context.Context, id int64) (models.TeamDeactivation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.TeamDeactivation), args.Error(1)
+}
+
+func (m *mockDeactivationRepository) InProgress(ctx context.Context, teamName string) (bool, error) {
+	args := m.Called(ctx, teamName)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockDeactivationRepository) Start(ctx context.Context, id int64, prIDs []string) error {
+	return m.Called(ctx, id, prIDs).Error(0)
+}
+
+func (m *mockDeactivationRepository) PendingPRs(ctx context.Context, id int64) ([]string, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockDeactivationRepository) MarkPRProcessed(ctx context.Context, id int64, prID string, reassigned, stuck int) error {
+	return m.Called(ctx, id, prID, reassigned, stuck).Error(0)
+}
+
+func (m *mockDeactivationRepository) Finish(ctx context.Context, id int64) (models.TeamDeactivation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.TeamDeactivation), args.Error(1)
+}
+
+func (m *mockDeactivationRepository) Fail(ctx context.Context, id int64, reason string) error {
+	return m.Called(ctx, id, reason).Error(0)
+}
+
+func deactivationJob(t *testing.T, id int64, attempts int) models.Job {
+	payload, err := json.Marshal(models.DeactivationJob{DeactivationID: id})
+	require.NoError(t, err)
+	return models.Job{Kind: models.JobTeamDeactivation, Payload: payload, Attempts: attempts, MaxAttempts: deactivationJobAttempts}
+}
+
+func TestTeamUsecase_StartDeactivation(t *testing.T) {
+	teamRepo := new(mockTeamRepository)
+	deactivations := new(mockDeactivationRepository)
+	uc := NewTeamUsecase(teamRepo, new(mockUserRepository), new(mockPRRepository), deactivations, nil, testLogger())
+
+	teamRepo.On("GetTeam", mock.Anything, "backend").Return(models.Team{Name: "backend"}, nil)
+	teamRepo.On("GetTeam", mock.Anything, "ghost").Return(models.Team{}, models.ErrTeamNotFound)
+	deactivations.On("Create", mock.Anything, "backend", deactivationJobAttempts).
+		Return(models.TeamDeactivation{ID: 3, TeamName: "backend", Status: models.DeactivationPending}, true, nil)
+
+	d, err := uc.StartDeactivation(context.Background(), models.DeactivateTeamRequest{TeamName: "backend"})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), d.ID)
+
+	_, err = uc.StartDeactivation(context.Background(), models.DeactivateTeamRequest{TeamName: "ghost"})
+	require.ErrorIs(t, err, models.ErrTeamNotFound)
+	deactivations.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestTeamUsecase_RunDeactivation_FromStart(t *testing.T) {
+	userRepo := new(mockUserRepository)
+	prRepo := new(mockPRRepository)
+	prUC := new(mockPRUsecase)
+	deactivations := new(mockDeactivationRepository)
+	uc := NewTeamUsecase(new(mockTeamRepository), userRepo, prRepo, deactivations, prUC, testLogger())
+
+	open := models.PullRequest{ID: "pr-1", AuthorID: "u9", Status: models.StatusOpen, AssignedReviewers: []string{"u1", "u5", "u2"}}
+	merged := models.PullRequest{ID: "pr-2", Status: models.StatusMerged, AssignedReviewers: []string{"u1"}}
+
+	deactivations.On("Get", mock.Anything, int64(3)).
+		Return(models.TeamDeactivation{ID: 3, TeamName: "backend", Status: models.DeactivationPending}, nil)
+	prRepo.On("GetOpenPRsWithTeamReviewers", mock.Anything, "backend").Return([]models.PullRequest{open, merged}, nil)
+	deactivations.On("Start", mock.Anything, int64(3), []string{"pr-1", "pr-2"}).Return(nil)
+	deactivations.On("PendingPRs", mock.Anything, int64(3)).Return([]string{"pr-1", "pr-2"}, nil)
+
+	prRepo.On("GetPR", mock.Anything, "pr-1").Return(open, nil)
+	userRepo.On("GetUser", mock.Anything, "u1").Return(models.User{UserID: "u1", TeamName: "backend"}, nil)
+	userRepo.On("GetUser", mock.Anything, "u5").Return(models.User{UserID: "u5", TeamName: "frontend"}, nil)
+	userRepo.On("GetUser", mock.Anything, "u2").Return(models.User{UserID: "u2", TeamName: "backend"}, nil)
+	prUC.On("ReassignReviewer", mock.Anything, models.ReassignRequest{PRID: "pr-1", OldReviewerID: "u1", Reason: models.ReassignReasonTeamDeactivation}).
+		Return(models.PullRequest{}, "u3", nil)
+	prUC.On("ReassignReviewer", mock.Anything, models.ReassignRequest{PRID: "pr-1", OldReviewerID: "u2", Reason: models.ReassignReasonTeamDeactivation}).
+		Return(models.PullRequest{}, "", models.ErrNoCandidate)
+	deactivations.On("MarkPRProcessed", mock.Anything, int64(3), "pr-1", 1, 1).Return(nil)
+
+	// смёржен после старта: отмечается без переназначений
+	prRepo.On("GetPR", mock.Anything, "pr-2").Return(merged, nil)
+	deactivations.On("MarkPRProcessed", mock.Anything, int64(3), "pr-2", 0, 0).Return(nil)
+
+	deactivations.On("Finish", mock.Anything, int64(3)).Return(models.TeamDeactivation{ID: 3, TeamName: "backend", Status: models.DeactivationDone}, nil)
+
+	require.NoError(t, uc.RunDeactivation(context.Background(), deactivationJob(t, 3, 1)))
+	deactivations.AssertExpectations(t)
+	prUC.AssertExpectations(t)
+}
+
+func TestTeamUsecase_RunDeactivation_Resume(t *testing.T) {
+	userRepo := new(mockUserRepository)
+	prRepo := new(mockPRRepository)
+	prUC := new(mockPRUsecase)
+	deactivations := new(mockDeactivationRepository)
+	uc := NewTeamUsecase(new(mockTeamRepository), userRepo, prRepo, deactivations, prUC, testLogger())
+
+	// упали после pr-1: снимок не переснимается, pr-1 не трогается
+	deactivations.On("Get", mock.Anything, int64(3)).
+		Return(models.TeamDeactivation{ID: 3, TeamName: "backend", Status: models.DeactivationRunning}, nil)
+	deactivations.On("PendingPRs", mock.Anything, int64(3)).Return([]string{"pr-2"}, nil)
+	prRepo.On("GetPR", mock.Anything, "pr-2").Return(models.PullRequest{ID: "pr-2", Status: models.StatusOpen, AssignedReviewers: []string{"u1"}}, nil)
+	userRepo.On("GetUser", mock.Anything, "u1").Return(models.User{UserID: "u1", TeamName: "backend"}, nil)
+	prUC.On("ReassignReviewer", mock.Anything, mock.Anything).Return(models.PullRequest{}, "u3", nil)
+	deactivations.On("MarkPRProcessed", mock.Anything, int64(3), "pr-2", 1, 0).Return(nil)
+	deactivations.On("Finish", mock.Anything, int64(3)).Return(models.TeamDeactivation{ID: 3, Status: models.DeactivationDone}, nil)
+
+	require.NoError(t, uc.RunDeactivation(context.Background(), deactivationJob(t, 3, 2)))
+	prRepo.AssertNotCalled(t, "GetOpenPRsWithTeamReviewers", mock.Anything, mock.Anything)
+	deactivations.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything)
+	deactivations.AssertExpectations(t)
+}
+
+func TestTeamUsecase_RunDeactivation_AlreadyDone(t *testing.T) {
+	deactivations := new(mockDeactivationRepository)
+	uc := NewTeamUsecase(new(mockTeamRepository), new(mockUserRepository), new(mockPRRepository), deactivations, nil, testLogger())
+
+	deactivations.On("Get", mock.Anything, int64(3)).Return(models.TeamDeactivation{ID: 3, Status: models.DeactivationDone}, nil)
+
+	require.NoError(t, uc.RunDeactivation(context.Background(), deactivationJob(t, 3, 1)))
+	deactivations.AssertNotCalled(t, "Finish", mock.Anything, mock.Anything)
+}
+
+func TestTeamUsecase_RunDeactivation_LastAttemptFails(t *testing.T) {
+	prRepo := new(mockPRRepository)
+	deactivations := new(mockDeactivationRepository)
+	uc := NewTeamUsecase(new(mockTeamRepository), new(mockUserRepository), prRepo, deactivations, nil, testLogger())
+
+	dbErr := errors.New("db is down")
+	deactivations.On("Get", mock.Anything, int64(3)).
+		Return(models.TeamDeactivation{ID: 3, TeamName: "backend", Status: models.DeactivationPending}, nil)
+	prRepo.On("GetOpenPRsWithTeamReviewers", mock.Anything, "backend").Return([]models.PullRequest(nil), dbErr)
+
+	// не последняя попытка: задача повторится, операция остаётся как есть
+	require.ErrorIs(t, uc.RunDeactivation(context.Background(), deactivationJob(t, 3, 1)), dbErr)
+	deactivations.AssertNotCalled(t, "Fail", mock.Anything, mock.Anything, mock.Anything)
+
+	deactivations.On("Fail", mock.Anything, int64(3), "db is down").Return(nil)
+	require.ErrorIs(t, uc.RunDeactivation(context.Background(), deactivationJob(t, 3, deactivationJobAttempts)), dbErr)
+	deactivations.AssertExpectations(t)
+}
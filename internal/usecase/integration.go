@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"errors"
+	"log/slog"
+)
+
+type IntegrationUsecase interface {
+	SetIdentity(ctx context.Context, identity models.VCSIdentity) error
+	HandlePullRequestEvent(ctx context.Context, event models.VCSPullRequestEvent) (models.VCSEventResult, error)
+}
+
+type integrationUsecase struct {
+	identities repository.VCSIdentityRepository
+	prUC       PRUsecase
+	log        *slog.Logger
+}
+
+func NewIntegrationUsecase(identities repository.VCSIdentityRepository, prUC PRUsecase, log *slog.Logger) IntegrationUsecase {
+	return &integrationUsecase{
+		identities: identities,
+		prUC:       prUC,
+		log:        log.With("layer", "usecase", "entity", "integration"),
+	}
+}
+
+func (u *integrationUsecase) SetIdentity(ctx context.Context, identity models.VCSIdentity) error {
+	ctx, span := tracing.Start(ctx, "IntegrationUsecase.SetIdentity")
+	defer span.End()
+
+	if err := u.identities.SetIdentity(ctx, identity); err != nil {
+		return err
+	}
+	u.log.InfoContext(ctx, "vcs identity mapped", "provider", identity.Provider, "login", identity.Login, "user_id", identity.UserID)
+	return nil
+}
+
+func (u *integrationUsecase) HandlePullRequestEvent(ctx context.Context, event models.VCSPullRequestEvent) (models.VCSEventResult, error) {
+	ctx, span := tracing.Start(ctx, "IntegrationUsecase.HandlePullRequestEvent")
+	defer span.End()
+
+	prID := event.PRID()
+	log := u.log.With("provider", event.Provider, "pr", prID, "action", event.Action)
+
+	switch event.Action {
+	case models.VCSActionOpen:
+		return u.open(ctx, event, prID, log)
+	case models.VCSActionMerge:
+		pr, err := u.prUC.MergePR(ctx, prID)
+		if err != nil {
+			log.WarnContext(ctx, "failed to merge PR from VCS event", "error", err)
+			return models.VCSEventResult{}, err
+		}
+		log.InfoContext(ctx, "PR merged from VCS event")
+		return models.VCSEventResult{Result: models.VCSResultMerged, PR: &pr}, nil
+	case models.VCSActionReview:
+		return u.review(ctx, event, prID, log)
+	default:
+		// закрытие без merge и прочие действия не меняют состояние PR в сервисе
+		return models.VCSEventResult{Result: models.VCSResultIgnored}, nil
+	}
+}
+
+func (u *integrationUsecase) open(ctx context.Context, event models.VCSPullRequestEvent, prID string, log *slog.Logger) (models.VCSEventResult, error) {
+	if event.AuthorLogin == "" {
+		// автор неизвестен: заводить PR на того, кто нажал кнопку, нельзя
+		existing, err := u.prUC.GetPR(ctx, prID)
+		if errors.Is(err, models.ErrPRNotFound) {
+			log.InfoContext(ctx, "PR without known author ignored")
+			return models.VCSEventResult{Result: models.VCSResultIgnored}, nil
+		}
+		if err != nil {
+			return models.VCSEventResult{}, err
+		}
+		return models.VCSEventResult{Result: models.VCSResultExists, PR: &existing}, nil
+	}
+
+	authorID, err := u.identities.ResolveUser(ctx, event.Provider, event.AuthorLogin)
+	if err != nil {
+		if errors.Is(err, models.ErrIdentityNotFound) {
+			log.WarnContext(ctx, "VCS login is not mapped to a user", "login", event.AuthorLogin)
+		}
+		return models.VCSEventResult{}, err
+	}
+
+	pr, err := u.prUC.CreatePR(ctx, models.CreatePRRequest{
+		ID:       prID,
+		Name:     event.Title,
+		AuthorID: authorID,
+	})
+	if errors.Is(err, models.ErrPRExists) {
+		// повторная доставка, ready_for_review или reopened: PR уже заведён
+		existing, getErr := u.prUC.GetPR(ctx, prID)
+		if getErr != nil {
+			return models.VCSEventResult{}, getErr
+		}
+		return models.VCSEventResult{Result: models.VCSResultExists, PR: &existing}, nil
+	}
+	if err != nil {
+		log.WarnContext(ctx, "failed to create PR from VCS event", "error", err)
+		return models.VCSEventResult{}, err
+	}
+
+	log.InfoContext(ctx, "PR created from VCS event", "author", authorID, "reviewers", pr.AssignedReviewers)
+	return models.VCSEventResult{Result: models.VCSResultCreated, PR: &pr}, nil
+}
+
+// review запоминает вердикт; отзывы от тех, кто не назначен ревьювером
+// или не сопоставлен с пользователем, и по неизвестным PR не меняют состояние
+func (u *integrationUsecase) review(ctx context.Context, event models.VCSPullRequestEvent, prID string, log *slog.Logger) (models.VCSEventResult, error) {
+	reviewerID, err := u.identities.ResolveUser(ctx, event.Provider, event.ReviewerLogin)
+	if errors.Is(err, models.ErrIdentityNotFound) {
+		log.InfoContext(ctx, "review from unmapped VCS login ignored", "login", event.ReviewerLogin)
+		return models.VCSEventResult{Result: models.VCSResultIgnored}, nil
+	}
+	if err != nil {
+		return models.VCSEventResult{}, err
+	}
+
+	pr, err := u.prUC.RecordVerdict(ctx, prID, reviewerID, event.Verdict)
+	if errors.Is(err, models.ErrPRNotFound) || errors.Is(err, models.ErrNotAssigned) || errors.Is(err, models.ErrPRMerged) {
+		log.InfoContext(ctx, "review ignored", "reviewer", reviewerID, "reason", err)
+		return models.VCSEventResult{Result: models.VCSResultIgnored}, nil
+	}
+	if err != nil {
+		log.WarnContext(ctx, "failed to record verdict from VCS event", "error", err)
+		return models.VCSEventResult{}, err
+	}
+
+	log.InfoContext(ctx, "verdict recorded from VCS event", "reviewer", reviewerID, "verdict", event.Verdict)
+	return models.VCSEventResult{Result: models.VCSResultReviewed, PR: &pr}, nil
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type mockVCSIdentityRepository struct{ mock.Mock }
+
+func (m *mockVCSIdentityRepository) SetIdentity(ctx context.Context, identity models.VCSIdentity) error {
+	return m.Called(ctx, identity).Error(0)
+}
+
+func (m *mockVCSIdentityRepository) ResolveUser(ctx context.Context, provider, login string) (string, error) {
+	args := m.Called(ctx, provider, login)
+	return args.String(0), args.Error(1)
+}
+
+type mockPRUsecase struct{ mock.Mock }
+
+func (m *mockPRUsecase) CreatePR(ctx context.Context, req models.CreatePRRequest) (models.PullRequest, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.PullRequest), args.Error(1)
+}
+
+func (m *mockPRUsecase) GetPR(ctx context.Context, prID string) (models.PullRequest, error) {
+	args := m.Called(ctx, prID)
+	return args.Get(0).(models.PullRequest), args.Error(1)
+}
+
+func (m *mockPRUsecase) MergePR(ctx context.Context, prID string) (models.PullRequest, error) {
+	args := m.Called(ctx, prID)
+	return args.Get(0).(models.PullRequest), args.Error(1)
+}
+
+func (m *mockPRUsecase) RecordVerdict(ctx context.Context, prID, reviewerID, verdict string) (models.PullRequest, error) {
+	args := m.Called(ctx, prID, reviewerID, verdict)
+	return args.Get(0).(models.PullRequest), args.Error(1)
+}
+
+func (m *mockPRUsecase) ReassignReviewer(ctx context.Context, req models.ReassignRequest) (models.PullRequest, string, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(models.PullRequest), args.String(1), args.Error(2)
+}
+
+func (m *mockPRUsecase) GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequest, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.PullRequest), args.Error(1)
+}
+
+func (m *mockPRUsecase) GetUserStats(ctx context.Context) ([]models.UserStats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.UserStats), args.Error(1)
+}
+
+func (m *mockPRUsecase) EachPRByReviewer(ctx context.Context, userID string, fn func(models.PullRequest) error) error {
+	args := m.Called(ctx, userID)
+	for _, pr := range args.Get(0).([]models.PullRequest) {
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockPRUsecase) EachUserStats(ctx context.Context, fn func(models.UserStats) error) error {
+	args := m.Called(ctx)
+	for _, s := range args.Get(0).([]models.UserStats) {
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func openEvent() models.VCSPullRequestEvent {
+	return models.VCSPullRequestEvent{
+		Provider:    models.VCSProviderGitHub,
+		Action:      models.VCSActionOpen,
+		Repository:  "acme/pr-service",
+		Number:      42,
+		Title:       "Add search",
+		AuthorLogin: "octo-alice",
+	}
+}
+
+func TestIntegrationUsecase_Open_CreatesPR(t *testing.T) {
+	identities := new(mockVCSIdentityRepository)
+	prUC := new(mockPRUsecase)
+	uc := NewIntegrationUsecase(identities, prUC, testLogger())
+
+	identities.On("ResolveUser", mock.Anything, "github", "octo-alice").Return("u1", nil)
+	prUC.On("CreatePR", mock.Anything, models.CreatePRRequest{
+		ID: "github:acme/pr-service#42", Name: "Add search", AuthorID: "u1",
+	}).Return(models.PullRequest{ID: "github:acme/pr-service#42", AssignedReviewers: []string{"u2"}}, nil)
+
+	res, err := uc.HandlePullRequestEvent(context.Background(), openEvent())
+
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultCreated, res.Result)
+	require.Equal(t, []string{"u2"}, res.PR.AssignedReviewers)
+	prUC.AssertExpectations(t)
+}
+
+func TestIntegrationUsecase_Open_ExistingPR(t *testing.T) {
+	identities := new(mockVCSIdentityRepository)
+	prUC := new(mockPRUsecase)
+	uc := NewIntegrationUsecase(identities, prUC, testLogger())
+
+	existing := models.PullRequest{ID: "github:acme/pr-service#42", Status: models.StatusOpen}
+	identities.On("ResolveUser", mock.Anything, "github", "octo-alice").Return("u1", nil)
+	prUC.On("CreatePR", mock.Anything, mock.Anything).Return(models.PullRequest{}, models.ErrPRExists)
+	prUC.On("GetPR", mock.Anything, "github:acme/pr-service#42").Return(existing, nil)
+
+	res, err := uc.HandlePullRequestEvent(context.Background(), openEvent())
+
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultExists, res.Result)
+	require.Equal(t, existing.ID, res.PR.ID)
+}
+
+func TestIntegrationUsecase_Open_UnknownAuthor(t *testing.T) {
+	identities := new(mockVCSIdentityRepository)
+	prUC := new(mockPRUsecase)
+	uc := NewIntegrationUsecase(identities, prUC, testLogger())
+
+	event := openEvent()
+	event.AuthorLogin = ""
+	existing := models.PullRequest{ID: "github:acme/pr-service#42", Status: models.StatusOpen}
+	prUC.On("GetPR", mock.Anything, "github:acme/pr-service#42").Return(models.PullRequest{}, models.ErrPRNotFound).Once()
+	prUC.On("GetPR", mock.Anything, "github:acme/pr-service#42").Return(existing, nil).Once()
+
+	res, err := uc.HandlePullRequestEvent(context.Background(), event)
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultIgnored, res.Result)
+
+	res, err = uc.HandlePullRequestEvent(context.Background(), event)
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultExists, res.Result)
+
+	identities.AssertNotCalled(t, "ResolveUser", mock.Anything, mock.Anything, mock.Anything)
+	prUC.AssertNotCalled(t, "CreatePR", mock.Anything, mock.Anything)
+}
+
+func TestIntegrationUsecase_Open_UnmappedLogin(t *testing.T) {
+	identities := new(mockVCSIdentityRepository)
+	prUC := new(mockPRUsecase)
+	uc := NewIntegrationUsecase(identities, prUC, testLogger())
+
+	identities.On("ResolveUser", mock.Anything, "github", "octo-alice").Return("", models.ErrIdentityNotFound)
+
+	_, err := uc.HandlePullRequestEvent(context.Background(), openEvent())
+
+	require.ErrorIs(t, err, models.ErrIdentityNotFound)
+	prUC.AssertNotCalled(t, "CreatePR", mock.Anything, mock.Anything)
+}
+
+func TestIntegrationUsecase_Merge(t *testing.T) {
+	identities := new(mockVCSIdentityRepository)
+	prUC := new(mockPRUsecase)
+	uc := NewIntegrationUsecase(identities, prUC, testLogger())
+
+	prUC.On("MergePR", mock.Anything, "github:acme/pr-service#42").
+		Return(models.PullRequest{ID: "github:acme/pr-service#42", Status: models.StatusMerged}, nil)
+
+	event := openEvent()
+	event.Action = models.VCSActionMerge
+	res, err := uc.HandlePullRequestEvent(context.Background(), event)
+
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultMerged, res.Result)
+	identities.AssertNotCalled(t, "ResolveUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIntegrationUsecase_CloseIgnored(t *testing.T) {
+	uc := NewIntegrationUsecase(new(mockVCSIdentityRepository), new(mockPRUsecase), testLogger())
+
+	event := openEvent()
+	event.Action = models.VCSActionClose
+	res, err := uc.HandlePullRequestEvent(context.Background(), event)
+
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultIgnored, res.Result)
+	require.Nil(t, res.PR)
+}
+
+func reviewEvent() models.VCSPullRequestEvent {
+	event := openEvent()
+	event.Action = models.VCSActionReview
+	event.ReviewerLogin = "octo-bob"
+	event.Verdict = models.VerdictApproved
+	return event
+}
+
+func TestIntegrationUsecase_Review_RecordsVerdict(t *testing.T) {
+	identities := new(mockVCSIdentityRepository)
+	prUC := new(mockPRUsecase)
+	uc := NewIntegrationUsecase(identities, prUC, testLogger())
+
+	identities.On("ResolveUser", mock.Anything, "github", "octo-bob").Return("u2", nil)
+	prUC.On("RecordVerdict", mock.Anything, "github:acme/pr-service#42", "u2", models.VerdictApproved).
+		Return(models.PullRequest{ID: "github:acme/pr-service#42", Status: models.StatusOpen}, nil)
+
+	res, err := uc.HandlePullRequestEvent(context.Background(), reviewEvent())
+
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultReviewed, res.Result)
+	prUC.AssertExpectations(t)
+}
+
+func TestIntegrationUsecase_Review_NotAssignedIgnored(t *testing.T) {
+	identities := new(mockVCSIdentityRepository)
+	prUC := new(mockPRUsecase)
+	uc := NewIntegrationUsecase(identities, prUC, testLogger())
+
+	identities.On("ResolveUser", mock.Anything, "github", "octo-bob").Return("u9", nil)
+	prUC.On("RecordVerdict", mock.Anything, mock.Anything, "u9", mock.Anything).Return(models.PullRequest{}, models.ErrNotAssigned)
+
+	res, err := uc.HandlePullRequestEvent(context.Background(), reviewEvent())
+
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultIgnored, res.Result)
+}
+
+func TestIntegrationUsecase_Review_UnmappedLoginIgnored(t *testing.T) {
+	identities := new(mockVCSIdentityRepository)
+	prUC := new(mockPRUsecase)
+	uc := NewIntegrationUsecase(identities, prUC, testLogger())
+
+	identities.On("ResolveUser", mock.Anything, "github", "octo-bob").Return("", models.ErrIdentityNotFound)
+
+	res, err := uc.HandlePullRequestEvent(context.Background(), reviewEvent())
+
+	require.NoError(t, err)
+	require.Equal(t, models.VCSResultIgnored, res.Result)
+	prUC.AssertNotCalled(t, "RecordVerdict", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
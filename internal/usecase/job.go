@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"log/slog"
+)
+
+const defaultJobListLimit = 100
+
+type JobUsecase interface {
+	ListJobs(ctx context.Context, filter models.JobFilter) ([]models.Job, error)
+	GetJob(ctx context.Context, id int64) (models.Job, error)
+	RetryJob(ctx context.Context, id int64) error
+}
+
+type jobUsecase struct {
+	repo repository.JobRepository
+	log  *slog.Logger
+}
+
+func NewJobUsecase(repo repository.JobRepository, log *slog.Logger) JobUsecase {
+	return &jobUsecase{
+		repo: repo,
+		log:  log.With("layer", "usecase", "entity", "job"),
+	}
+}
+
+func (u *jobUsecase) ListJobs(ctx context.Context, filter models.JobFilter) ([]models.Job, error) {
+	ctx, span := tracing.Start(ctx, "JobUsecase.ListJobs")
+	defer span.End()
+
+	switch filter.Status {
+	case "", models.JobPending, models.JobRunning, models.JobDone, models.JobFailed:
+	default:
+		return nil, models.AppError{Code: models.ErrorValidation, Message: "status must be PENDING, RUNNING, DONE or FAILED"}
+	}
+	if filter.Limit <= 0 || filter.Limit > defaultJobListLimit {
+		filter.Limit = defaultJobListLimit
+	}
+	return u.repo.List(ctx, filter)
+}
+
+func (u *jobUsecase) GetJob(ctx context.Context, id int64) (models.Job, error) {
+	ctx, span := tracing.Start(ctx, "JobUsecase.GetJob")
+	defer span.End()
+
+	return u.repo.Get(ctx, id)
+}
+
+// RetryJob возвращает в очередь только FAILED-задачу, счётчик попыток обнуляется
+func (u *jobUsecase) RetryJob(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "JobUsecase.RetryJob")
+	defer span.End()
+
+	if err := u.repo.Retry(ctx, id); err != nil {
+		return err
+	}
+	u.log.InfoContext(ctx, "job requeued", "id", id)
+	return nil
+}
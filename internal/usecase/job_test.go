@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type mockJobRepository struct{ mock.Mock }
+
+func (m *mockJobRepository) Enqueue(ctx context.Context, job models.Job) (models.Job, bool, error) {
+	args := m.Called(ctx, job)
+	return args.Get(0).(models.Job), args.Bool(1), args.Error(2)
+}
+
+func (m *mockJobRepository) ClaimDue(ctx context.Context, kinds []string, limit int, lease time.Duration) ([]models.Job, error) {
+	args := m.Called(ctx, kinds, limit, lease)
+	return args.Get(0).([]models.Job), args.Error(1)
+}
+
+func (m *mockJobRepository) MarkDone(ctx context.Context, job models.Job) error {
+	return m.Called(ctx, job).Error(0)
+}
+
+func (m *mockJobRepository) MarkFailed(ctx context.Context, job models.Job, attempts int, runAt time.Time, lastErr string, dead bool) error {
+	return m.Called(ctx, job, attempts, runAt, lastErr, dead).Error(0)
+}
+
+func (m *mockJobRepository) List(ctx context.Context, filter models.JobFilter) ([]models.Job, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Job), args.Error(1)
+}
+
+func (m *mockJobRepository) Get(ctx context.Context, id int64) (models.Job, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Job), args.Error(1)
+}
+
+func (m *mockJobRepository) Retry(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockJobRepository) DeleteFinished(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func TestJobUsecase_ListJobs_DefaultLimit(t *testing.T) {
+	repo := new(mockJobRepository)
+	uc := NewJobUsecase(repo, testLogger())
+
+	repo.On("List", mock.Anything, models.JobFilter{Status: models.JobFailed, Limit: defaultJobListLimit}).
+		Return([]models.Job{{ID: 1, Kind: "stale_prs.check", Status: models.JobFailed}}, nil).Twice()
+
+	jobs, err := uc.ListJobs(context.Background(), models.JobFilter{Status: models.JobFailed})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+
+	_, err = uc.ListJobs(context.Background(), models.JobFilter{Status: models.JobFailed, Limit: 100000})
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestJobUsecase_ListJobs_InvalidStatus(t *testing.T) {
+	repo := new(mockJobRepository)
+	uc := NewJobUsecase(repo, testLogger())
+
+	_, err := uc.ListJobs(context.Background(), models.JobFilter{Status: "done"})
+
+	var appErr models.AppError
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, models.ErrorValidation, appErr.Code)
+	repo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestJobUsecase_RetryJob_NotFound(t *testing.T) {
+	repo := new(mockJobRepository)
+	uc := NewJobUsecase(repo, testLogger())
+
+	repo.On("Retry", mock.Anything, int64(7)).Return(models.ErrJobNotFound)
+
+	err := uc.RetryJob(context.Background(), 7)
+	require.ErrorIs(t, err, models.ErrJobNotFound)
+	repo.AssertExpectations(t)
+}
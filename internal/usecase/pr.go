@@ -1,8 +1,10 @@
 package usecase
 
 import (
+	"avito-pr-service/internal/metrics"
 	"avito-pr-service/internal/models"
 	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
 	"avito-pr-service/internal/utils"
 	"context"
 	"errors"
@@ -16,9 +18,13 @@ type PRUsecase interface {
 	CreatePR(ctx context.Context, req models.CreatePRRequest) (models.PullRequest, error)
 	GetPR(ctx context.Context, prID string) (models.PullRequest, error)
 	MergePR(ctx context.Context, prID string) (models.PullRequest, error)
+	// RecordVerdict запоминает вердикт назначенного ревьювера открытого PR
+	RecordVerdict(ctx context.Context, prID, reviewerID, verdict string) (models.PullRequest, error)
 	ReassignReviewer(ctx context.Context, req models.ReassignRequest) (models.PullRequest, string, error)
 	GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequest, error)
+	EachPRByReviewer(ctx context.Context, userID string, fn func(models.PullRequest) error) error
 	GetUserStats(ctx context.Context) ([]models.UserStats, error)
+	EachUserStats(ctx context.Context, fn func(models.UserStats) error) error
 }
 
 type prUsecase struct {
@@ -33,7 +39,10 @@ func NewPRUsecase(pr repository.PRRepository, user repository.UserRepository, te
 }
 
 func (u *prUsecase) CreatePR(ctx context.Context, req models.CreatePRRequest) (models.PullRequest, error) {
-	u.log.Info("creating PR", "id", req.ID, "author", req.AuthorID)
+	ctx, span := tracing.Start(ctx, "PRUsecase.CreatePR")
+	defer span.End()
+
+	u.log.InfoContext(ctx, "creating PR", "id", req.ID, "author", req.AuthorID)
 
 	author, err := u.userRepo.GetUser(ctx, req.AuthorID)
 	if err != nil {
@@ -73,14 +82,26 @@ func (u *prUsecase) CreatePR(ctx context.Context, req models.CreatePRRequest) (m
 		return models.PullRequest{}, err
 	}
 
+	metrics.PRsCreated.Inc()
+	metrics.ReviewersAssigned.Add(float64(len(reviewers)))
+	// PR создан, но активных кандидатов на оба места не хватило
+	if len(reviewers) < 2 {
+		metrics.NoCandidate.WithLabelValues(team.Name).Inc()
+	}
 	return pr, nil
 }
 
 func (u *prUsecase) GetPR(ctx context.Context, prID string) (models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PRUsecase.GetPR")
+	defer span.End()
+
 	return u.prRepo.GetPR(ctx, prID)
 }
 
 func (u *prUsecase) MergePR(ctx context.Context, prID string) (models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PRUsecase.MergePR")
+	defer span.End()
+
 	pr, err := u.prRepo.GetPR(ctx, prID)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
@@ -101,12 +122,29 @@ func (u *prUsecase) MergePR(ctx context.Context, prID string) (models.PullReques
 		return models.PullRequest{}, err
 	}
 
+	metrics.PRsMerged.Inc()
 	pr.Status = models.StatusMerged
 	pr.MergedAt = mergedAt
 	return pr, nil
 }
 
+func (u *prUsecase) RecordVerdict(ctx context.Context, prID, reviewerID, verdict string) (models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PRUsecase.RecordVerdict")
+	defer span.End()
+
+	if err := u.prRepo.SetVerdict(ctx, prID, reviewerID, verdict); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return models.PullRequest{}, models.ErrPRNotFound
+		}
+		return models.PullRequest{}, err
+	}
+	return u.prRepo.GetPR(ctx, prID)
+}
+
 func (u *prUsecase) ReassignReviewer(ctx context.Context, req models.ReassignRequest) (models.PullRequest, string, error) {
+	ctx, span := tracing.Start(ctx, "PRUsecase.ReassignReviewer")
+	defer span.End()
+
 	pr, err := u.prRepo.GetPR(ctx, req.PRID)
 	if err != nil {
 		return models.PullRequest{}, "", err
@@ -140,15 +178,26 @@ func (u *prUsecase) ReassignReviewer(ctx context.Context, req models.ReassignReq
 	}
 
 	if len(candidates) == 0 {
+		metrics.NoCandidate.WithLabelValues(team.Name).Inc()
 		return models.PullRequest{}, "", models.ErrNoCandidate
 	}
 
 	newUID := utils.PickRandom(candidates, 1)[0]
 
-	if reassignErr := u.prRepo.ReassignReviewer(ctx, req.PRID, req.OldReviewerID, newUID); reassignErr != nil {
+	move := u.prRepo.ReassignReviewer
+	if req.PendingOnly {
+		move = u.prRepo.MovePendingReviewer
+	}
+	if reassignErr := move(ctx, req.PRID, req.OldReviewerID, newUID); reassignErr != nil {
 		return models.PullRequest{}, "", reassignErr
 	}
 
+	reason := req.Reason
+	if reason == "" {
+		reason = models.ReassignReasonManual
+	}
+	metrics.Reassignments.WithLabelValues(reason).Inc()
+
 	freshPR, err := u.prRepo.GetPR(ctx, req.PRID)
 	if err != nil {
 		return models.PullRequest{}, "", err
@@ -158,17 +207,48 @@ func (u *prUsecase) ReassignReviewer(ctx context.Context, req models.ReassignReq
 }
 
 func (u *prUsecase) GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PRUsecase.GetPRsByReviewer")
+	defer span.End()
+
+	if err := u.checkReviewer(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return u.prRepo.GetPRsByReviewer(ctx, userID)
+}
+
+func (u *prUsecase) EachPRByReviewer(ctx context.Context, userID string, fn func(models.PullRequest) error) error {
+	ctx, span := tracing.Start(ctx, "PRUsecase.EachPRByReviewer")
+	defer span.End()
+
+	if err := u.checkReviewer(ctx, userID); err != nil {
+		return err
+	}
+
+	return u.prRepo.EachPRByReviewer(ctx, userID, fn)
+}
+
+func (u *prUsecase) checkReviewer(ctx context.Context, userID string) error {
 	_, err := u.userRepo.GetUser(ctx, userID)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
-			return nil, models.ErrUserNotFound
+			return models.ErrUserNotFound
 		}
-		return nil, fmt.Errorf("get user: %w", err)
+		return fmt.Errorf("get user: %w", err)
 	}
-
-	return u.prRepo.GetPRsByReviewer(ctx, userID)
+	return nil
 }
 
 func (u *prUsecase) GetUserStats(ctx context.Context) ([]models.UserStats, error) {
+	ctx, span := tracing.Start(ctx, "PRUsecase.GetUserStats")
+	defer span.End()
+
 	return u.prRepo.GetUserStats(ctx)
 }
+
+func (u *prUsecase) EachUserStats(ctx context.Context, fn func(models.UserStats) error) error {
+	ctx, span := tracing.Start(ctx, "PRUsecase.EachUserStats")
+	defer span.End()
+
+	return u.prRepo.EachUserStats(ctx, fn)
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"avito-pr-service/internal/metrics"
 	"avito-pr-service/internal/models"
 	"avito-pr-service/internal/utils"
 	"context"
@@ -10,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -39,6 +41,14 @@ func (m *mockPRRepository) ReassignReviewer(ctx context.Context, prID, oldUID, n
 	return m.Called(ctx, prID, oldUID, newUID).Error(0)
 }
 
+func (m *mockPRRepository) MovePendingReviewer(ctx context.Context, prID, oldUID, newUID string) error {
+	return m.Called(ctx, prID, oldUID, newUID).Error(0)
+}
+
+func (m *mockPRRepository) SetVerdict(ctx context.Context, prID, userID, verdict string) error {
+	return m.Called(ctx, prID, userID, verdict).Error(0)
+}
+
 func (m *mockPRRepository) GetPRsByReviewer(ctx context.Context, userID string) ([]models.PullRequest, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).([]models.PullRequest), args.Error(1)
@@ -49,11 +59,41 @@ func (m *mockPRRepository) GetOpenPRsWithTeamReviewers(ctx context.Context, team
 	return args.Get(0).([]models.PullRequest), args.Error(1)
 }
 
+func (m *mockPRRepository) GetReviewedAssignments(ctx context.Context, teamName string) (map[string][]string, error) {
+	args := m.Called(ctx, teamName)
+	return args.Get(0).(map[string][]string), args.Error(1)
+}
+
 func (m *mockPRRepository) GetUserStats(ctx context.Context) ([]models.UserStats, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]models.UserStats), args.Error(1)
 }
 
+func (m *mockPRRepository) EachPRByReviewer(ctx context.Context, userID string, fn func(models.PullRequest) error) error {
+	args := m.Called(ctx, userID)
+	for _, pr := range args.Get(0).([]models.PullRequest) {
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockPRRepository) EachUserStats(ctx context.Context, fn func(models.UserStats) error) error {
+	args := m.Called(ctx)
+	for _, s := range args.Get(0).([]models.UserStats) {
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockPRRepository) CountOpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
 func TestPRUsecase_CreatePR_Success(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	userRepo := new(mockUserRepository)
@@ -89,6 +129,34 @@ func TestPRUsecase_CreatePR_Success(t *testing.T) {
 	teamRepo.AssertExpectations(t)
 }
 
+func TestPRUsecase_CreatePR_NotEnoughCandidates(t *testing.T) {
+	prRepo := new(mockPRRepository)
+	userRepo := new(mockUserRepository)
+	teamRepo := new(mockTeamRepository)
+
+	author := models.User{UserID: "u1", TeamName: "mobile", IsActive: true}
+	team := models.Team{
+		Name: "mobile",
+		Members: []models.TeamMember{
+			{UserID: "u1", IsActive: true},
+			{UserID: "u2", IsActive: true},
+			{UserID: "u3", IsActive: false},
+		},
+	}
+
+	userRepo.On("GetUser", mock.Anything, "u1").Return(author, nil)
+	teamRepo.On("GetTeam", mock.Anything, "mobile").Return(team, nil)
+	prRepo.On("CreatePR", mock.Anything, mock.Anything).Return(nil)
+
+	before := testutil.ToFloat64(metrics.NoCandidate.WithLabelValues("mobile"))
+
+	uc := NewPRUsecase(prRepo, userRepo, teamRepo, testLogger())
+	pr, err := uc.CreatePR(context.Background(), models.CreatePRRequest{ID: "pr-1001", Name: "Fix login", AuthorID: "u1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+	require.Equal(t, before+1, testutil.ToFloat64(metrics.NoCandidate.WithLabelValues("mobile")))
+}
+
 func TestPRUsecase_MergePR_Success(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	userRepo := new(mockUserRepository)
@@ -179,18 +247,41 @@ func TestPRUsecase_ReassignReviewer_Success(t *testing.T) {
 	prRepo.On("ReassignReviewer", mock.Anything, "pr-1001", "u2", "u3").Return(nil)
 	prRepo.On("GetPR", mock.Anything, "pr-1001").Return(updatedPR, nil).Once()
 
+	before := testutil.ToFloat64(metrics.Reassignments.WithLabelValues(models.ReassignReasonManual))
+
 	uc := NewPRUsecase(prRepo, userRepo, teamRepo, testLogger())
 	req := models.ReassignRequest{PRID: "pr-1001", OldReviewerID: "u2"}
 	newPR, replacedBy, err := uc.ReassignReviewer(context.Background(), req)
 	require.NoError(t, err)
 	require.Equal(t, "u3", replacedBy)
 	require.Contains(t, newPR.AssignedReviewers, "u3")
+	require.Equal(t, before+1, testutil.ToFloat64(metrics.Reassignments.WithLabelValues(models.ReassignReasonManual)))
 
 	prRepo.AssertExpectations(t)
 	userRepo.AssertExpectations(t)
 	teamRepo.AssertExpectations(t)
 }
 
+func TestPRUsecase_ReassignReviewer_PendingOnly(t *testing.T) {
+	prRepo := new(mockPRRepository)
+	userRepo := new(mockUserRepository)
+	teamRepo := new(mockTeamRepository)
+
+	pr := models.PullRequest{ID: "pr-1001", Status: models.StatusOpen, AssignedReviewers: []string{"u2"}}
+	prRepo.On("GetPR", mock.Anything, "pr-1001").Return(pr, nil)
+	userRepo.On("GetUser", mock.Anything, "u2").Return(models.User{UserID: "u2", TeamName: "backend"}, nil)
+	teamRepo.On("GetTeam", mock.Anything, "backend").Return(models.Team{
+		Name:    "backend",
+		Members: []models.TeamMember{{UserID: "u2", IsActive: true}, {UserID: "u3", IsActive: true}},
+	}, nil)
+	prRepo.On("MovePendingReviewer", mock.Anything, "pr-1001", "u2", "u3").Return(models.ErrReviewSubmitted)
+
+	uc := NewPRUsecase(prRepo, userRepo, teamRepo, testLogger())
+	_, _, err := uc.ReassignReviewer(context.Background(), models.ReassignRequest{PRID: "pr-1001", OldReviewerID: "u2", PendingOnly: true})
+	require.ErrorIs(t, err, models.ErrReviewSubmitted)
+	prRepo.AssertNotCalled(t, "ReassignReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestPRUsecase_ReassignReviewer_NoCandidate(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	userRepo := new(mockUserRepository)
@@ -204,9 +295,12 @@ func TestPRUsecase_ReassignReviewer_NoCandidate(t *testing.T) {
 	userRepo.On("GetUser", mock.Anything, "u2").Return(oldUser, nil)
 	teamRepo.On("GetTeam", mock.Anything, "backend").Return(team, nil)
 
+	before := testutil.ToFloat64(metrics.NoCandidate.WithLabelValues("backend"))
+
 	uc := NewPRUsecase(prRepo, userRepo, teamRepo, testLogger())
 	_, _, err := uc.ReassignReviewer(context.Background(), models.ReassignRequest{PRID: "pr-1001", OldReviewerID: "u2"})
 	require.ErrorIs(t, err, models.ErrNoCandidate)
+	require.Equal(t, before+1, testutil.ToFloat64(metrics.NoCandidate.WithLabelValues("backend")))
 
 	prRepo.AssertExpectations(t)
 	userRepo.AssertExpectations(t)
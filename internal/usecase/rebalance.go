@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/metrics"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// при разнице в одно ревью перенос только меняет донора и получателя местами
+const minRebalanceGap = 2
+
+type RebalanceUsecase interface {
+	// RebalanceTeam переносит открытые ревью между активными участниками
+	// команды; с DryRun только возвращает план
+	RebalanceTeam(ctx context.Context, req models.RebalanceTeamRequest) (models.RebalanceTeamResponse, error)
+	// RunScheduled — обработчик задачи JobTeamRebalance: выравнивает все команды
+	RunScheduled(ctx context.Context, job models.Job) error
+}
+
+type rebalanceUsecase struct {
+	teamRepo repository.TeamRepository
+	prRepo   repository.PRRepository
+	limits   models.RebalanceLimits
+	log      *slog.Logger
+}
+
+func NewRebalanceUsecase(teamRepo repository.TeamRepository, prRepo repository.PRRepository, limits models.RebalanceLimits, log *slog.Logger) RebalanceUsecase {
+	return &rebalanceUsecase{
+		teamRepo: teamRepo,
+		prRepo:   prRepo,
+		limits:   limits,
+		log:      log.With("layer", "usecase", "entity", "rebalance"),
+	}
+}
+
+func (u *rebalanceUsecase) RebalanceTeam(ctx context.Context, req models.RebalanceTeamRequest) (models.RebalanceTeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "RebalanceUsecase.RebalanceTeam")
+	defer span.End()
+
+	resp := models.RebalanceTeamResponse{TeamName: req.TeamName, DryRun: req.DryRun, Moves: []models.ReviewerMove{}, Load: []models.ReviewerLoad{}}
+
+	team, err := u.teamRepo.GetTeam(ctx, req.TeamName)
+	if err != nil {
+		return resp, err
+	}
+	var active []string
+	for _, m := range team.Members {
+		if m.IsActive {
+			active = append(active, m.UserID)
+		}
+	}
+
+	prs, err := u.prRepo.GetOpenPRsWithTeamReviewers(ctx, req.TeamName)
+	if err != nil {
+		return resp, err
+	}
+	reviewed, err := u.prRepo.GetReviewedAssignments(ctx, req.TeamName)
+	if err != nil {
+		return resp, err
+	}
+
+	// из запроса можно только ужесточить ограничение конфигурации
+	limits := u.limits
+	if req.MaxMoves > 0 && (limits.MaxMoves == 0 || req.MaxMoves < limits.MaxMoves) {
+		limits.MaxMoves = req.MaxMoves
+	}
+	limits.MinGap = max(limits.MinGap, req.MinGap)
+
+	plan := planRebalance(prs, reviewed, active, active, limits)
+	if req.DryRun {
+		resp.Moves = plan
+	} else {
+		resp.Moves, err = applyMoves(ctx, u.prRepo, u.log, plan)
+	}
+	resp.Load = reviewerLoads(prs, reviewed, active, resp.Moves)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to rebalance team", "team", req.TeamName, "error", err)
+		return resp, err
+	}
+
+	u.log.InfoContext(ctx, "team rebalanced", "team", req.TeamName, "dry_run", req.DryRun, "planned", len(plan), "moved", len(resp.Moves))
+	return resp, nil
+}
+
+func (u *rebalanceUsecase) RunScheduled(ctx context.Context, _ models.Job) error {
+	ctx, span := tracing.Start(ctx, "RebalanceUsecase.RunScheduled")
+	defer span.End()
+
+	teams, err := u.teamRepo.ListTeams(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ошибка одной команды не мешает остальным; повтор задачи пересчитает
+	// план заново, уже выровненные команды не изменятся
+	var errs []error
+	for _, team := range teams {
+		_, err := u.RebalanceTeam(ctx, models.RebalanceTeamRequest{TeamName: team.Name})
+		// команду удалили после ListTeams
+		if errors.Is(err, models.ErrTeamNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rebalance %s: %w", team.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyMoves выполняет план; если PR успел измениться (смёржен, ревьювер
+// уже заменён или вынес вердикт), перенос пропускается
+func applyMoves(ctx context.Context, prRepo repository.PRRepository, log *slog.Logger, plan []models.ReviewerMove) ([]models.ReviewerMove, error) {
+	done := []models.ReviewerMove{}
+	for _, m := range plan {
+		err := prRepo.MovePendingReviewer(ctx, m.PRID, m.FromID, m.ToID)
+		var appErr models.AppError
+		if errors.As(err, &appErr) {
+			log.WarnContext(ctx, "rebalance move skipped", "pr", m.PRID, "from", m.FromID, "to", m.ToID, "error", err)
+			continue
+		}
+		if err != nil {
+			return done, err
+		}
+		metrics.Reassignments.WithLabelValues(models.ReassignReasonRebalance).Inc()
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// planRebalance переносит назначения с самых загруженных доноров на наименее
+// загруженных получателей, пока разница нагрузок не меньше limits.MinGap.
+// Нагрузка — число открытых PR из prs, где пользователь ревьювер и ещё не
+// вынес вердикт (reviewed: id PR -> id ревьюверов с вердиктом); вынесшие
+// вердикт не переносятся. Получатель не может быть автором PR или уже
+// стоять в нём ревьювером
+func planRebalance(prs []models.PullRequest, reviewed map[string][]string, donors, receivers []string, limits models.RebalanceLimits) []models.ReviewerMove {
+	minGap := max(limits.MinGap, minRebalanceGap)
+	reviewers := make(map[string][]string, len(prs))
+	authors := make(map[string]string, len(prs))
+	load := pendingLoad(prs, reviewed)
+	for _, pr := range prs {
+		reviewers[pr.ID] = slices.Clone(pr.AssignedReviewers)
+		authors[pr.ID] = pr.AuthorID
+	}
+
+	// порядок по нагрузке, при равенстве по id: план детерминирован
+	byLoad := func(ids []string, desc bool) []string {
+		sorted := slices.Clone(ids)
+		slices.SortFunc(sorted, func(a, b string) int {
+			if d := len(load[a]) - len(load[b]); d != 0 {
+				if desc {
+					return -d
+				}
+				return d
+			}
+			if a < b {
+				return -1
+			}
+			if a > b {
+				return 1
+			}
+			return 0
+		})
+		return sorted
+	}
+
+	moves := []models.ReviewerMove{}
+	for limits.MaxMoves <= 0 || len(moves) < limits.MaxMoves {
+		move, ok := nextMove(byLoad(donors, true), byLoad(receivers, false), load, reviewers, authors, minGap)
+		if !ok {
+			break
+		}
+		load[move.FromID] = slices.DeleteFunc(load[move.FromID], func(id string) bool { return id == move.PRID })
+		load[move.ToID] = append(load[move.ToID], move.PRID)
+		slices.Sort(load[move.ToID])
+		r := reviewers[move.PRID]
+		r[slices.Index(r, move.FromID)] = move.ToID
+		moves = append(moves, move)
+	}
+	return moves
+}
+
+func nextMove(donors, receivers []string, load, reviewers map[string][]string, authors map[string]string, minGap int) (models.ReviewerMove, bool) {
+	for _, from := range donors {
+		for _, to := range receivers {
+			if from == to || len(load[from])-len(load[to]) < minGap {
+				continue
+			}
+			for _, prID := range load[from] {
+				if authors[prID] != to && !slices.Contains(reviewers[prID], to) {
+					return models.ReviewerMove{PRID: prID, FromID: from, ToID: to}, true
+				}
+			}
+		}
+	}
+	return models.ReviewerMove{}, false
+}
+
+// pendingLoad — отсортированные id PR без вердикта для каждого ревьювера
+func pendingLoad(prs []models.PullRequest, reviewed map[string][]string) map[string][]string {
+	load := make(map[string][]string)
+	for _, pr := range prs {
+		for _, id := range pr.AssignedReviewers {
+			if !slices.Contains(reviewed[pr.ID], id) {
+				load[id] = append(load[id], pr.ID)
+			}
+		}
+	}
+	for _, ids := range load {
+		slices.Sort(ids)
+	}
+	return load
+}
+
+// reviewerLoads считает нагрузку members до и после переносов moves
+func reviewerLoads(prs []models.PullRequest, reviewed map[string][]string, members []string, moves []models.ReviewerMove) []models.ReviewerLoad {
+	load := pendingLoad(prs, reviewed)
+	after := make(map[string]int, len(members))
+	for _, id := range members {
+		after[id] = len(load[id])
+	}
+	for _, m := range moves {
+		after[m.FromID]--
+		after[m.ToID]++
+	}
+
+	loads := make([]models.ReviewerLoad, 0, len(members))
+	for _, id := range members {
+		loads = append(loads, models.ReviewerLoad{UserID: id, Before: len(load[id]), After: after[id]})
+	}
+	return loads
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPlanRebalance(t *testing.T) {
+	prs := []models.PullRequest{
+		{ID: "pr-1", AuthorID: "a", AssignedReviewers: []string{"u1", "u2"}},
+		{ID: "pr-2", AuthorID: "a", AssignedReviewers: []string{"u1", "u2"}},
+		{ID: "pr-3", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-4", AuthorID: "u4", AssignedReviewers: []string{"u1"}},
+	}
+
+	moves := planRebalance(prs, nil, []string{"u1", "u2"}, []string{"u3", "u4"}, models.RebalanceLimits{})
+
+	// u1: 4 -> 2, u3 и u4 получают по одному; pr-4 автора u4 ему не достаётся
+	require.Equal(t, []models.ReviewerMove{
+		{PRID: "pr-1", FromID: "u1", ToID: "u3"},
+		{PRID: "pr-2", FromID: "u1", ToID: "u4"},
+	}, moves)
+}
+
+func TestPlanRebalance_SkipsAlreadyAssigned(t *testing.T) {
+	prs := []models.PullRequest{
+		{ID: "pr-1", AuthorID: "a", AssignedReviewers: []string{"u1", "u2"}},
+		{ID: "pr-2", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-3", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+	}
+
+	moves := planRebalance(prs, nil, []string{"u1"}, []string{"u2"}, models.RebalanceLimits{})
+
+	require.Equal(t, []models.ReviewerMove{{PRID: "pr-2", FromID: "u1", ToID: "u2"}}, moves)
+}
+
+func TestPlanRebalance_MaxMoves(t *testing.T) {
+	prs := []models.PullRequest{
+		{ID: "pr-1", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-2", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-3", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-4", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+	}
+
+	require.Len(t, planRebalance(prs, nil, []string{"u1"}, []string{"u2"}, models.RebalanceLimits{}), 2)
+	require.Len(t, planRebalance(prs, nil, []string{"u1"}, []string{"u2"}, models.RebalanceLimits{MaxMoves: 1}), 1)
+	require.Empty(t, planRebalance(nil, nil, []string{"u1"}, []string{"u2"}, models.RebalanceLimits{}))
+}
+
+func TestPlanRebalance_KeepsReviewedAssignments(t *testing.T) {
+	prs := []models.PullRequest{
+		{ID: "pr-1", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-2", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-3", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-4", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+	}
+	reviewed := map[string][]string{"pr-1": {"u1"}, "pr-2": {"u1"}}
+	members := []string{"u1", "u2"}
+
+	// вынесенные вердикты не считаются нагрузкой: у u1 два ожидающих ревью
+	moves := planRebalance(prs, reviewed, members, members, models.RebalanceLimits{})
+	require.Equal(t, []models.ReviewerMove{{PRID: "pr-3", FromID: "u1", ToID: "u2"}}, moves)
+
+	require.Equal(t, []models.ReviewerLoad{
+		{UserID: "u1", Before: 2, After: 1},
+		{UserID: "u2", Before: 0, After: 1},
+	}, reviewerLoads(prs, reviewed, members, moves))
+}
+
+func TestPlanRebalance_MinGap(t *testing.T) {
+	prs := []models.PullRequest{
+		{ID: "pr-1", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-2", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-3", AuthorID: "a", AssignedReviewers: []string{"u1"}},
+	}
+	members := []string{"u1", "u2"}
+
+	require.Len(t, planRebalance(prs, nil, members, members, models.RebalanceLimits{MinGap: 3}), 1)
+	require.Empty(t, planRebalance(prs, nil, members, members, models.RebalanceLimits{MinGap: 4}))
+}
+
+func rebalanceFixture() (*mockTeamRepository, *mockPRRepository) {
+	teamRepo := new(mockTeamRepository)
+	prRepo := new(mockPRRepository)
+
+	teamRepo.On("GetTeam", mock.Anything, "backend").Return(models.Team{
+		Name: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", IsActive: true},
+			{UserID: "u2", IsActive: true},
+			{UserID: "u3", IsActive: false},
+		},
+	}, nil)
+	prRepo.On("GetOpenPRsWithTeamReviewers", mock.Anything, "backend").Return([]models.PullRequest{
+		{ID: "pr-1", AuthorID: "x", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-2", AuthorID: "x", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-3", AuthorID: "x", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-4", AuthorID: "x", AssignedReviewers: []string{"u1"}},
+		{ID: "pr-5", AuthorID: "x", AssignedReviewers: []string{"u1"}},
+	}, nil)
+	prRepo.On("GetReviewedAssignments", mock.Anything, "backend").Return(map[string][]string{"pr-1": {"u1"}}, nil)
+	return teamRepo, prRepo
+}
+
+func TestRebalanceUsecase_RebalanceTeam_DryRun(t *testing.T) {
+	teamRepo, prRepo := rebalanceFixture()
+	uc := NewRebalanceUsecase(teamRepo, prRepo, models.RebalanceLimits{MaxMoves: 10, MinGap: 2}, testLogger())
+
+	resp, err := uc.RebalanceTeam(context.Background(), models.RebalanceTeamRequest{TeamName: "backend", DryRun: true})
+	require.NoError(t, err)
+	require.True(t, resp.DryRun)
+	// неактивный u3 не получает ревью, pr-1 с вердиктом u1 остаётся у него
+	require.Equal(t, []models.ReviewerMove{
+		{PRID: "pr-2", FromID: "u1", ToID: "u2"},
+		{PRID: "pr-3", FromID: "u1", ToID: "u2"},
+	}, resp.Moves)
+	require.Equal(t, []models.ReviewerLoad{
+		{UserID: "u1", Before: 4, After: 2},
+		{UserID: "u2", Before: 0, After: 2},
+	}, resp.Load)
+
+	prRepo.AssertNotCalled(t, "MovePendingReviewer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRebalanceUsecase_RebalanceTeam_Apply(t *testing.T) {
+	teamRepo, prRepo := rebalanceFixture()
+	prRepo.On("MovePendingReviewer", mock.Anything, "pr-2", "u1", "u2").Return(models.ErrReviewSubmitted)
+	uc := NewRebalanceUsecase(teamRepo, prRepo, models.RebalanceLimits{MaxMoves: 1}, testLogger())
+
+	resp, err := uc.RebalanceTeam(context.Background(), models.RebalanceTeamRequest{TeamName: "backend"})
+	require.NoError(t, err)
+	// вердикт пришёл между планом и переносом: перенос пропущен
+	require.Empty(t, resp.Moves)
+	require.Equal(t, 4, resp.Load[0].After)
+
+	prRepo.AssertExpectations(t)
+}
+
+func TestRebalanceUsecase_RebalanceTeam_RequestOverridesLimits(t *testing.T) {
+	teamRepo, prRepo := rebalanceFixture()
+	uc := NewRebalanceUsecase(teamRepo, prRepo, models.RebalanceLimits{MaxMoves: 10, MinGap: 2}, testLogger())
+
+	resp, err := uc.RebalanceTeam(context.Background(), models.RebalanceTeamRequest{TeamName: "backend", DryRun: true, MaxMoves: 5, MinGap: 3})
+	require.NoError(t, err)
+	require.Len(t, resp.Moves, 1)
+
+	resp, err = uc.RebalanceTeam(context.Background(), models.RebalanceTeamRequest{TeamName: "backend", DryRun: true, MaxMoves: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.Moves, 1)
+}
+
+func TestRebalanceUsecase_RebalanceTeam_ConfigCapsMaxMoves(t *testing.T) {
+	teamRepo, prRepo := rebalanceFixture()
+	uc := NewRebalanceUsecase(teamRepo, prRepo, models.RebalanceLimits{MaxMoves: 1, MinGap: 2}, testLogger())
+
+	// запрос не может поднять rebalance.max_moves
+	resp, err := uc.RebalanceTeam(context.Background(), models.RebalanceTeamRequest{TeamName: "backend", DryRun: true, MaxMoves: 5})
+	require.NoError(t, err)
+	require.Len(t, resp.Moves, 1)
+}
+
+func TestRebalanceUsecase_RebalanceTeam_ConfigCapsMinGap(t *testing.T) {
+	teamRepo, prRepo := rebalanceFixture()
+	uc := NewRebalanceUsecase(teamRepo, prRepo, models.RebalanceLimits{MaxMoves: 10, MinGap: 3}, testLogger())
+
+	// запрос не может опустить rebalance.min_gap: при min_gap=2 было бы два переноса
+	resp, err := uc.RebalanceTeam(context.Background(), models.RebalanceTeamRequest{TeamName: "backend", DryRun: true, MinGap: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Moves, 1)
+}
+
+func TestRebalanceUsecase_RunScheduled(t *testing.T) {
+	teamRepo, prRepo := rebalanceFixture()
+	teamRepo.On("ListTeams", mock.Anything).Return([]models.Team{{Name: "backend"}, {Name: "gone"}}, nil)
+	teamRepo.On("GetTeam", mock.Anything, "gone").Return(models.Team{}, models.ErrTeamNotFound)
+	prRepo.On("MovePendingReviewer", mock.Anything, mock.Anything, "u1", "u2").Return(nil)
+	uc := NewRebalanceUsecase(teamRepo, prRepo, models.RebalanceLimits{MaxMoves: 10}, testLogger())
+
+	require.NoError(t, uc.RunScheduled(context.Background(), models.Job{Kind: models.JobTeamRebalance}))
+	prRepo.AssertNumberOfCalls(t, "MovePendingReviewer", 2)
+}
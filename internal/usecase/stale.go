@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/metrics"
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+type StaleUsecase interface {
+	// ProcessStalePRs делает по одному следующему шагу для каждого PR,
+	// простоявшего дольше порога своей команды
+	ProcessStalePRs(ctx context.Context) (models.StaleRunResult, error)
+}
+
+type staleUsecase struct {
+	repo   repository.StaleRepository
+	pr     PRUsecase
+	policy models.StalePolicy
+	log    *slog.Logger
+	now    func() time.Time
+}
+
+func NewStaleUsecase(repo repository.StaleRepository, pr PRUsecase, policy models.StalePolicy, log *slog.Logger) StaleUsecase {
+	return &staleUsecase{
+		repo:   repo,
+		pr:     pr,
+		policy: policy,
+		log:    log.With("layer", "usecase", "entity", "stale"),
+		now:    time.Now,
+	}
+}
+
+func (u *staleUsecase) ProcessStalePRs(ctx context.Context) (models.StaleRunResult, error) {
+	ctx, span := tracing.Start(ctx, "StaleUsecase.ProcessStalePRs")
+	defer span.End()
+
+	var result models.StaleRunResult
+	now := u.now()
+	prs, err := u.repo.FindStalePRs(ctx, now.Add(-u.policy.MinThreshold()))
+	if err != nil {
+		return result, err
+	}
+
+	// один сломанный PR не должен останавливать остальные
+	var errs []error
+	for _, pr := range prs {
+		team := u.policy.Team(pr.TeamName)
+		since := pr.LastActivity
+		if pr.LastStepAt != nil {
+			since = *pr.LastStepAt
+		}
+		if now.Sub(since) < team.Threshold {
+			continue
+		}
+
+		step, err := u.nextStep(ctx, pr, team)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pr %s: %w", pr.ID, err))
+			continue
+		}
+		switch step {
+		case models.StaleStepRemind:
+			result.Reminded++
+		case models.StaleStepReassign:
+			result.Reassigned++
+		case models.StaleStepEscalate:
+			result.Escalated++
+		default:
+			continue
+		}
+		metrics.StaleSteps.WithLabelValues(step).Inc()
+	}
+	return result, errors.Join(errs...)
+}
+
+// nextStep возвращает сделанный шаг или пустую строку, если PR успел
+// смёржиться или поменять ревьюверов
+func (u *staleUsecase) nextStep(ctx context.Context, pr models.StalePR, team models.StaleTeamPolicy) (string, error) {
+	switch pr.LastStep {
+	case "":
+		return models.StaleStepRemind, u.remind(ctx, pr)
+	case models.StaleStepRemind:
+		return u.reassign(ctx, pr, team)
+	case models.StaleStepReassign:
+		return models.StaleStepEscalate, u.escalate(ctx, pr, team)
+	default:
+		return "", nil
+	}
+}
+
+func (u *staleUsecase) remind(ctx context.Context, pr models.StalePR) error {
+	at := u.now()
+	u.log.InfoContext(ctx, "reminding reviewers of stale PR", "pr_id", pr.ID, "reviewers", pr.Reviewers, "inactive_since", pr.LastActivity)
+	return u.repo.RecordStep(ctx, models.StaleStep{
+		PRID: pr.ID, Step: models.StaleStepRemind, InactiveSince: pr.LastActivity, CreatedAt: at,
+	}, &models.Event{
+		Type: models.EventPRStaleReminder, OccurredAt: at, PRID: pr.ID, TeamName: pr.TeamName,
+		Data: models.PRStaleReminderData{PRID: pr.ID, ReviewerIDs: pr.Reviewers, InactiveSince: pr.LastActivity},
+	})
+}
+
+// переназначаем того, кто назначен дольше всех и ещё не вынес вердикт;
+// если таких нет или заменить некем, сразу эскалируем
+func (u *staleUsecase) reassign(ctx context.Context, pr models.StalePR, team models.StaleTeamPolicy) (string, error) {
+	i := slices.IndexFunc(pr.Reviewers, func(id string) bool { return !slices.Contains(pr.Reviewed, id) })
+	if i < 0 {
+		return models.StaleStepEscalate, u.escalate(ctx, pr, team)
+	}
+
+	oldUID := pr.Reviewers[i]
+	_, newUID, err := u.pr.ReassignReviewer(ctx, models.ReassignRequest{
+		PRID: pr.ID, OldReviewerID: oldUID, Reason: models.ReassignReasonStale, PendingOnly: true,
+	})
+	switch {
+	case errors.Is(err, models.ErrNoCandidate):
+		u.log.WarnContext(ctx, "no replacement for stale PR reviewer, escalating", "pr_id", pr.ID, "reviewer", oldUID)
+		return models.StaleStepEscalate, u.escalate(ctx, pr, team)
+	case errors.Is(err, models.ErrPRMerged), errors.Is(err, models.ErrNotAssigned), errors.Is(err, models.ErrNotFound),
+		errors.Is(err, models.ErrReviewSubmitted):
+		// PR изменился между выборкой и переназначением
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+
+	u.log.InfoContext(ctx, "reassigned stale PR reviewer", "pr_id", pr.ID, "old_reviewer", oldUID, "new_reviewer", newUID)
+	// шаг пишется после переназначения, чтобы оно не считалось новой активностью
+	return models.StaleStepReassign, u.repo.RecordStep(ctx, models.StaleStep{
+		PRID: pr.ID, Step: models.StaleStepReassign, ReviewerID: oldUID, NewReviewerID: newUID,
+		InactiveSince: pr.LastActivity, CreatedAt: u.now(),
+	}, nil)
+}
+
+func (u *staleUsecase) escalate(ctx context.Context, pr models.StalePR, team models.StaleTeamPolicy) error {
+	at := u.now()
+	if team.LeadID == "" {
+		u.log.WarnContext(ctx, "escalating stale PR without a team lead", "pr_id", pr.ID, "team", pr.TeamName)
+	} else {
+		u.log.InfoContext(ctx, "escalating stale PR to team lead", "pr_id", pr.ID, "team", pr.TeamName, "lead", team.LeadID)
+	}
+	return u.repo.RecordStep(ctx, models.StaleStep{
+		PRID: pr.ID, Step: models.StaleStepEscalate, LeadID: team.LeadID, InactiveSince: pr.LastActivity, CreatedAt: at,
+	}, &models.Event{
+		Type: models.EventPRStaleEscalated, OccurredAt: at, PRID: pr.ID, TeamName: pr.TeamName,
+		Data: models.PRStaleEscalatedData{
+			PRID: pr.ID, TeamName: pr.TeamName, LeadID: team.LeadID, ReviewerIDs: pr.Reviewers, InactiveSince: pr.LastActivity,
+		},
+	})
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type mockStaleRepository struct{ mock.Mock }
+
+func (m *mockStaleRepository) FindStalePRs(ctx context.Context, inactiveBefore time.Time) ([]models.StalePR, error) {
+	args := m.Called(ctx, inactiveBefore)
+	return args.Get(0).([]models.StalePR), args.Error(1)
+}
+
+func (m *mockStaleRepository) RecordStep(ctx context.Context, step models.StaleStep, event *models.Event) error {
+	return m.Called(ctx, step, event).Error(0)
+}
+
+func newTestStaleUsecase(repo *mockStaleRepository, pr *mockPRUsecase, now time.Time) *staleUsecase {
+	policy := models.StalePolicy{
+		Threshold: 72 * time.Hour,
+		Teams: map[string]models.StaleTeamPolicy{
+			"backend": {Threshold: 24 * time.Hour, LeadID: "lead"},
+		},
+	}
+	uc := NewStaleUsecase(repo, pr, policy, testLogger()).(*staleUsecase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestStaleUsecase_ProcessStalePRs_Ladder(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-10 * 24 * time.Hour)
+	stepAt := now.Add(-48 * time.Hour)
+
+	repo := new(mockStaleRepository)
+	prUC := new(mockPRUsecase)
+	repo.On("FindStalePRs", mock.Anything, now.Add(-24*time.Hour)).Return([]models.StalePR{
+		// порог frontend — общие 72h, а простой только 48h
+		{ID: "pr-frontend", TeamName: "frontend", Reviewers: []string{"f1"}, LastActivity: stepAt},
+		{ID: "pr-new", TeamName: "backend", Reviewers: []string{"u2", "u3"}, LastActivity: created},
+		{ID: "pr-reminded", TeamName: "backend", Reviewers: []string{"u2", "u3"}, LastActivity: created, LastStep: models.StaleStepRemind, LastStepAt: &stepAt},
+		{ID: "pr-reassigned", TeamName: "backend", Reviewers: []string{"u3", "u4"}, LastActivity: created, LastStep: models.StaleStepReassign, LastStepAt: &stepAt},
+	}, nil)
+
+	repo.On("RecordStep", mock.Anything, models.StaleStep{
+		PRID: "pr-new", Step: models.StaleStepRemind, InactiveSince: created, CreatedAt: now,
+	}, &models.Event{
+		Type: models.EventPRStaleReminder, OccurredAt: now, PRID: "pr-new", TeamName: "backend",
+		Data: models.PRStaleReminderData{PRID: "pr-new", ReviewerIDs: []string{"u2", "u3"}, InactiveSince: created},
+	}).Return(nil).Once()
+
+	prUC.On("ReassignReviewer", mock.Anything, models.ReassignRequest{
+		PRID: "pr-reminded", OldReviewerID: "u2", Reason: models.ReassignReasonStale, PendingOnly: true,
+	}).Return(models.PullRequest{}, "u4", nil).Once()
+	repo.On("RecordStep", mock.Anything, models.StaleStep{
+		PRID: "pr-reminded", Step: models.StaleStepReassign, ReviewerID: "u2", NewReviewerID: "u4", InactiveSince: created, CreatedAt: now,
+	}, (*models.Event)(nil)).Return(nil).Once()
+
+	repo.On("RecordStep", mock.Anything, models.StaleStep{
+		PRID: "pr-reassigned", Step: models.StaleStepEscalate, LeadID: "lead", InactiveSince: created, CreatedAt: now,
+	}, &models.Event{
+		Type: models.EventPRStaleEscalated, OccurredAt: now, PRID: "pr-reassigned", TeamName: "backend",
+		Data: models.PRStaleEscalatedData{PRID: "pr-reassigned", TeamName: "backend", LeadID: "lead", ReviewerIDs: []string{"u3", "u4"}, InactiveSince: created},
+	}).Return(nil).Once()
+
+	result, err := newTestStaleUsecase(repo, prUC, now).ProcessStalePRs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.StaleRunResult{Reminded: 1, Reassigned: 1, Escalated: 1}, result)
+	repo.AssertExpectations(t)
+	prUC.AssertExpectations(t)
+}
+
+func TestStaleUsecase_ProcessStalePRs_NoCandidateEscalates(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-10 * 24 * time.Hour)
+	stepAt := now.Add(-5 * 24 * time.Hour)
+
+	repo := new(mockStaleRepository)
+	prUC := new(mockPRUsecase)
+	repo.On("FindStalePRs", mock.Anything, mock.Anything).Return([]models.StalePR{
+		{ID: "pr-1", TeamName: "mobile", Reviewers: []string{"m1"}, LastActivity: created, LastStep: models.StaleStepRemind, LastStepAt: &stepAt},
+	}, nil)
+	prUC.On("ReassignReviewer", mock.Anything, mock.Anything).Return(models.PullRequest{}, "", models.ErrNoCandidate)
+	repo.On("RecordStep", mock.Anything, mock.MatchedBy(func(s models.StaleStep) bool {
+		return s.Step == models.StaleStepEscalate && s.LeadID == ""
+	}), mock.MatchedBy(func(e *models.Event) bool {
+		return e.Type == models.EventPRStaleEscalated
+	})).Return(nil).Once()
+
+	result, err := newTestStaleUsecase(repo, prUC, now).ProcessStalePRs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.StaleRunResult{Escalated: 1}, result)
+	repo.AssertExpectations(t)
+}
+
+func TestStaleUsecase_ProcessStalePRs_ContinuesAfterError(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-10 * 24 * time.Hour)
+
+	repo := new(mockStaleRepository)
+	repo.On("FindStalePRs", mock.Anything, mock.Anything).Return([]models.StalePR{
+		{ID: "pr-1", TeamName: "backend", LastActivity: created},
+		{ID: "pr-2", TeamName: "backend", LastActivity: created},
+	}, nil)
+	repo.On("RecordStep", mock.Anything, mock.MatchedBy(func(s models.StaleStep) bool { return s.PRID == "pr-1" }), mock.Anything).
+		Return(errors.New("db is down"))
+	repo.On("RecordStep", mock.Anything, mock.MatchedBy(func(s models.StaleStep) bool { return s.PRID == "pr-2" }), mock.Anything).
+		Return(nil)
+
+	result, err := newTestStaleUsecase(repo, new(mockPRUsecase), now).ProcessStalePRs(context.Background())
+	require.ErrorContains(t, err, "pr pr-1: db is down")
+	assert.Equal(t, models.StaleRunResult{Reminded: 1}, result)
+}
+
+func TestStaleUsecase_ProcessStalePRs_SkipsReviewersWithVerdict(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-10 * 24 * time.Hour)
+	stepAt := now.Add(-5 * 24 * time.Hour)
+
+	repo := new(mockStaleRepository)
+	prUC := new(mockPRUsecase)
+	repo.On("FindStalePRs", mock.Anything, mock.Anything).Return([]models.StalePR{
+		{ID: "pr-1", TeamName: "backend", Reviewers: []string{"u2", "u3"}, Reviewed: []string{"u2"}, LastActivity: created, LastStep: models.StaleStepRemind, LastStepAt: &stepAt},
+		{ID: "pr-2", TeamName: "backend", Reviewers: []string{"u2", "u3"}, Reviewed: []string{"u2", "u3"}, LastActivity: created, LastStep: models.StaleStepRemind, LastStepAt: &stepAt},
+	}, nil)
+	// одобривший u2 остаётся, заменяется u3
+	prUC.On("ReassignReviewer", mock.Anything, models.ReassignRequest{
+		PRID: "pr-1", OldReviewerID: "u3", Reason: models.ReassignReasonStale, PendingOnly: true,
+	}).Return(models.PullRequest{}, "u4", nil).Once()
+	repo.On("RecordStep", mock.Anything, mock.MatchedBy(func(s models.StaleStep) bool {
+		return s.PRID == "pr-1" && s.Step == models.StaleStepReassign && s.ReviewerID == "u3"
+	}), (*models.Event)(nil)).Return(nil).Once()
+	// все ревьюверы вынесли вердикт: переназначать некого, эскалируем
+	repo.On("RecordStep", mock.Anything, mock.MatchedBy(func(s models.StaleStep) bool {
+		return s.PRID == "pr-2" && s.Step == models.StaleStepEscalate
+	}), mock.Anything).Return(nil).Once()
+
+	result, err := newTestStaleUsecase(repo, prUC, now).ProcessStalePRs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.StaleRunResult{Reassigned: 1, Escalated: 1}, result)
+	repo.AssertExpectations(t)
+	prUC.AssertExpectations(t)
+}
+
+func TestStaleUsecase_ProcessStalePRs_VerdictDuringReassign(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	stepAt := now.Add(-5 * 24 * time.Hour)
+
+	repo := new(mockStaleRepository)
+	prUC := new(mockPRUsecase)
+	repo.On("FindStalePRs", mock.Anything, mock.Anything).Return([]models.StalePR{
+		{ID: "pr-1", TeamName: "backend", Reviewers: []string{"u2"}, LastActivity: stepAt, LastStep: models.StaleStepRemind, LastStepAt: &stepAt},
+	}, nil)
+	prUC.On("ReassignReviewer", mock.Anything, mock.Anything).Return(models.PullRequest{}, "", models.ErrReviewSubmitted)
+
+	result, err := newTestStaleUsecase(repo, prUC, now).ProcessStalePRs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.StaleRunResult{}, result)
+	repo.AssertNotCalled(t, "RecordStep", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+)
+
+type StatsUsecase interface {
+	GetUserLoad(ctx context.Context, filter models.StatsFilter) ([]models.UserReviewStats, error)
+	EachUserLoad(ctx context.Context, filter models.StatsFilter, fn func(models.UserReviewStats) error) error
+	GetTeamLoad(ctx context.Context, filter models.StatsFilter) ([]models.TeamReviewStats, error)
+	GetCycleTime(ctx context.Context, filter models.CycleTimeFilter) ([]models.CycleTimeGroup, error)
+	GetFairness(ctx context.Context, filter models.StatsFilter) ([]models.TeamFairness, error)
+}
+
+// участник помечается, если получил в полтора раза больше или вдвое
+// меньше ожидаемого; при малом ожидаемом числе отклонения — шум
+const (
+	fairnessOverloadRatio  = 1.5
+	fairnessUnderloadRatio = 0.5
+	fairnessMinExpected    = 3
+)
+
+type statsUsecase struct {
+	repo     repository.StatsRepository
+	teamRepo repository.TeamRepository
+	log      *slog.Logger
+}
+
+func NewStatsUsecase(repo repository.StatsRepository, teamRepo repository.TeamRepository, log *slog.Logger) StatsUsecase {
+	return &statsUsecase{
+		repo:     repo,
+		teamRepo: teamRepo,
+		log:      log.With("layer", "usecase", "entity", "stats"),
+	}
+}
+
+func (u *statsUsecase) GetUserLoad(ctx context.Context, filter models.StatsFilter) ([]models.UserReviewStats, error) {
+	ctx, span := tracing.Start(ctx, "StatsUsecase.GetUserLoad")
+	defer span.End()
+
+	if err := u.checkFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	stats, err := u.repo.GetReviewStats(ctx, filter)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to get review stats", "error", err)
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (u *statsUsecase) EachUserLoad(ctx context.Context, filter models.StatsFilter, fn func(models.UserReviewStats) error) error {
+	ctx, span := tracing.Start(ctx, "StatsUsecase.EachUserLoad")
+	defer span.End()
+
+	if err := u.checkFilter(ctx, filter); err != nil {
+		return err
+	}
+
+	if err := u.repo.EachReviewStats(ctx, filter, fn); err != nil {
+		u.log.ErrorContext(ctx, "failed to stream review stats", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (u *statsUsecase) GetTeamLoad(ctx context.Context, filter models.StatsFilter) ([]models.TeamReviewStats, error) {
+	ctx, span := tracing.Start(ctx, "StatsUsecase.GetTeamLoad")
+	defer span.End()
+
+	users, err := u.GetUserLoad(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byTeam := make(map[string]*models.TeamReviewStats)
+	for _, s := range users {
+		team, ok := byTeam[s.TeamName]
+		if !ok {
+			team = &models.TeamReviewStats{TeamName: s.TeamName}
+			byTeam[s.TeamName] = team
+		}
+		team.Members++
+		team.Add(s.ReviewLoad)
+	}
+
+	teams := make([]models.TeamReviewStats, 0, len(byTeam))
+	for _, t := range byTeam {
+		teams = append(teams, *t)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		li, lj := teams[i].OpenReviews+teams[i].MergedReviews, teams[j].OpenReviews+teams[j].MergedReviews
+		if li != lj {
+			return li > lj
+		}
+		return teams[i].TeamName < teams[j].TeamName
+	})
+	return teams, nil
+}
+
+// GetCycleTime группирует строки репозитория: сначала самые медленные
+// по медиане группы, внутри группы недели по порядку
+func (u *statsUsecase) GetCycleTime(ctx context.Context, filter models.CycleTimeFilter) ([]models.CycleTimeGroup, error) {
+	ctx, span := tracing.Start(ctx, "StatsUsecase.GetCycleTime")
+	defer span.End()
+
+	switch filter.GroupBy {
+	case models.CycleTimeByTeam, models.CycleTimeByAuthor, models.CycleTimeByReviewer:
+	default:
+		return nil, models.AppError{Code: models.ErrorValidation, Message: "group_by must be team, author or reviewer"}
+	}
+	if err := u.checkWindow(ctx, filter.From, filter.To, filter.TeamName); err != nil {
+		return nil, err
+	}
+
+	rows, err := u.repo.GetCycleTimes(ctx, filter)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to get cycle times", "error", err)
+		return nil, err
+	}
+
+	var groups []models.CycleTimeGroup
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, ok := index[row.Key]
+		if !ok {
+			i = len(groups)
+			index[row.Key] = i
+			groups = append(groups, models.CycleTimeGroup{Key: row.Key, Weeks: []models.CycleTimeWeek{}})
+		}
+		if row.WeekStart == nil {
+			groups[i].TimeToMerge = row.CycleTime
+			groups[i].TimeToFirstReview = row.FirstReview
+			continue
+		}
+		groups[i].Weeks = append(groups[i].Weeks, models.CycleTimeWeek{
+			WeekStart:         *row.WeekStart,
+			TimeToMerge:       row.CycleTime,
+			TimeToFirstReview: row.FirstReview,
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].TimeToMerge.MedianSeconds != groups[j].TimeToMerge.MedianSeconds {
+			return groups[i].TimeToMerge.MedianSeconds > groups[j].TimeToMerge.MedianSeconds
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups, nil
+}
+
+// GetFairness сравнивает назначения участников с равной долей;
+// неактивные участники попадают в отчёт, но в метриках не участвуют
+func (u *statsUsecase) GetFairness(ctx context.Context, filter models.StatsFilter) ([]models.TeamFairness, error) {
+	ctx, span := tracing.Start(ctx, "StatsUsecase.GetFairness")
+	defer span.End()
+
+	if err := u.checkWindow(ctx, filter.From, filter.To, filter.TeamName); err != nil {
+		return nil, err
+	}
+
+	rows, err := u.repo.GetFairness(ctx, filter)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to get fairness", "error", err)
+		return nil, err
+	}
+
+	var teams []models.TeamFairness
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].TeamName == rows[start].TeamName {
+			end++
+		}
+		teams = append(teams, teamFairness(rows[start:end]))
+		start = end
+	}
+	return teams, nil
+}
+
+func teamFairness(rows []models.FairnessRow) models.TeamFairness {
+	team := models.TeamFairness{TeamName: rows[0].TeamName, Members: make([]models.MemberFairness, 0, len(rows))}
+	var expectedTotal float64
+	for _, r := range rows {
+		team.Assignments += r.Assignments
+		expectedTotal += r.Expected
+	}
+
+	var ratios []float64
+	for _, r := range rows {
+		m := models.MemberFairness{
+			UserID:      r.UserID,
+			Username:    r.Username,
+			IsActive:    r.IsActive,
+			Assignments: r.Assignments,
+			Expected:    r.Expected,
+		}
+		if team.Assignments > 0 {
+			m.Share = float64(r.Assignments) / float64(team.Assignments)
+		}
+		if expectedTotal > 0 {
+			m.ExpectedShare = r.Expected / expectedTotal
+		}
+		if r.IsActive && r.Expected > 0 {
+			ratio := float64(r.Assignments) / r.Expected
+			m.LoadRatio = &ratio
+			ratios = append(ratios, ratio)
+			if r.Expected >= fairnessMinExpected {
+				switch {
+				case ratio >= fairnessOverloadRatio:
+					m.Flag = models.FairnessOverloaded
+				case ratio <= fairnessUnderloadRatio:
+					m.Flag = models.FairnessUnderloaded
+				}
+			}
+		}
+		team.Members = append(team.Members, m)
+	}
+
+	team.Gini = gini(ratios)
+	if len(ratios) > 0 {
+		lo, hi := ratios[0], ratios[0]
+		for _, r := range ratios[1:] {
+			lo, hi = min(lo, r), max(hi, r)
+		}
+		if lo > 0 {
+			ratio := hi / lo
+			team.MaxMinRatio = &ratio
+		}
+	}
+	return team
+}
+
+// коэффициент Джини: 0 — нагрузка равная, ближе к 1 — всё у одного
+func gini(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum, diffs float64
+	for _, a := range values {
+		sum += a
+		for _, b := range values {
+			diffs += math.Abs(a - b)
+		}
+	}
+	if sum == 0 {
+		return 0
+	}
+	n := float64(len(values))
+	return diffs / (2 * n * sum)
+}
+
+func (u *statsUsecase) checkFilter(ctx context.Context, filter models.StatsFilter) error {
+	switch filter.Status {
+	case "", models.StatusOpen, models.StatusMerged:
+	default:
+		return models.AppError{Code: models.ErrorValidation, Message: "status must be OPEN or MERGED"}
+	}
+	return u.checkWindow(ctx, filter.From, filter.To, filter.TeamName)
+}
+
+func (u *statsUsecase) checkWindow(ctx context.Context, from, to *time.Time, teamName string) error {
+	if from != nil && to != nil && !from.Before(*to) {
+		return models.AppError{Code: models.ErrorValidation, Message: "from must be before to"}
+	}
+	if teamName != "" {
+		if _, err := u.teamRepo.GetTeam(ctx, teamName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
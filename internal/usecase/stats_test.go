@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type mockStatsRepository struct{ mock.Mock }
+
+func (m *mockStatsRepository) GetReviewStats(ctx context.Context, filter models.StatsFilter) ([]models.UserReviewStats, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.UserReviewStats), args.Error(1)
+}
+
+func (m *mockStatsRepository) EachReviewStats(ctx context.Context, filter models.StatsFilter, fn func(models.UserReviewStats) error) error {
+	args := m.Called(ctx, filter)
+	for _, s := range args.Get(0).([]models.UserReviewStats) {
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func TestStatsUsecase_GetTeamLoad(t *testing.T) {
+	repo := new(mockStatsRepository)
+	repo.On("GetReviewStats", mock.Anything, models.StatsFilter{}).Return([]models.UserReviewStats{
+		{UserID: "u1", TeamName: "backend", ReviewLoad: models.ReviewLoad{OpenReviews: 1, AuthoredPRs: 2, ReassignedOut: 1}},
+		{UserID: "u2", TeamName: "backend", ReviewLoad: models.ReviewLoad{MergedReviews: 3, ReassignedIn: 1}},
+		{UserID: "u3", TeamName: "frontend", ReviewLoad: models.ReviewLoad{OpenReviews: 1}},
+		{UserID: "u4", TeamName: "mobile"},
+	}, nil)
+	uc := NewStatsUsecase(repo, new(mockTeamRepository), testLogger())
+
+	teams, err := uc.GetTeamLoad(context.Background(), models.StatsFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []models.TeamReviewStats{
+		{TeamName: "backend", Members: 2, ReviewLoad: models.ReviewLoad{OpenReviews: 1, MergedReviews: 3, AuthoredPRs: 2, ReassignedIn: 1, ReassignedOut: 1}},
+		{TeamName: "frontend", Members: 1, ReviewLoad: models.ReviewLoad{OpenReviews: 1}},
+		{TeamName: "mobile", Members: 1},
+	}, teams)
+}
+
+func TestStatsUsecase_GetUserLoad_InvalidFilter(t *testing.T) {
+	from := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, -14)
+
+	tests := []struct {
+		name   string
+		filter models.StatsFilter
+		want   string
+	}{
+		{"reversed window", models.StatsFilter{From: &from, To: &to}, "from must be before to"},
+		{"empty window", models.StatsFilter{From: &from, To: &from}, "from must be before to"},
+		{"unknown status", models.StatsFilter{Status: "CLOSED"}, "status must be OPEN or MERGED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockStatsRepository)
+			uc := NewStatsUsecase(repo, new(mockTeamRepository), testLogger())
+
+			_, err := uc.GetUserLoad(context.Background(), tt.filter)
+			require.ErrorIs(t, err, models.AppError{Code: models.ErrorValidation})
+			require.EqualError(t, err, tt.want)
+			repo.AssertNotCalled(t, "GetReviewStats", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestStatsUsecase_GetUserLoad_UnknownTeam(t *testing.T) {
+	repo := new(mockStatsRepository)
+	teamRepo := new(mockTeamRepository)
+	teamRepo.On("GetTeam", mock.Anything, "ghosts").Return(models.Team{}, models.ErrTeamNotFound)
+	uc := NewStatsUsecase(repo, teamRepo, testLogger())
+
+	_, err := uc.GetUserLoad(context.Background(), models.StatsFilter{TeamName: "ghosts"})
+	require.ErrorIs(t, err, models.ErrTeamNotFound)
+	repo.AssertNotCalled(t, "GetReviewStats", mock.Anything, mock.Anything)
+}
+
+func (m *mockStatsRepository) GetCycleTimes(ctx context.Context, filter models.CycleTimeFilter) ([]models.CycleTimeRow, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.CycleTimeRow), args.Error(1)
+}
+
+func TestStatsUsecase_GetCycleTime(t *testing.T) {
+	week1 := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	week2 := week1.AddDate(0, 0, 7)
+	filter := models.CycleTimeFilter{GroupBy: models.CycleTimeByTeam}
+
+	repo := new(mockStatsRepository)
+	repo.On("GetCycleTimes", mock.Anything, filter).Return([]models.CycleTimeRow{
+		{
+			Key:         "backend",
+			CycleTime:   models.CycleTime{Count: 3, MedianSeconds: 3600, P90Seconds: 7200, P99Seconds: 7200},
+			FirstReview: models.CycleTime{Count: 2, MedianSeconds: 600, P90Seconds: 900, P99Seconds: 900},
+		},
+		{Key: "backend", WeekStart: &week1, CycleTime: models.CycleTime{Count: 2, MedianSeconds: 1800}, FirstReview: models.CycleTime{Count: 2, MedianSeconds: 600}},
+		{Key: "backend", WeekStart: &week2, CycleTime: models.CycleTime{Count: 1, MedianSeconds: 7200}},
+		{Key: "mobile", CycleTime: models.CycleTime{Count: 1, MedianSeconds: 86400}},
+		{Key: "mobile", WeekStart: &week2, CycleTime: models.CycleTime{Count: 1, MedianSeconds: 86400}},
+	}, nil)
+	uc := NewStatsUsecase(repo, new(mockTeamRepository), testLogger())
+
+	groups, err := uc.GetCycleTime(context.Background(), filter)
+	require.NoError(t, err)
+	require.Equal(t, []models.CycleTimeGroup{
+		{
+			Key:         "mobile",
+			TimeToMerge: models.CycleTime{Count: 1, MedianSeconds: 86400},
+			Weeks:       []models.CycleTimeWeek{{WeekStart: week2, TimeToMerge: models.CycleTime{Count: 1, MedianSeconds: 86400}}},
+		},
+		{
+			Key:               "backend",
+			TimeToMerge:       models.CycleTime{Count: 3, MedianSeconds: 3600, P90Seconds: 7200, P99Seconds: 7200},
+			TimeToFirstReview: models.CycleTime{Count: 2, MedianSeconds: 600, P90Seconds: 900, P99Seconds: 900},
+			Weeks: []models.CycleTimeWeek{
+				{WeekStart: week1, TimeToMerge: models.CycleTime{Count: 2, MedianSeconds: 1800}, TimeToFirstReview: models.CycleTime{Count: 2, MedianSeconds: 600}},
+				{WeekStart: week2, TimeToMerge: models.CycleTime{Count: 1, MedianSeconds: 7200}},
+			},
+		},
+	}, groups)
+}
+
+func TestStatsUsecase_GetCycleTime_InvalidGroupBy(t *testing.T) {
+	repo := new(mockStatsRepository)
+	uc := NewStatsUsecase(repo, new(mockTeamRepository), testLogger())
+
+	_, err := uc.GetCycleTime(context.Background(), models.CycleTimeFilter{GroupBy: "repository"})
+	require.EqualError(t, err, "group_by must be team, author or reviewer")
+	repo.AssertNotCalled(t, "GetCycleTimes", mock.Anything, mock.Anything)
+}
+
+func (m *mockStatsRepository) GetFairness(ctx context.Context, filter models.StatsFilter) ([]models.FairnessRow, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.FairnessRow), args.Error(1)
+}
+
+func TestStatsUsecase_GetFairness(t *testing.T) {
+	repo := new(mockStatsRepository)
+	repo.On("GetFairness", mock.Anything, models.StatsFilter{}).Return([]models.FairnessRow{
+		{TeamName: "backend", UserID: "u1", IsActive: true, Assignments: 8, Expected: 4},
+		{TeamName: "backend", UserID: "u2", IsActive: true, Assignments: 2, Expected: 4},
+		{TeamName: "backend", UserID: "u3", IsActive: true, Assignments: 2, Expected: 4},
+		{TeamName: "backend", UserID: "u4", IsActive: false, Assignments: 1},
+		{TeamName: "mobile", UserID: "u5", IsActive: true, Assignments: 1, Expected: 1},
+		{TeamName: "mobile", UserID: "u6", IsActive: true, Assignments: 0, Expected: 1},
+	}, nil)
+	uc := NewStatsUsecase(repo, new(mockTeamRepository), testLogger())
+
+	teams, err := uc.GetFairness(context.Background(), models.StatsFilter{})
+	require.NoError(t, err)
+	require.Len(t, teams, 2)
+
+	backend := teams[0]
+	require.Equal(t, "backend", backend.TeamName)
+	require.Equal(t, 13, backend.Assignments)
+	// нагрузки 2, 0.5, 0.5: Джини = (2·1.5·2) / (2·3·3) = 1/3
+	require.InDelta(t, 1.0/3, backend.Gini, 1e-9)
+	require.NotNil(t, backend.MaxMinRatio)
+	require.InDelta(t, 4, *backend.MaxMinRatio, 1e-9)
+	require.Equal(t, models.FairnessOverloaded, backend.Members[0].Flag)
+	require.Equal(t, models.FairnessUnderloaded, backend.Members[1].Flag)
+	require.InDelta(t, 1.0/3, backend.Members[1].ExpectedShare, 1e-9)
+	require.Nil(t, backend.Members[3].LoadRatio)
+	require.Empty(t, backend.Members[3].Flag)
+
+	// ожидаемых назначений мало, флаги не ставятся, а min = 0 убирает max/min
+	mobile := teams[1]
+	require.Equal(t, "mobile", mobile.TeamName)
+	require.InDelta(t, 0.5, mobile.Gini, 1e-9)
+	require.Nil(t, mobile.MaxMinRatio)
+	require.Empty(t, mobile.Members[1].Flag)
+}
@@ -1,38 +1,54 @@
 package usecase
 
 import (
+	"avito-pr-service/internal/importer"
 	"avito-pr-service/internal/models"
 	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 )
 
 type TeamUsecase interface {
 	AddTeam(ctx context.Context, team models.Team) error
 	GetTeam(ctx context.Context, name string) (models.Team, error)
+	ListTeams(ctx context.Context) ([]models.Team, error)
 	DeactivateTeam(ctx context.Context, req models.DeactivateTeamRequest) (models.DeactivateTeamResponse, error)
+	ActivateTeam(ctx context.Context, req models.ActivateTeamRequest) (models.ActivateTeamResponse, error)
+	StartDeactivation(ctx context.Context, req models.DeactivateTeamRequest) (models.TeamDeactivation, error)
+	GetDeactivation(ctx context.Context, id int64) (models.TeamDeactivation, error)
+	// RunDeactivation — обработчик задачи models.JobTeamDeactivation
+	RunDeactivation(ctx context.Context, job models.Job) error
+	ImportTeams(ctx context.Context, req models.ImportRequest) (models.ImportResult, error)
 }
 
 type teamUsecase struct {
-	repo     repository.TeamRepository
-	userRepo repository.UserRepository
-	prRepo   repository.PRRepository
-	prUC     PRUsecase
-	log      *slog.Logger
+	repo          repository.TeamRepository
+	userRepo      repository.UserRepository
+	prRepo        repository.PRRepository
+	deactivations repository.DeactivationRepository
+	prUC          PRUsecase
+	log           *slog.Logger
 }
 
-func NewTeamUsecase(repo repository.TeamRepository, userRepo repository.UserRepository, prRepo repository.PRRepository, prUC PRUsecase, log *slog.Logger) TeamUsecase {
+func NewTeamUsecase(repo repository.TeamRepository, userRepo repository.UserRepository, prRepo repository.PRRepository, deactivations repository.DeactivationRepository, prUC PRUsecase, log *slog.Logger) TeamUsecase {
 	return &teamUsecase{
-		repo:     repo,
-		userRepo: userRepo,
-		prRepo:   prRepo,
-		prUC:     prUC,
-		log:      log.With("layer", "usecase", "entity", "team"),
+		repo:          repo,
+		userRepo:      userRepo,
+		prRepo:        prRepo,
+		deactivations: deactivations,
+		prUC:          prUC,
+		log:           log.With("layer", "usecase", "entity", "team"),
 	}
 }
 
 func (u *teamUsecase) AddTeam(ctx context.Context, team models.Team) error {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.AddTeam")
+	defer span.End()
+
 	seen := make(map[string]bool)
 	for _, m := range team.Members {
 		if seen[m.UserID] {
@@ -57,19 +73,22 @@ func (u *teamUsecase) AddTeam(ctx context.Context, team models.Team) error {
 
 	_, err := u.repo.GetTeam(ctx, team.Name)
 	if err == nil {
-		u.log.Warn("team already exists", "team_name", team.Name)
+		u.log.WarnContext(ctx, "team already exists", "team_name", team.Name)
 		return models.ErrTeamExists
 	}
 	if !errors.Is(err, models.ErrTeamNotFound) {
-		u.log.Error("database error on GetTeam", "error", err)
+		u.log.ErrorContext(ctx, "database error on GetTeam", "error", err)
 		return err
 	}
 
-	u.log.Info("creating new team", "team_name", team.Name)
+	u.log.InfoContext(ctx, "creating new team", "team_name", team.Name)
 	return u.repo.CreateTeam(ctx, team)
 }
 
 func (u *teamUsecase) GetTeam(ctx context.Context, name string) (models.Team, error) {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.GetTeam")
+	defer span.End()
+
 	team, err := u.repo.GetTeam(ctx, name)
 	if errors.Is(err, models.ErrTeamNotFound) {
 		return team, models.ErrTeamNotFound
@@ -77,7 +96,17 @@ func (u *teamUsecase) GetTeam(ctx context.Context, name string) (models.Team, er
 	return team, err
 }
 
+func (u *teamUsecase) ListTeams(ctx context.Context) ([]models.Team, error) {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.ListTeams")
+	defer span.End()
+
+	return u.repo.ListTeams(ctx)
+}
+
 func (u *teamUsecase) DeactivateTeam(ctx context.Context, req models.DeactivateTeamRequest) (models.DeactivateTeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.DeactivateTeam")
+	defer span.End()
+
 	resp := models.DeactivateTeamResponse{}
 
 	_, err := u.repo.GetTeam(ctx, req.TeamName)
@@ -88,40 +117,192 @@ func (u *teamUsecase) DeactivateTeam(ctx context.Context, req models.DeactivateT
 		return resp, err
 	}
 
+	// асинхронная деактивация сама переназначит PR и выключит участников
+	deactivating, err := u.deactivations.InProgress(ctx, req.TeamName)
+	if err != nil {
+		return resp, err
+	}
+	if deactivating {
+		return resp, models.ErrTeamDeactivating
+	}
+
 	prs, err := u.prRepo.GetOpenPRsWithTeamReviewers(ctx, req.TeamName)
 	if err != nil {
-		u.log.Error("failed to get PRs", "error", err)
+		u.log.ErrorContext(ctx, "failed to get PRs", "error", err)
 		return resp, err
 	}
 
 	for _, pr := range prs {
-		for _, reviewerID := range pr.AssignedReviewers {
-			user, getErr := u.userRepo.GetUser(ctx, reviewerID)
-			if getErr != nil || user.TeamName != req.TeamName {
-				continue
-			}
-
-			reassignReq := models.ReassignRequest{
-				PRID:          pr.ID,
-				OldReviewerID: reviewerID,
-			}
-
-			_, _, err = u.prUC.ReassignReviewer(ctx, reassignReq)
-			if err == nil {
-				resp.ReassignedPRs++
-			} else if !errors.Is(err, models.ErrNoCandidate) {
-				u.log.Warn("failed to reassign", "pr", pr.ID, "old", reviewerID, "error", err)
-			}
+		reassigned, _, err := u.reassignTeamReviewers(ctx, pr, req.TeamName)
+		resp.ReassignedPRs += reassigned
+		if err != nil {
+			return resp, err
 		}
 	}
 
-	deactivated, err := u.userRepo.DeactivateTeam(ctx, req.TeamName)
+	deactivated, err := u.userRepo.DeactivateTeam(ctx, req.TeamName, resp.ReassignedPRs)
 	if err != nil {
-		u.log.Error("failed to deactivate team", "team", req.TeamName, "error", err)
+		u.log.ErrorContext(ctx, "failed to deactivate team", "team", req.TeamName, "error", err)
 		return resp, err
 	}
 	resp.DeactivatedUsers = deactivated
 
-	u.log.Info("team deactivated", "team", req.TeamName, "users", deactivated, "reassigned", resp.ReassignedPRs)
+	u.log.InfoContext(ctx, "team deactivated", "team", req.TeamName, "users", deactivated, "reassigned", resp.ReassignedPRs)
 	return resp, nil
 }
+
+// ActivateTeam отменяет последнюю деактивацию: включаются только те, кто
+// был активен до неё. С Rebalance часть открытых ревью команды переносится
+// на вернувшихся, чтобы выровнять нагрузку
+func (u *teamUsecase) ActivateTeam(ctx context.Context, req models.ActivateTeamRequest) (models.ActivateTeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.ActivateTeam")
+	defer span.End()
+
+	resp := models.ActivateTeamResponse{ActivatedUsers: []string{}, Moves: []models.ReviewerMove{}}
+
+	if _, err := u.repo.GetTeam(ctx, req.TeamName); err != nil {
+		return resp, err
+	}
+
+	activated, err := u.userRepo.ActivateTeam(ctx, req.TeamName)
+	if err != nil {
+		return resp, err
+	}
+	if len(activated) > 0 {
+		resp.ActivatedUsers = activated
+	}
+
+	if req.Rebalance && len(activated) > 0 {
+		moves, err := u.rebalanceOnto(ctx, req.TeamName, activated)
+		resp.Moves = moves
+		if err != nil {
+			u.log.ErrorContext(ctx, "failed to rebalance team", "team", req.TeamName, "error", err)
+			return resp, err
+		}
+	}
+
+	u.log.InfoContext(ctx, "team activated", "team", req.TeamName, "users", len(resp.ActivatedUsers), "moved", len(resp.Moves))
+	return resp, nil
+}
+
+// rebalanceOnto переносит на receivers ревью остальных участников команды
+func (u *teamUsecase) rebalanceOnto(ctx context.Context, teamName string, receivers []string) ([]models.ReviewerMove, error) {
+	team, err := u.repo.GetTeam(ctx, teamName)
+	if err != nil {
+		return []models.ReviewerMove{}, err
+	}
+	prs, err := u.prRepo.GetOpenPRsWithTeamReviewers(ctx, teamName)
+	if err != nil {
+		return []models.ReviewerMove{}, err
+	}
+	reviewed, err := u.prRepo.GetReviewedAssignments(ctx, teamName)
+	if err != nil {
+		return []models.ReviewerMove{}, err
+	}
+
+	var donors []string
+	for _, m := range team.Members {
+		if m.IsActive && !slices.Contains(receivers, m.UserID) {
+			donors = append(donors, m.UserID)
+		}
+	}
+	return applyMoves(ctx, u.prRepo, u.log, planRebalance(prs, reviewed, donors, receivers, models.RebalanceLimits{}))
+}
+
+// reassignTeamReviewers заменяет ревьюверов PR из команды teamName; stuck —
+// те, кого заменить не удалось. Ошибку возвращает только отмена ctx
+func (u *teamUsecase) reassignTeamReviewers(ctx context.Context, pr models.PullRequest, teamName string) (reassigned, stuck int, err error) {
+	for _, reviewerID := range pr.AssignedReviewers {
+		user, getErr := u.userRepo.GetUser(ctx, reviewerID)
+		if getErr != nil || user.TeamName != teamName {
+			continue
+		}
+
+		reassignReq := models.ReassignRequest{
+			PRID:          pr.ID,
+			OldReviewerID: reviewerID,
+			Reason:        models.ReassignReasonTeamDeactivation,
+		}
+
+		_, _, err = u.prUC.ReassignReviewer(ctx, reassignReq)
+		switch {
+		case err == nil:
+			reassigned++
+		case ctx.Err() != nil:
+			return reassigned, stuck, ctx.Err()
+		case errors.Is(err, models.ErrNoCandidate):
+			stuck++
+		default:
+			stuck++
+			u.log.WarnContext(ctx, "failed to reassign", "pr", pr.ID, "old", reviewerID, "error", err)
+		}
+	}
+	return reassigned, stuck, nil
+}
+
+func (u *teamUsecase) ImportTeams(ctx context.Context, req models.ImportRequest) (models.ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "TeamUsecase.ImportTeams")
+	defer span.End()
+
+	rows, errs := importer.Parse(req.Data, req.Format)
+	errs = append(errs, validateImportRows(rows)...)
+	if len(rows) == 0 && len(errs) == 0 {
+		errs = append(errs, models.ImportRowError{Location: "file", Message: "file contains no members"})
+	}
+	if len(errs) > 0 {
+		u.log.WarnContext(ctx, "import file rejected", "format", req.Format, "errors", len(errs))
+		return models.ImportResult{}, &models.ImportValidationError{Errors: errs}
+	}
+
+	diff, err := u.repo.ImportTeams(ctx, rows, req.DryRun)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to import teams", "error", err)
+		return models.ImportResult{}, err
+	}
+
+	u.log.InfoContext(ctx, "teams imported", "dry_run", req.DryRun, "rows", len(rows),
+		"teams_created", len(diff.TeamsCreated), "users_created", len(diff.UsersCreated), "users_updated", len(diff.UsersUpdated))
+	return models.ImportResult{DryRun: req.DryRun, Diff: diff}, nil
+}
+
+// в отличие от /team/add пользователь может быть переведён в другую команду,
+// но в пределах одного файла он должен встречаться ровно один раз
+func validateImportRows(rows []models.ImportRow) []models.ImportRowError {
+	var errs []models.ImportRowError
+	seen := make(map[string]models.ImportRow, len(rows))
+	for _, row := range rows {
+		invalid := false
+		for _, f := range []struct{ name, value string }{
+			{"team_name", row.TeamName},
+			{"user_id", row.UserID},
+			{"username", row.Username},
+		} {
+			if f.value == "" {
+				errs = append(errs, models.ImportRowError{Location: row.Location, Field: f.name, Message: "is required"})
+				invalid = true
+			}
+		}
+		if invalid {
+			continue
+		}
+
+		first, ok := seen[row.UserID]
+		switch {
+		case !ok:
+			seen[row.UserID] = row
+		case first.TeamName == row.TeamName:
+			errs = append(errs, models.ImportRowError{
+				Location: row.Location,
+				Field:    "user_id",
+				Message:  fmt.Sprintf("duplicate user_id %q, first listed at %s", row.UserID, first.Location),
+			})
+		default:
+			errs = append(errs, models.ImportRowError{
+				Location: row.Location,
+				Field:    "user_id",
+				Message:  fmt.Sprintf("user %q is also listed in team %q at %s", row.UserID, first.TeamName, first.Location),
+			})
+		}
+	}
+	return errs
+}
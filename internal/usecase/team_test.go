@@ -1,11 +1,16 @@
 package usecase
 
 import (
+	"avito-pr-service/internal/metrics"
 	"avito-pr-service/internal/models"
 	"context"
 	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"testing"
 )
 
@@ -20,13 +25,23 @@ func (m *mockTeamRepository) GetTeam(ctx context.Context, name string) (models.T
 	return args.Get(0).(models.Team), args.Error(1)
 }
 
+func (m *mockTeamRepository) ListTeams(ctx context.Context) ([]models.Team, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Team), args.Error(1)
+}
+
+func (m *mockTeamRepository) ImportTeams(ctx context.Context, rows []models.ImportRow, dryRun bool) (models.ImportDiff, error) {
+	args := m.Called(ctx, rows, dryRun)
+	return args.Get(0).(models.ImportDiff), args.Error(1)
+}
+
 func TestTeamUsecase_AddTeam_AlreadyExists(t *testing.T) {
 	repo := new(mockTeamRepository)
 	userRepo := new(mockUserRepository)
 	prRepo := new(mockPRRepository)
 	prUC := NewPRUsecase(prRepo, userRepo, repo, testLogger())
 
-	uc := NewTeamUsecase(repo, userRepo, prRepo, prUC, testLogger())
+	uc := NewTeamUsecase(repo, userRepo, prRepo, nil, prUC, testLogger())
 
 	team := models.Team{
 		Name: "avito",
@@ -55,7 +70,7 @@ func TestTeamUsecase_AddTeam_Success(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	prUC := NewPRUsecase(prRepo, userRepo, repo, testLogger())
 
-	uc := NewTeamUsecase(repo, userRepo, prRepo, prUC, testLogger())
+	uc := NewTeamUsecase(repo, userRepo, prRepo, nil, prUC, testLogger())
 
 	team := models.Team{
 		Name: "new-team",
@@ -86,7 +101,7 @@ func TestTeamUsecase_AddTeam_EmptyMembers(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	prUC := NewPRUsecase(prRepo, userRepo, repo, testLogger())
 
-	uc := NewTeamUsecase(repo, userRepo, prRepo, prUC, testLogger())
+	uc := NewTeamUsecase(repo, userRepo, prRepo, nil, prUC, testLogger())
 
 	team := models.Team{
 		Name:    "empty-team",
@@ -105,7 +120,7 @@ func TestTeamUsecase_AddTeam_DuplicateUserID(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	prUC := NewPRUsecase(prRepo, userRepo, repo, testLogger())
 
-	uc := NewTeamUsecase(repo, userRepo, prRepo, prUC, testLogger())
+	uc := NewTeamUsecase(repo, userRepo, prRepo, nil, prUC, testLogger())
 
 	team := models.Team{
 		Name: "duplicate-team",
@@ -127,7 +142,7 @@ func TestTeamUsecase_AddTeam_UserInAnotherTeam(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	prUC := NewPRUsecase(prRepo, userRepo, repo, testLogger())
 
-	uc := NewTeamUsecase(repo, userRepo, prRepo, prUC, testLogger())
+	uc := NewTeamUsecase(repo, userRepo, prRepo, nil, prUC, testLogger())
 
 	team := models.Team{
 		Name: "new-team",
@@ -158,7 +173,7 @@ func TestTeamUsecase_GetTeam_NotFound(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	prUC := NewPRUsecase(prRepo, userRepo, repo, testLogger())
 
-	uc := NewTeamUsecase(repo, userRepo, prRepo, prUC, testLogger())
+	uc := NewTeamUsecase(repo, userRepo, prRepo, nil, prUC, testLogger())
 
 	repo.On("GetTeam", mock.Anything, "unknown").Return(models.Team{}, models.ErrTeamNotFound)
 
@@ -176,7 +191,7 @@ func TestTeamUsecase_GetTeam_Success(t *testing.T) {
 	prRepo := new(mockPRRepository)
 	prUC := NewPRUsecase(prRepo, userRepo, repo, testLogger())
 
-	uc := NewTeamUsecase(repo, userRepo, prRepo, prUC, testLogger())
+	uc := NewTeamUsecase(repo, userRepo, prRepo, nil, prUC, testLogger())
 
 	expected := models.Team{
 		Name: "avito",
@@ -193,3 +208,197 @@ func TestTeamUsecase_GetTeam_Success(t *testing.T) {
 
 	repo.AssertExpectations(t)
 }
+
+func TestTeamUsecase_DeactivateTeam_Spans(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	prevProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { otel.SetTracerProvider(prevProvider) })
+
+	teamRepo := new(mockTeamRepository)
+	userRepo := new(mockUserRepository)
+	prRepo := new(mockPRRepository)
+
+	team := models.Team{
+		Name: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u2", IsActive: true},
+			{UserID: "u3", IsActive: true},
+		},
+	}
+	pr := models.PullRequest{ID: "pr-1", AuthorID: "u1", Status: models.StatusOpen, AssignedReviewers: []string{"u2"}}
+	reassigned := pr
+	reassigned.AssignedReviewers = []string{"u3"}
+
+	teamRepo.On("GetTeam", mock.Anything, "backend").Return(team, nil)
+	prRepo.On("GetOpenPRsWithTeamReviewers", mock.Anything, "backend").Return([]models.PullRequest{pr}, nil)
+	userRepo.On("GetUser", mock.Anything, "u2").Return(models.User{UserID: "u2", TeamName: "backend"}, nil)
+	prRepo.On("GetPR", mock.Anything, "pr-1").Return(pr, nil).Once()
+	prRepo.On("ReassignReviewer", mock.Anything, "pr-1", "u2", "u3").Return(nil)
+	prRepo.On("GetPR", mock.Anything, "pr-1").Return(reassigned, nil).Once()
+	userRepo.On("DeactivateTeam", mock.Anything, "backend", 1).Return(2, nil)
+	deactivations := new(mockDeactivationRepository)
+	deactivations.On("InProgress", mock.Anything, "backend").Return(false, nil)
+
+	before := testutil.ToFloat64(metrics.Reassignments.WithLabelValues(models.ReassignReasonTeamDeactivation))
+
+	prUC := NewPRUsecase(prRepo, userRepo, teamRepo, testLogger())
+	uc := NewTeamUsecase(teamRepo, userRepo, prRepo, deactivations, prUC, testLogger())
+	resp, err := uc.DeactivateTeam(context.Background(), models.DeactivateTeamRequest{TeamName: "backend"})
+	require.NoError(t, err)
+	require.Equal(t, 1, resp.ReassignedPRs)
+	require.Equal(t, before+1, testutil.ToFloat64(metrics.Reassignments.WithLabelValues(models.ReassignReasonTeamDeactivation)))
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "PRUsecase.ReassignReviewer", spans[0].Name)
+	require.Equal(t, "TeamUsecase.DeactivateTeam", spans[1].Name)
+	require.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	require.Equal(t, spans[1].SpanContext.TraceID(), spans[0].SpanContext.TraceID())
+}
+
+func TestTeamUsecase_DeactivateTeam_AsyncInProgress(t *testing.T) {
+	teamRepo := new(mockTeamRepository)
+	prRepo := new(mockPRRepository)
+	userRepo := new(mockUserRepository)
+	deactivations := new(mockDeactivationRepository)
+
+	teamRepo.On("GetTeam", mock.Anything, "backend").Return(models.Team{Name: "backend"}, nil)
+	deactivations.On("InProgress", mock.Anything, "backend").Return(true, nil)
+
+	uc := NewTeamUsecase(teamRepo, userRepo, prRepo, deactivations, nil, testLogger())
+	_, err := uc.DeactivateTeam(context.Background(), models.DeactivateTeamRequest{TeamName: "backend"})
+	require.ErrorIs(t, err, models.ErrTeamDeactivating)
+
+	prRepo.AssertNotCalled(t, "GetOpenPRsWithTeamReviewers", mock.Anything, mock.Anything)
+	userRepo.AssertNotCalled(t, "DeactivateTeam", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamUsecase_ActivateTeam_Rebalance(t *testing.T) {
+	teamRepo := new(mockTeamRepository)
+	userRepo := new(mockUserRepository)
+	prRepo := new(mockPRRepository)
+
+	team := models.Team{
+		Name: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", IsActive: true},
+			{UserID: "u2", IsActive: true},
+			{UserID: "u3", IsActive: true},
+		},
+	}
+	prs := []models.PullRequest{
+		{ID: "pr-1", AuthorID: "x", Status: models.StatusOpen, AssignedReviewers: []string{"u1", "u2"}},
+		{ID: "pr-2", AuthorID: "x", Status: models.StatusOpen, AssignedReviewers: []string{"u1", "u2"}},
+		{ID: "pr-3", AuthorID: "x", Status: models.StatusOpen, AssignedReviewers: []string{"u1", "u2"}},
+		{ID: "pr-4", AuthorID: "x", Status: models.StatusOpen, AssignedReviewers: []string{"u1"}},
+	}
+
+	teamRepo.On("GetTeam", mock.Anything, "backend").Return(team, nil)
+	userRepo.On("ActivateTeam", mock.Anything, "backend").Return([]string{"u3"}, nil)
+	prRepo.On("GetOpenPRsWithTeamReviewers", mock.Anything, "backend").Return(prs, nil)
+	prRepo.On("GetReviewedAssignments", mock.Anything, "backend").Return(map[string][]string{}, nil)
+	prRepo.On("MovePendingReviewer", mock.Anything, "pr-1", "u1", "u3").Return(nil)
+	prRepo.On("MovePendingReviewer", mock.Anything, "pr-2", "u1", "u3").Return(models.ErrPRMerged)
+
+	before := testutil.ToFloat64(metrics.Reassignments.WithLabelValues(models.ReassignReasonRebalance))
+
+	uc := NewTeamUsecase(teamRepo, userRepo, prRepo, nil, nil, testLogger())
+	resp, err := uc.ActivateTeam(context.Background(), models.ActivateTeamRequest{TeamName: "backend", Rebalance: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"u3"}, resp.ActivatedUsers)
+	// перенос pr-2 пропущен: PR смёржили между планом и выполнением
+	require.Equal(t, []models.ReviewerMove{{PRID: "pr-1", FromID: "u1", ToID: "u3"}}, resp.Moves)
+	require.Equal(t, before+1, testutil.ToFloat64(metrics.Reassignments.WithLabelValues(models.ReassignReasonRebalance)))
+
+	prRepo.AssertExpectations(t)
+}
+
+func TestTeamUsecase_ActivateTeam_WithoutRebalance(t *testing.T) {
+	teamRepo := new(mockTeamRepository)
+	userRepo := new(mockUserRepository)
+	prRepo := new(mockPRRepository)
+
+	teamRepo.On("GetTeam", mock.Anything, "backend").Return(models.Team{Name: "backend"}, nil)
+	userRepo.On("ActivateTeam", mock.Anything, "backend").Return([]string{"u1", "u2"}, nil)
+
+	uc := NewTeamUsecase(teamRepo, userRepo, prRepo, nil, nil, testLogger())
+	resp, err := uc.ActivateTeam(context.Background(), models.ActivateTeamRequest{TeamName: "backend"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"u1", "u2"}, resp.ActivatedUsers)
+	require.Empty(t, resp.Moves)
+
+	prRepo.AssertNotCalled(t, "GetOpenPRsWithTeamReviewers", mock.Anything, mock.Anything)
+}
+
+func TestTeamUsecase_ActivateTeam_NotDeactivated(t *testing.T) {
+	teamRepo := new(mockTeamRepository)
+	userRepo := new(mockUserRepository)
+
+	teamRepo.On("GetTeam", mock.Anything, "backend").Return(models.Team{Name: "backend"}, nil)
+	userRepo.On("ActivateTeam", mock.Anything, "backend").Return([]string(nil), models.ErrTeamNotDeactivated)
+
+	uc := NewTeamUsecase(teamRepo, userRepo, new(mockPRRepository), nil, nil, testLogger())
+	_, err := uc.ActivateTeam(context.Background(), models.ActivateTeamRequest{TeamName: "backend", Rebalance: true})
+	require.ErrorIs(t, err, models.ErrTeamNotDeactivated)
+}
+
+func TestTeamUsecase_ImportTeams_DryRun(t *testing.T) {
+	repo := new(mockTeamRepository)
+	uc := NewTeamUsecase(repo, new(mockUserRepository), new(mockPRRepository), nil, nil, testLogger())
+
+	diff := models.ImportDiff{TeamsCreated: []string{"backend"}}
+	repo.On("ImportTeams", mock.Anything, []models.ImportRow{
+		{Location: "line 2", TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true},
+		{Location: "line 3", TeamName: "backend", UserID: "u2", Username: "Bob", IsActive: false},
+	}, true).Return(diff, nil)
+
+	result, err := uc.ImportTeams(context.Background(), models.ImportRequest{
+		Format: models.ImportFormatCSV,
+		Data:   []byte("team_name,user_id,username,is_active\nbackend,u1,Alice,true\nbackend,u2,Bob,false\n"),
+		DryRun: true,
+	})
+	require.NoError(t, err)
+	require.True(t, result.DryRun)
+	require.Equal(t, diff, result.Diff)
+	repo.AssertExpectations(t)
+}
+
+func TestTeamUsecase_ImportTeams_ValidatesWholeFile(t *testing.T) {
+	repo := new(mockTeamRepository)
+	uc := NewTeamUsecase(repo, new(mockUserRepository), new(mockPRRepository), nil, nil, testLogger())
+
+	data := "team_name,user_id,username,is_active\n" +
+		"backend,u1,Alice,true\n" +
+		"backend,,Bob,true\n" +
+		"backend,u1,Alice,false\n" +
+		"frontend,u1,Alice,true\n" +
+		"frontend,u3,Carol,perhaps\n" +
+		",u4,,true\n"
+
+	_, err := uc.ImportTeams(context.Background(), models.ImportRequest{Format: models.ImportFormatCSV, Data: []byte(data)})
+
+	var verr *models.ImportValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, []models.ImportRowError{
+		{Location: "line 6", Field: "is_active", Message: `invalid boolean "perhaps"`},
+		{Location: "line 3", Field: "user_id", Message: "is required"},
+		{Location: "line 4", Field: "user_id", Message: `duplicate user_id "u1", first listed at line 2`},
+		{Location: "line 5", Field: "user_id", Message: `user "u1" is also listed in team "backend" at line 2`},
+		{Location: "line 7", Field: "team_name", Message: "is required"},
+		{Location: "line 7", Field: "username", Message: "is required"},
+	}, verr.Errors)
+	repo.AssertNotCalled(t, "ImportTeams", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamUsecase_ImportTeams_EmptyFile(t *testing.T) {
+	repo := new(mockTeamRepository)
+	uc := NewTeamUsecase(repo, new(mockUserRepository), new(mockPRRepository), nil, nil, testLogger())
+
+	_, err := uc.ImportTeams(context.Background(), models.ImportRequest{Format: models.ImportFormatYAML, Data: []byte("teams: []\n")})
+
+	var verr *models.ImportValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Errors, 1)
+	require.Equal(t, "file", verr.Errors[0].Location)
+}
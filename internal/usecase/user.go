@@ -3,6 +3,7 @@ package usecase
 import (
 	"avito-pr-service/internal/models"
 	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
 	"context"
 	"errors"
 	"log/slog"
@@ -26,21 +27,27 @@ func NewUserUsecase(repo repository.UserRepository, log *slog.Logger) UserUsecas
 }
 
 func (u *userUsecase) SetActive(ctx context.Context, req models.SetUserActiveRequest) error {
-	u.log.Info("setting user active", "user_id", req.UserID, "is_active", req.IsActive)
+	ctx, span := tracing.Start(ctx, "UserUsecase.SetActive")
+	defer span.End()
+
+	u.log.InfoContext(ctx, "setting user active", "user_id", req.UserID, "is_active", req.IsActive)
 
 	if err := u.repo.SetActive(ctx, req.UserID, req.IsActive); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
-			u.log.Warn("user not found", "user_id", req.UserID)
+			u.log.WarnContext(ctx, "user not found", "user_id", req.UserID)
 			return models.ErrUserNotFound
 		}
-		u.log.Error("failed to update user", "error", err)
+		u.log.ErrorContext(ctx, "failed to update user", "error", err)
 		return err
 	}
 
-	u.log.Info("user updated", "user_id", req.UserID, "is_active", req.IsActive)
+	u.log.InfoContext(ctx, "user updated", "user_id", req.UserID, "is_active", req.IsActive)
 	return nil
 }
 
 func (u *userUsecase) GetUser(ctx context.Context, userID string) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.GetUser")
+	defer span.End()
+
 	return u.repo.GetUser(ctx, userID)
 }
@@ -19,8 +19,13 @@ func (m *mockUserRepository) SetActive(ctx context.Context, userID string, isAct
 	return m.Called(ctx, userID, isActive).Error(0)
 }
 
-func (m *mockUserRepository) DeactivateTeam(ctx context.Context, teamName string) (int, error) {
+func (m *mockUserRepository) ActivateTeam(ctx context.Context, teamName string) ([]string, error) {
 	args := m.Called(ctx, teamName)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockUserRepository) DeactivateTeam(ctx context.Context, teamName string, reassignedPRs int) (int, error) {
+	args := m.Called(ctx, teamName, reassignedPRs)
 	return args.Int(0), args.Error(1)
 }
 
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"avito-pr-service/internal/tracing"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+)
+
+const defaultDeadLetterLimit = 100
+
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, req models.CreateWebhookRequest) (models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	ListDeadLetters(ctx context.Context, limit int) ([]models.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, deliveryID int64) error
+}
+
+type webhookUsecase struct {
+	repo repository.WebhookRepository
+	log  *slog.Logger
+}
+
+func NewWebhookUsecase(repo repository.WebhookRepository, log *slog.Logger) WebhookUsecase {
+	return &webhookUsecase{
+		repo: repo,
+		log:  log.With("layer", "usecase", "entity", "webhook"),
+	}
+}
+
+func (u *webhookUsecase) CreateSubscription(ctx context.Context, req models.CreateWebhookRequest) (models.WebhookSubscription, error) {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.CreateSubscription")
+	defer span.End()
+
+	eventTypes := slices.Clone(req.EventTypes)
+	slices.Sort(eventTypes)
+
+	sub, err := u.repo.CreateSubscription(ctx, models.WebhookSubscription{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: slices.Compact(eventTypes),
+	})
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to create webhook subscription", "url", req.URL, "error", err)
+		return models.WebhookSubscription{}, err
+	}
+
+	u.log.InfoContext(ctx, "webhook subscription created", "id", sub.ID, "url", sub.URL, "events", sub.EventTypes)
+	return sub, nil
+}
+
+func (u *webhookUsecase) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.ListSubscriptions")
+	defer span.End()
+
+	return u.repo.ListSubscriptions(ctx)
+}
+
+func (u *webhookUsecase) DeleteSubscription(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.DeleteSubscription")
+	defer span.End()
+
+	if err := u.repo.DeleteSubscription(ctx, id); err != nil {
+		if !errors.Is(err, models.ErrWebhookNotFound) {
+			u.log.ErrorContext(ctx, "failed to delete webhook subscription", "id", id, "error", err)
+		}
+		return err
+	}
+	u.log.InfoContext(ctx, "webhook subscription deleted", "id", id)
+	return nil
+}
+
+func (u *webhookUsecase) ListDeadLetters(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.ListDeadLetters")
+	defer span.End()
+
+	if limit <= 0 || limit > defaultDeadLetterLimit {
+		limit = defaultDeadLetterLimit
+	}
+	return u.repo.ListDeadLetters(ctx, limit)
+}
+
+func (u *webhookUsecase) RetryDelivery(ctx context.Context, deliveryID int64) error {
+	ctx, span := tracing.Start(ctx, "WebhookUsecase.RetryDelivery")
+	defer span.End()
+
+	if err := u.repo.RetryDelivery(ctx, deliveryID); err != nil {
+		return err
+	}
+	u.log.InfoContext(ctx, "webhook delivery requeued", "id", deliveryID)
+	return nil
+}
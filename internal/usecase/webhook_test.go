@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type mockWebhookRepository struct{ mock.Mock }
+
+func (m *mockWebhookRepository) CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	return args.Get(0).(models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockWebhookRepository) EnqueueDeliveries(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error) {
+	args := m.Called(ctx, eventID, eventType, payload)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepository) MarkDelivered(ctx context.Context, delivery models.WebhookDelivery) error {
+	return m.Called(ctx, delivery.ID).Error(0)
+}
+
+func (m *mockWebhookRepository) MarkFailed(ctx context.Context, delivery models.WebhookDelivery, attempts int, nextAttemptAt time.Time, lastErr string, dead bool) error {
+	return m.Called(ctx, delivery.ID, attempts, nextAttemptAt, lastErr, dead).Error(0)
+}
+
+func (m *mockWebhookRepository) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListDeadLetters(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepository) RetryDelivery(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func TestWebhookUsecase_CreateSubscription_DeduplicatesEventTypes(t *testing.T) {
+	repo := new(mockWebhookRepository)
+	uc := NewWebhookUsecase(repo, testLogger())
+
+	repo.On("CreateSubscription", mock.Anything, models.WebhookSubscription{
+		URL:        "https://hooks.example.com/pr",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{models.EventPRMerged, models.EventReviewerAssigned},
+	}).Return(models.WebhookSubscription{ID: 1, URL: "https://hooks.example.com/pr"}, nil)
+
+	sub, err := uc.CreateSubscription(context.Background(), models.CreateWebhookRequest{
+		URL:        "https://hooks.example.com/pr",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{models.EventReviewerAssigned, models.EventPRMerged, models.EventReviewerAssigned},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), sub.ID)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhookUsecase_DeleteSubscription_NotFound(t *testing.T) {
+	repo := new(mockWebhookRepository)
+	uc := NewWebhookUsecase(repo, testLogger())
+
+	repo.On("DeleteSubscription", mock.Anything, int64(42)).Return(models.ErrWebhookNotFound)
+
+	err := uc.DeleteSubscription(context.Background(), 42)
+	require.ErrorIs(t, err, models.ErrWebhookNotFound)
+}
+
+func TestWebhookUsecase_ListDeadLetters_ClampsLimit(t *testing.T) {
+	repo := new(mockWebhookRepository)
+	uc := NewWebhookUsecase(repo, testLogger())
+
+	repo.On("ListDeadLetters", mock.Anything, defaultDeadLetterLimit).Return([]models.WebhookDelivery{}, nil).Twice()
+
+	_, err := uc.ListDeadLetters(context.Background(), 0)
+	require.NoError(t, err)
+	_, err = uc.ListDeadLetters(context.Background(), 100000)
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"avito-pr-service/internal/models"
+	"avito-pr-service/internal/repository"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KindCleanup — задача очереди jobs, удаляющая доставки старше Retention
+const KindCleanup = "webhook.cleanup"
+
+const (
+	SignatureHeader = "X-Signature-256"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	log    *slog.Logger
+
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Retention    time.Duration
+}
+
+func NewDispatcher(repo repository.WebhookRepository, log *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:         repo,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		log:          log.With("component", "webhook"),
+		PollInterval: time.Second,
+		BatchSize:    50,
+		MaxAttempts:  8,
+		BaseBackoff:  5 * time.Second,
+		MaxBackoff:   time.Hour,
+		Retention:    7 * 24 * time.Hour,
+	}
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhook"
+}
+
+// Deliver вызывается диспетчером outbox: событие раскладывается по доставкам
+// подписчиков, а сама отправка идёт в Run
+func (d *Dispatcher) Deliver(ctx context.Context, event models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	if _, err := d.repo.EnqueueDeliveries(ctx, event.ID, event.Type, payload); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.DeliverDue(ctx)
+		}
+	}
+}
+
+func (d *Dispatcher) DeliverDue(ctx context.Context) {
+	// доставки пачки отправляются по очереди, поэтому аренда должна пережить
+	// отправку всей пачки, а не одной доставки
+	lease := time.Duration(d.BatchSize) * d.client.Timeout
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, d.BatchSize, lease)
+	if err != nil {
+		d.log.ErrorContext(ctx, "failed to claim webhook deliveries", "error", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		d.deliver(ctx, delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		if err := d.repo.MarkDelivered(ctx, delivery); err != nil {
+			d.markError(ctx, delivery, err)
+		}
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	dead := attempts >= d.MaxAttempts
+	next := time.Now().Add(d.backoff(attempts))
+	if dead {
+		d.log.WarnContext(ctx, "webhook delivery moved to dead letters", "id", delivery.ID, "url", delivery.URL, "error", sendErr)
+	} else {
+		d.log.InfoContext(ctx, "webhook delivery failed, will retry", "id", delivery.ID, "attempt", attempts, "next", next, "error", sendErr)
+	}
+
+	if err := d.repo.MarkFailed(ctx, delivery, attempts, next, sendErr.Error(), dead); err != nil {
+		d.markError(ctx, delivery, err)
+	}
+}
+
+func (d *Dispatcher) markError(ctx context.Context, delivery models.WebhookDelivery, err error) {
+	if errors.Is(err, repository.ErrLeaseLost) {
+		d.log.WarnContext(ctx, "webhook delivery outlived its lease, result dropped", "id", delivery.ID, "attempt", delivery.Attempts+1)
+		return
+	}
+	d.log.ErrorContext(ctx, "failed to mark delivery", "id", delivery.ID, "error", err)
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Cleanup — обработчик задачи KindCleanup. Удаляются только доставленные:
+// dead letters остаются, пока их не перезапустят
+func (d *Dispatcher) Cleanup(ctx context.Context, _ models.Job) error {
+	n, err := d.repo.DeleteDelivered(ctx, time.Now().Add(-d.Retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		d.log.InfoContext(ctx, "delivered webhook deliveries deleted", "count", n)
+	}
+	return nil
+}
+
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return delay
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
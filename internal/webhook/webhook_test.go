@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"avito-pr-service/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockWebhookRepository struct{ mock.Mock }
+
+func (m *mockWebhookRepository) CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	return args.Get(0).(models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *mockWebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockWebhookRepository) EnqueueDeliveries(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error) {
+	args := m.Called(ctx, eventID, eventType, payload)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepository) MarkDelivered(ctx context.Context, delivery models.WebhookDelivery) error {
+	return m.Called(ctx, delivery.ID).Error(0)
+}
+
+func (m *mockWebhookRepository) MarkFailed(ctx context.Context, delivery models.WebhookDelivery, attempts int, nextAttemptAt time.Time, lastErr string, dead bool) error {
+	return m.Called(ctx, delivery.ID, attempts, nextAttemptAt, lastErr, dead).Error(0)
+}
+
+func (m *mockWebhookRepository) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListDeadLetters(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepository) RetryDelivery(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestSign(t *testing.T) {
+	require.Equal(t,
+		"sha256=b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad",
+		Sign("", []byte("")),
+	)
+	require.NotEqual(t, Sign("secret-a", []byte(`{}`)), Sign("secret-b", []byte(`{}`)))
+}
+
+func TestDispatcher_DeliverDue_Success(t *testing.T) {
+	payload := []byte(`{"type":"pr.merged","pull_request_id":"pr-1"}`)
+
+	var gotHeaders http.Header
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	repo := new(mockWebhookRepository)
+	// аренда покрывает отправку всей пачки: 50 доставок по 10s
+	repo.On("ClaimDueDeliveries", mock.Anything, 50, 500*time.Second).Return([]models.WebhookDelivery{{
+		ID: 7, EventType: models.EventPRMerged, Payload: payload, URL: srv.URL, Secret: "topsecret-topsecret",
+	}}, nil)
+	repo.On("MarkDelivered", mock.Anything, int64(7)).Return(nil)
+
+	d := NewDispatcher(repo, testLogger())
+	d.DeliverDue(context.Background())
+
+	require.Equal(t, payload, gotBody)
+	require.Equal(t, models.EventPRMerged, gotHeaders.Get(EventHeader))
+	require.Equal(t, "7", gotHeaders.Get(DeliveryHeader))
+	require.Equal(t, Sign("topsecret-topsecret", payload), gotHeaders.Get(SignatureHeader))
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_DeliverDue_RetryWithBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	repo := new(mockWebhookRepository)
+	repo.On("ClaimDueDeliveries", mock.Anything, 50, mock.Anything).Return([]models.WebhookDelivery{{
+		ID: 1, Attempts: 2, Payload: []byte(`{}`), URL: srv.URL,
+	}}, nil)
+	before := time.Now()
+	repo.On("MarkFailed", mock.Anything, int64(1), 3, mock.MatchedBy(func(next time.Time) bool {
+		// третья попытка: 5s * 2^2
+		return next.Sub(before) >= 20*time.Second && next.Sub(before) < 21*time.Second
+	}), "unexpected status 502", false).Return(nil)
+
+	d := NewDispatcher(repo, testLogger())
+	d.DeliverDue(context.Background())
+
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_DeliverDue_DeadLetter(t *testing.T) {
+	repo := new(mockWebhookRepository)
+	repo.On("ClaimDueDeliveries", mock.Anything, 50, mock.Anything).Return([]models.WebhookDelivery{{
+		ID: 1, Attempts: 7, Payload: []byte(`{}`), URL: "http://127.0.0.1:1",
+	}}, nil)
+	repo.On("MarkFailed", mock.Anything, int64(1), 8, mock.Anything, mock.Anything, true).Return(nil)
+
+	d := NewDispatcher(repo, testLogger())
+	d.DeliverDue(context.Background())
+
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := NewDispatcher(new(mockWebhookRepository), testLogger())
+	require.Equal(t, 5*time.Second, d.backoff(1))
+	require.Equal(t, 10*time.Second, d.backoff(2))
+	require.Equal(t, 40*time.Second, d.backoff(4))
+	require.Equal(t, time.Hour, d.backoff(20))
+}
+
+func TestDispatcher_CleanupUsesRetention(t *testing.T) {
+	repo := new(mockWebhookRepository)
+	repo.On("DeleteDelivered", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return before.Sub(time.Now().Add(-time.Hour)).Abs() < time.Minute
+	})).Return(3, nil)
+
+	d := NewDispatcher(repo, testLogger())
+	d.Retention = time.Hour
+
+	require.NoError(t, d.Cleanup(context.Background(), models.Job{Kind: KindCleanup}))
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_Deliver_EnqueuesEvent(t *testing.T) {
+	repo := new(mockWebhookRepository)
+	repo.On("EnqueueDeliveries", mock.Anything, int64(12), models.EventPRCreated, mock.MatchedBy(func(payload []byte) bool {
+		var ev map[string]any
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			return false
+		}
+		return ev["id"] == float64(12) && ev["pull_request_id"] == "pr-1"
+	})).Return(2, nil)
+
+	d := NewDispatcher(repo, testLogger())
+	err := d.Deliver(context.Background(), models.Event{ID: 12, Type: models.EventPRCreated, PRID: "pr-1"})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_Deliver_PropagatesError(t *testing.T) {
+	repo := new(mockWebhookRepository)
+	repo.On("EnqueueDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, errors.New("db is down"))
+
+	d := NewDispatcher(repo, testLogger())
+	err := d.Deliver(context.Background(), models.Event{ID: 1, Type: models.EventPRMerged})
+
+	require.Error(t, err)
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS vcs_identities (
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (provider, login)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vcs_identities_user ON vcs_identities(user_id);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS reviewer_reassignments (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    old_reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    new_reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reassigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviewer_reassignments_at ON reviewer_reassignments(reassigned_at);
+
+-- история до появления таблицы есть только в outbox
+INSERT INTO reviewer_reassignments (pr_id, old_reviewer_id, new_reviewer_id, reassigned_at)
+SELECT e.payload->'data'->>'pull_request_id',
+       e.payload->'data'->>'old_reviewer_id',
+       e.payload->'data'->>'new_reviewer_id',
+       e.created_at
+FROM outbox_events e
+WHERE e.event_type = 'reviewer.reassigned'
+  AND EXISTS (SELECT 1 FROM pull_requests p WHERE p.id = e.payload->'data'->>'pull_request_id')
+  AND EXISTS (SELECT 1 FROM users u WHERE u.user_id = e.payload->'data'->>'old_reviewer_id')
+  AND EXISTS (SELECT 1 FROM users u WHERE u.user_id = e.payload->'data'->>'new_reviewer_id')
+ORDER BY e.id;
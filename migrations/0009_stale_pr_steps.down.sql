@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_reviewer_reassignments_pr;
+DROP TABLE IF EXISTS stale_pr_steps;
@@ -0,0 +1,15 @@
+-- шаги обработки зависших PR; reviewer_id и lead_id — журнал, без внешних
+-- ключей, чтобы запись пережила удаление пользователя
+CREATE TABLE IF NOT EXISTS stale_pr_steps (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    step TEXT NOT NULL CHECK (step IN ('remind', 'reassign', 'escalate')),
+    reviewer_id TEXT,
+    new_reviewer_id TEXT,
+    lead_id TEXT,
+    inactive_since TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stale_pr_steps_pr ON stale_pr_steps(pr_id, id);
+CREATE INDEX IF NOT EXISTS idx_reviewer_reassignments_pr ON reviewer_reassignments(pr_id, reassigned_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS team_deactivation_prs;
+DROP TABLE IF EXISTS team_deactivations;
@@ -0,0 +1,29 @@
+-- асинхронная деактивация команды; прогресс хранится по PR, поэтому после
+-- падения задача продолжает с необработанных
+CREATE TABLE IF NOT EXISTS team_deactivations (
+    id BIGSERIAL PRIMARY KEY,
+    team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('PENDING', 'RUNNING', 'DONE', 'FAILED')) DEFAULT 'PENDING',
+    total_prs INT NOT NULL DEFAULT 0,
+    processed_prs INT NOT NULL DEFAULT 0,
+    reassigned INT NOT NULL DEFAULT 0,
+    stuck INT NOT NULL DEFAULT 0,
+    deactivated_users INT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+-- одновременно у команды может идти только одна деактивация
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_deactivations_active
+    ON team_deactivations(team_name) WHERE status IN ('PENDING', 'RUNNING');
+
+-- снимок открытых PR на момент старта; pr_id без внешнего ключа: если PR
+-- удалят до того, как задача до него дойдёт, строка не исчезнет каскадом,
+-- а будет отмечена обработанной, и processed_prs всё равно дойдёт до total_prs
+CREATE TABLE IF NOT EXISTS team_deactivation_prs (
+    deactivation_id BIGINT NOT NULL REFERENCES team_deactivations(id) ON DELETE CASCADE,
+    pr_id TEXT NOT NULL,
+    processed BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (deactivation_id, pr_id)
+);
@@ -0,0 +1,9 @@
+-- состояние участников перед последней деактивацией команды; /team/activate
+-- включает тех, кто был активен, и удаляет снимок
+CREATE TABLE IF NOT EXISTS team_member_snapshots (
+    team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    was_active BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_name, user_id)
+);
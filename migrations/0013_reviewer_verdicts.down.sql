@@ -0,0 +1,3 @@
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS verdict_at,
+    DROP COLUMN IF EXISTS verdict;
@@ -0,0 +1,5 @@
+-- вердикт ревьювера из VCS (approve / request changes); таких ревьюверов
+-- выравнивание нагрузки не переносит
+ALTER TABLE pr_reviewers
+    ADD COLUMN IF NOT EXISTS verdict TEXT CHECK (verdict IN ('APPROVED', 'CHANGES_REQUESTED')),
+    ADD COLUMN IF NOT EXISTS verdict_at TIMESTAMPTZ;
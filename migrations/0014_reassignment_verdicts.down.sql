@@ -0,0 +1,3 @@
+ALTER TABLE reviewer_reassignments
+    DROP COLUMN IF EXISTS verdict_at,
+    DROP COLUMN IF EXISTS verdict;
@@ -0,0 +1,5 @@
+-- вердикт снятого ревьювера переезжает в историю: ручное переназначение
+-- и деактивация команды снимают и тех, кто уже вынес вердикт
+ALTER TABLE reviewer_reassignments
+    ADD COLUMN IF NOT EXISTS verdict TEXT CHECK (verdict IN ('APPROVED', 'CHANGES_REQUESTED')),
+    ADD COLUMN IF NOT EXISTS verdict_at TIMESTAMPTZ;
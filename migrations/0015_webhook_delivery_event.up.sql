@@ -0,0 +1,7 @@
+-- доставка привязана к событию outbox: повторная передача того же события
+-- в sink вебхуков не создаёт вторую доставку подписчику. У доставок,
+-- созданных до миграции, event_id пустой
+ALTER TABLE webhook_deliveries
+    ADD COLUMN IF NOT EXISTS event_id BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event ON webhook_deliveries(subscription_id, event_id);
@@ -0,0 +1,11 @@
+-- граница очистки журнала событий: наибольший id, удалённый по outbox.retention.
+-- По ней поток событий отказывает в продолжении с более старого Last-Event-ID,
+-- вместо того чтобы молча пропустить удалённые события
+CREATE TABLE IF NOT EXISTS outbox_retention (
+    id            BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    deleted_up_to BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO outbox_retention (deleted_up_to)
+SELECT COALESCE(MIN(id) - 1, 0) FROM outbox_events
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+var upFile = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
+// LatestVersion возвращает номер последней встроенной миграции
+func LatestVersion() (int64, error) {
+	return latestVersion(FS)
+}
+
+func latestVersion(fsys fs.FS) (int64, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return 0, fmt.Errorf("read migrations: %w", err)
+	}
+
+	var latest int64
+	for _, e := range entries {
+		m := upFile.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		v, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse migration version %q: %w", e.Name(), err)
+		}
+		latest = max(latest, v)
+	}
+
+	if latest == 0 {
+		return 0, errors.New("no migrations found")
+	}
+	return latest, nil
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLatestVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_init.up.sql":        {},
+		"0001_init.down.sql":      {},
+		"0012_outbox.up.sql":      {},
+		"0012_outbox.down.sql":    {},
+		"0003_pr.up.sql":          {},
+		"0013_wip.down.sql":       {},
+		"embed.go":                {},
+		"0002_add_indexes.up.sql": {},
+	}
+
+	v, err := latestVersion(fsys)
+	require.NoError(t, err)
+	require.Equal(t, int64(12), v)
+
+	_, err = latestVersion(fstest.MapFS{})
+	require.Error(t, err)
+}
+
+func TestLatestVersion_Embedded(t *testing.T) {
+	v, err := LatestVersion()
+	require.NoError(t, err)
+	require.Positive(t, v)
+}